@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"docker-gui-backend/internal/database"
+)
+
+func loginFrom(t *testing.T, srv *testServer, forwardedFor string) {
+	t.Helper()
+
+	body := `{"username":"` + adminUser + `","password":"` + adminPassword + `"}`
+	req, _ := http.NewRequest("POST", srv.URL+"/api/v1/auth/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login: status %d", resp.StatusCode)
+	}
+}
+
+func lastActivity(t *testing.T, client *http.Client, srv *testServer, action string) database.ContainerLog {
+	t.Helper()
+
+	resp, err := client.Get(srv.URL + "/api/v1/logs?limit=1&action=" + action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var page struct {
+		Logs []database.ContainerLog `json:"logs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Logs) == 0 {
+		t.Fatalf("no %s entry in the activity log", action)
+	}
+	return page.Logs[0]
+}
+
+func TestAuditClientIP(t *testing.T) {
+	cases := []struct {
+		trusted  string
+		clientIP string
+	}{
+		{"", "127.0.0.1"},
+		{"127.0.0.1", "203.0.113.9"},
+	}
+
+	for _, tc := range cases {
+		t.Setenv("TRUSTED_PROXIES", tc.trusted)
+		srv := newTestServer(t)
+		client := srv.login(t)
+
+		loginFrom(t, srv, "203.0.113.9")
+		entry := lastActivity(t, client, srv, "login")
+		if entry.ClientIP != tc.clientIP || entry.RemoteAddr != "127.0.0.1" {
+			t.Errorf("TRUSTED_PROXIES=%q: client_ip %q remote_addr %q, want %q and 127.0.0.1", tc.trusted, entry.ClientIP, entry.RemoteAddr, tc.clientIP)
+		}
+	}
+}
+
+func TestBackupDownloadAudited(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+
+	srv.run(t, client, []routeCase{
+		{method: "POST", path: "/api/v1/volumes/data/backups", want: 201, save: "backup"},
+		{method: "GET", path: "/api/v1/backups/{backup}/download", want: 200},
+	}, map[string]bool{})
+
+	entry := lastActivity(t, client, srv, "download_backup")
+	if entry.ContainerName != "data" || !strings.Contains(entry.Details, "downloaded") {
+		t.Errorf("download entry %+v", entry)
+	}
+}
+
+func TestContainerActionAuditName(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+	worker, _ := srv.fake.Container("worker")
+
+	for _, id := range []string{"worker", worker.ID[:8], worker.ID} {
+		srv.run(t, client, []routeCase{
+			{method: "POST", path: "/api/v1/containers/" + id + "/start", want: 200},
+			{method: "POST", path: "/api/v1/containers/" + id + "/stop", want: 200},
+		}, map[string]bool{})
+
+		for _, action := range []string{"start", "stop"} {
+			if entry := lastActivity(t, client, srv, action); entry.ContainerName != "/worker" {
+				t.Errorf("%s %s: container name %q, want /worker", action, id, entry.ContainerName)
+			}
+		}
+	}
+}
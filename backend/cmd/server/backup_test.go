@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker/dockertest"
+)
+
+func TestBackupsScopedToHost(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+
+	srv.run(t, client, []routeCase{
+		{method: "POST", path: "/api/v1/volumes/data/backups", want: 201, save: "backup"},
+		{method: "POST", path: "/api/v1/hosts", body: `{"name":"edge","endpoint":"tcp://127.0.0.1:1"}`, want: 201},
+		{method: "GET", path: "/api/v1/hosts/edge/backups/{backup}", want: 404},
+		{method: "GET", path: "/api/v1/hosts/edge/backups/{backup}/download", want: 404},
+		{method: "DELETE", path: "/api/v1/hosts/edge/backups/{backup}", want: 404},
+		{method: "GET", path: "/api/v1/hosts/local/backups/{backup}", want: 200},
+	}, map[string]bool{})
+
+	files, err := filepath.Glob(filepath.Join(os.Getenv("BACKUP_DIR"), "*"))
+	if err != nil || len(files) != 1 {
+		t.Errorf("backup files after cross-host delete: %v (%v)", files, err)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/backups":                  1,
+		"/api/v1/backups?host=edge":        1,
+		"/api/v1/hosts/local/backups":      1,
+		"/api/v1/hosts/edge/backups":       0,
+		"/api/v1/hosts/edge/backups?host=": 0,
+	} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var backups []database.VolumeBackup
+		json.NewDecoder(resp.Body).Decode(&backups)
+		resp.Body.Close()
+		if len(backups) != want {
+			t.Errorf("GET %s listed %d backups, want %d", path, len(backups), want)
+		}
+	}
+}
+
+func TestRestoreCorruptBackup(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+
+	srv.run(t, client, []routeCase{
+		{method: "POST", path: "/api/v1/volumes/data/backups", want: 201, save: "backup"},
+	}, map[string]bool{})
+
+	files, err := filepath.Glob(filepath.Join(os.Getenv("BACKUP_DIR"), "*"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("backup files: %v (%v)", files, err)
+	}
+	if err := os.WriteFile(files[0], []byte("tampered"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.run(t, client, []routeCase{
+		{method: "POST", path: "/api/v1/backups/{backup}/restore", body: `{"targetVolume":"restored"}`, want: http.StatusConflict, check: func(t *testing.T, fake *dockertest.Fake) {
+			if _, ok := fake.VolumeData("restored"); ok {
+				t.Error("a corrupt backup was restored")
+			}
+		}},
+	}, map[string]bool{})
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"docker-gui-backend/internal/docker/dockertest"
+	"docker-gui-backend/pkg/models"
+)
+
+func TestDownloadFollowsRelativeSymlink(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+
+	resp, err := client.Get(srv.URL + "/api/v1/containers/web/files/download?path=/etc/nginx/conf.d/default.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || string(body) != "worker_processes 1;\n" {
+		t.Errorf("status %d, body %q, want the content of /etc/nginx/nginx.conf", resp.StatusCode, body)
+	}
+}
+
+func TestListDirectoryFollowsRelativeSymlink(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+	srv.fake.AddContainer(dockertest.Container{
+		Name:  "site",
+		Image: "nginx:1.27",
+		Files: map[string]string{"/srv/releases/42/index.html": "<h1>42</h1>\n"},
+		Links: map[string]string{"/srv/current": "releases/42"},
+	})
+
+	resp, err := client.Get(srv.URL + "/api/v1/containers/site/files?path=/srv/current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var listing struct {
+		Entries []models.FileEntry `json:"entries"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(listing.Entries) != 1 || listing.Entries[0].Path != "/srv/releases/42/index.html" {
+		t.Errorf("status %d, entries %+v, want the content of /srv/releases/42", resp.StatusCode, listing.Entries)
+	}
+}
+
+func TestUploadSizeLimit(t *testing.T) {
+	t.Setenv("MAX_UPLOAD_SIZE", "1024")
+	srv := newTestServer(t)
+	client := srv.login(t)
+
+	body, contentType := multipartUpload(t, "/tmp", map[string]string{"big.bin": strings.Repeat("x", 4096)})
+	resp, err := client.Post(srv.URL+"/api/v1/containers/web/files/upload", contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want 413", resp.StatusCode)
+	}
+	if _, ok := srv.fake.File("web", "/tmp/big.bin"); ok {
+		t.Error("oversized upload reached the container")
+	}
+}
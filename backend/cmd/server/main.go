@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"docker-gui-backend/internal/database"
 	"docker-gui-backend/internal/docker"
-	"docker-gui-backend/internal/handlers"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
@@ -18,7 +21,7 @@ func main() {
 		log.Println("Warning: .env file not found, using environment variables")
 	}
 
-	db, err := database.NewDatabase()
+	db, err := database.Open()
 	if err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
@@ -30,65 +33,59 @@ func main() {
 	}
 	defer dockerClient.Close()
 
-	r := gin.Default()
-
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	r.Use(cors.New(config))
-
-	containerHandler := handlers.NewContainerHandler(dockerClient, db)
-	metricsHandler := handlers.NewMetricsHandler(dockerClient)
-	imageHandler := handlers.NewImageHandler(dockerClient, db)
-
-	api := r.Group("/api/v1")
-	{
-		containers := api.Group("/containers")
-		{
-			containers.GET("", containerHandler.ListContainers)
-			containers.POST("/:id/start", containerHandler.StartContainer)
-			containers.POST("/:id/stop", containerHandler.StopContainer)
-			containers.POST("/:id/restart", containerHandler.RestartContainer)
-			containers.DELETE("/:id", containerHandler.RemoveContainer)
-			containers.GET("/:id/logs", containerHandler.GetContainerLogs)
-			containers.GET("/:id/stats", containerHandler.GetContainerStats)
-			containers.POST("/:id/action", containerHandler.PerformAction)
-		}
-		
-		images := api.Group("/images")
-		{
-			images.GET("", imageHandler.ListImages)
-			images.POST("/pull", imageHandler.PullImage)
-			images.DELETE("/:id", imageHandler.RemoveImage)
-			images.POST("/prune", imageHandler.PruneImages)
-		}
-		
-		logs := api.Group("/logs")
-		{
-			logs.GET("", containerHandler.GetActivityLogs)
-			logs.GET("/:id", containerHandler.GetContainerActivityLogs)
-		}
-		
-		metrics := api.Group("/metrics")
-		{
-			metrics.GET("", metricsHandler.GetOverallMetrics)
-			metrics.GET("/:id", metricsHandler.GetContainerMetrics)
-			metrics.GET("/historical", metricsHandler.GetHistoricalMetrics)
-		}
+	svc, err := newServices(db, dockerClient)
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer svc.pool.Close()
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
+	if svc.auditForwarder != nil {
+		svc.auditForwarder.Start()
+	}
+	svc.auditChain.Start()
+
+	db.OnReconnect(func() {
+		if err := svc.auth.Bootstrap(); err != nil {
+			log.Println("Failed to set up initial user:", err)
+		}
+		if err := svc.authorizer.Bootstrap(); err != nil {
+			log.Println("Failed to set up roles:", err)
+		}
 	})
 
+	r := newRouter(svc)
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	log.Printf("Server starting on :%s", port)
-	if err := r.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	srv := &http.Server{Addr: ":" + port, Handler: r}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("Server starting on :%s", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Failed to start server:", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown:", err)
+	}
+	if err := svc.auditWriter.Close(shutdownCtx); err != nil {
+		log.Println("Audit writer shutdown:", err)
+	}
+	if svc.auditForwarder != nil {
+		svc.auditForwarder.Close()
 	}
+	svc.auditChain.Close()
 }
\ No newline at end of file
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/auth"
+	"docker-gui-backend/internal/backup"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/handlers"
+	"docker-gui-backend/internal/openapi"
+	"docker-gui-backend/pkg/models"
+)
+
+const openAPIPath = "/api/v1/openapi.json"
+
+var messageResponse = openapi.Object{"message": ""}
+
+func apiSpec() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "Docker GUI API",
+		Version:     "1.0.0",
+		Description: "Manage containers, images, volumes, networks, stacks and Docker hosts.",
+	})
+	doc.Components.SecuritySchemes["session"] = openapi.SecurityScheme{
+		Type: "apiKey",
+		In:   "cookie",
+		Name: auth.SessionCookie,
+	}
+	doc.Components.SecuritySchemes["token"] = openapi.SecurityScheme{
+		Type:   "http",
+		Scheme: "bearer",
+	}
+	doc.Security = []openapi.SecurityRequirement{{"session": {}}, {"token": {}}}
+	doc.SetDefaultResponse("Error", models.ErrorResponse{})
+	doc.Schema(models.AccessDenied{})
+
+	for _, route := range dockerRoutes() {
+		doc.Add(route)
+
+		route.Path = "/api/v1/hosts/:host" + strings.TrimPrefix(route.Path, "/api/v1")
+		route.OperationID += "OnHost"
+		doc.Add(route)
+	}
+	for _, route := range apiRoutes() {
+		doc.Add(route)
+	}
+
+	return doc
+}
+
+func dockerRoutes() []openapi.Route {
+	force := openapi.Query("force", "Force the operation", false)
+	all := openapi.Query("all", "Include stopped containers (default true)", false)
+	filePath := openapi.Query("path", "Absolute path inside the container", "")
+	removeOrphans := openapi.Query("removeOrphans", "Remove containers of services no longer in the compose file", false)
+	compose := &openapi.Body{Content: map[string]any{
+		"application/json":   models.DeployStackRequest{},
+		"application/x-yaml": openapi.Text(),
+	}}
+	stackResult := openapi.Object{"message": "", "results": []models.StackActionResult{}}
+	stackAction := func(method, path, id, summary string, params ...openapi.Parameter) openapi.Route {
+		return openapi.Route{
+			Method: method, Path: path, OperationID: id, Summary: summary, Tag: "stacks", Params: params,
+			Responses: []openapi.Reply{
+				openapi.OK(stackResult),
+				{Status: http.StatusMultiStatus, Description: "Some containers failed", ContentType: "application/json", Value: stackResult},
+			},
+		}
+	}
+
+	return []openapi.Route{
+		{Method: "GET", Path: "/api/v1/containers", OperationID: "listContainers", Summary: "List containers", Tag: "containers",
+			Params: []openapi.Parameter{all}, Responses: []openapi.Reply{openapi.OK([]models.Container{})}},
+		{Method: "POST", Path: "/api/v1/containers/:id/start", OperationID: "startContainer", Summary: "Start a container", Tag: "containers",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "POST", Path: "/api/v1/containers/:id/stop", OperationID: "stopContainer", Summary: "Stop a container", Tag: "containers",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "POST", Path: "/api/v1/containers/:id/restart", OperationID: "restartContainer", Summary: "Restart a container", Tag: "containers",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "DELETE", Path: "/api/v1/containers/:id", OperationID: "removeContainer", Summary: "Remove a container", Tag: "containers",
+			Params: []openapi.Parameter{force}, Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/containers/:id/logs", OperationID: "getContainerLogs", Summary: "Container logs", Tag: "containers",
+			Params: []openapi.Parameter{
+				openapi.Query("lines", "Number of lines to read from the end of the log (default 100, at most 10000)", 0),
+				openapi.Query("format", "text returns formatted lines, structured returns entries with stream and level (default text)", openapi.Enum("text", "structured")),
+				openapi.Query("since", "Only entries at or after this RFC 3339 timestamp, Unix time or duration ago such as 15m", ""),
+				openapi.Query("until", "Only entries at or before this RFC 3339 timestamp, Unix time or duration ago", ""),
+				openapi.Query("stream", "Only entries from this stream", openapi.Enum(docker.StreamStdout, docker.StreamStderr)),
+				openapi.Query("q", "Only entries containing this text, ignoring case", ""),
+				openapi.Query("regex", "Only entries matching this RE2 regular expression", ""),
+				openapi.Query("level", "Comma-separated detected levels to keep: trace, debug, info, warn, error or fatal", ""),
+				openapi.Query("query", "Field query over parsed JSON and logfmt fields, such as level=error AND service=payments", ""),
+			},
+			Responses: []openapi.Reply{
+				openapi.OK(openapi.Object{"logs": []string{}, "timestamp": int64(0)}),
+				openapi.OK(models.ContainerLogs{}),
+			}},
+		{Method: "GET", Path: "/api/v1/containers/:id/stats", OperationID: "getContainerStats", Summary: "Container statistics", Tag: "containers",
+			Responses: []openapi.Reply{openapi.OK(models.ContainerStats{})}},
+		{Method: "POST", Path: "/api/v1/containers/:id/action", OperationID: "performContainerAction", Summary: "Start, stop, restart or remove a container", Tag: "containers",
+			Params: []openapi.Parameter{force}, Body: openapi.JSON(models.ContainerAction{}), Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/containers/:id/top", OperationID: "getContainerTop", Summary: "Processes running in a container", Tag: "containers",
+			Params:    []openapi.Parameter{openapi.Query("ps_args", "Arguments for ps (default -ef)", "")},
+			Responses: []openapi.Reply{openapi.OK(models.ProcessList{})}},
+		{Method: "PATCH", Path: "/api/v1/containers/:id/resources", OperationID: "updateContainerResources", Summary: "Update resource limits and restart policy", Tag: "containers",
+			Body: openapi.JSON(models.UpdateResourcesRequest{}),
+			Responses: []openapi.Reply{openapi.OK(openapi.Object{
+				"message":   "",
+				"previous":  models.ContainerResources{},
+				"resources": models.ContainerResources{},
+				"warnings":  []string{},
+			})}},
+		{Method: "GET", Path: "/api/v1/containers/:id/files", OperationID: "listContainerFiles", Summary: "List a directory inside a container", Tag: "files",
+			Params:    []openapi.Parameter{filePath},
+			Responses: []openapi.Reply{openapi.OK(openapi.Object{"path": "", "entries": []models.FileEntry{}})}},
+		{Method: "GET", Path: "/api/v1/containers/:id/files/download", OperationID: "downloadContainerFile", Summary: "Download a file, or a directory as tar", Tag: "files",
+			Params:    []openapi.Parameter{filePath},
+			Responses: []openapi.Reply{openapi.File("application/octet-stream"), openapi.File("application/x-tar")}},
+		{Method: "POST", Path: "/api/v1/containers/:id/files/upload", OperationID: "uploadContainerFiles", Summary: "Upload files into a container", Tag: "files",
+			Body: &openapi.Body{Content: map[string]any{"multipart/form-data": &openapi.Schema{
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"files":      {Type: "array", Items: openapi.Binary()},
+					"path":       openapi.Text(),
+					"uid":        {Type: "integer"},
+					"gid":        {Type: "integer"},
+					"mode":       {Type: "string", Format: "octal"},
+					"copyUidGid": {Type: "boolean"},
+				},
+				Required: []string{"files", "path"},
+			}}},
+			Responses: []openapi.Reply{openapi.OK(openapi.Object{"message": "", "paths": []string{}})}},
+		{Method: "GET", Path: "/api/v1/containers/:id/changes", OperationID: "getContainerChanges", Summary: "Filesystem changes since container creation", Tag: "files",
+			Responses: []openapi.Reply{openapi.OK([]models.FileChange{})}},
+
+		{Method: "GET", Path: "/api/v1/images", OperationID: "listImages", Summary: "List images", Tag: "images",
+			Responses: []openapi.Reply{openapi.OK([]models.Image{})}},
+		{Method: "POST", Path: "/api/v1/images/pull", OperationID: "pullImage", Summary: "Pull an image", Tag: "images",
+			Body: openapi.JSON(models.PullImageRequest{}), Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "DELETE", Path: "/api/v1/images/:id", OperationID: "removeImage", Summary: "Remove an image", Tag: "images",
+			Params: []openapi.Parameter{force}, Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "POST", Path: "/api/v1/images/prune", OperationID: "pruneImages", Summary: "Prune dangling images", Tag: "images",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+
+		{Method: "GET", Path: "/api/v1/volumes", OperationID: "listVolumes", Summary: "List volumes with size and using containers", Tag: "volumes",
+			Responses: []openapi.Reply{openapi.OK([]models.Volume{})}},
+		{Method: "POST", Path: "/api/v1/volumes", OperationID: "createVolume", Summary: "Create a volume", Tag: "volumes",
+			Body: openapi.JSON(models.CreateVolumeRequest{}), Responses: []openapi.Reply{openapi.Created(models.Volume{})}},
+		{Method: "GET", Path: "/api/v1/volumes/:name", OperationID: "inspectVolume", Summary: "Inspect a volume", Tag: "volumes",
+			Responses: []openapi.Reply{openapi.OK(models.Volume{})}},
+		{Method: "DELETE", Path: "/api/v1/volumes/:name", OperationID: "removeVolume", Summary: "Remove a volume", Tag: "volumes",
+			Params: []openapi.Parameter{force}, Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "POST", Path: "/api/v1/volumes/prune", OperationID: "pruneVolumes", Summary: "Prune unused volumes", Tag: "volumes",
+			Params:    []openapi.Parameter{openapi.Query("all", "Also prune named volumes", false)},
+			Responses: []openapi.Reply{openapi.OK(models.PruneVolumesResult{})}},
+		{Method: "GET", Path: "/api/v1/volumes/:name/backups", OperationID: "listVolumeBackups", Summary: "List backups of a volume", Tag: "backups",
+			Responses: []openapi.Reply{openapi.OK([]database.VolumeBackup{})}},
+		{Method: "POST", Path: "/api/v1/volumes/:name/backups", OperationID: "createBackup", Summary: "Back up a volume", Tag: "backups",
+			Body: openapi.OptionalJSON(backup.Options{}), Responses: []openapi.Reply{openapi.Created(database.VolumeBackup{})}},
+		{Method: "GET", Path: "/api/v1/volumes/:name/export", OperationID: "exportVolume", Summary: "Stream a volume as a compressed tar", Tag: "backups",
+			Responses: []openapi.Reply{openapi.File("application/gzip")}},
+
+		{Method: "GET", Path: "/api/v1/backups", OperationID: "listBackups", Summary: "List backups", Tag: "backups",
+			Params: []openapi.Parameter{
+				openapi.Query("volume", "Only backups of this volume", ""),
+			},
+			Responses: []openapi.Reply{openapi.OK([]database.VolumeBackup{})}},
+		{Method: "GET", Path: "/api/v1/backups/:id", OperationID: "getBackup", Summary: "Backup details", Tag: "backups",
+			Responses: []openapi.Reply{openapi.OK(database.VolumeBackup{})}},
+		{Method: "GET", Path: "/api/v1/backups/:id/download", OperationID: "downloadBackup", Summary: "Download a backup archive", Tag: "backups",
+			Responses: []openapi.Reply{{
+				Status: http.StatusOK, Description: "Backup archive", ContentType: "application/gzip", Value: openapi.Binary(),
+				Headers: map[string]openapi.Header{"X-Checksum-Sha256": {Description: "SHA-256 of the archive", Schema: openapi.Text()}},
+			}}},
+		{Method: "POST", Path: "/api/v1/backups/:id/restore", OperationID: "restoreBackup", Summary: "Restore a backup into a new or existing volume", Tag: "backups",
+			Body: openapi.OptionalJSON(backup.RestoreOptions{}), Responses: []openapi.Reply{openapi.OK(openapi.Object{"message": "", "volume": ""})}},
+		{Method: "DELETE", Path: "/api/v1/backups/:id", OperationID: "deleteBackup", Summary: "Delete a backup", Tag: "backups",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+
+		{Method: "GET", Path: "/api/v1/networks", OperationID: "listNetworks", Summary: "List networks", Tag: "networks",
+			Responses: []openapi.Reply{openapi.OK([]models.NetworkResource{})}},
+		{Method: "POST", Path: "/api/v1/networks", OperationID: "createNetwork", Summary: "Create a network", Tag: "networks",
+			Body: openapi.JSON(models.CreateNetworkRequest{}), Responses: []openapi.Reply{openapi.Created(models.NetworkResource{})}},
+		{Method: "GET", Path: "/api/v1/networks/:id", OperationID: "inspectNetwork", Summary: "Inspect a network and its containers", Tag: "networks",
+			Responses: []openapi.Reply{openapi.OK(models.NetworkResource{})}},
+		{Method: "DELETE", Path: "/api/v1/networks/:id", OperationID: "removeNetwork", Summary: "Remove a network", Tag: "networks",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "POST", Path: "/api/v1/networks/prune", OperationID: "pruneNetworks", Summary: "Prune unused networks", Tag: "networks",
+			Responses: []openapi.Reply{openapi.OK(models.PruneNetworksResult{})}},
+		{Method: "POST", Path: "/api/v1/networks/:id/connect", OperationID: "connectContainer", Summary: "Connect a container to a network", Tag: "networks",
+			Body: openapi.JSON(models.NetworkConnectRequest{}), Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "POST", Path: "/api/v1/networks/:id/disconnect", OperationID: "disconnectContainer", Summary: "Disconnect a container from a network", Tag: "networks",
+			Body: openapi.JSON(models.NetworkDisconnectRequest{}), Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/topology", OperationID: "getTopology", Summary: "Network topology graph", Tag: "networks",
+			Params: []openapi.Parameter{all, openapi.Query("format", "Response format (default json)", openapi.Enum("json", "dot"))},
+			Responses: []openapi.Reply{
+				openapi.OK(models.Topology{}),
+				{Status: http.StatusOK, ContentType: "text/vnd.graphviz", Value: openapi.Text()},
+			}},
+
+		{Method: "GET", Path: "/api/v1/stacks", OperationID: "listStacks", Summary: "Compose projects with per-service status", Tag: "stacks",
+			Responses: []openapi.Reply{openapi.OK([]models.Stack{})}},
+		{Method: "GET", Path: "/api/v1/stacks/:name", OperationID: "getStack", Summary: "Compose project details", Tag: "stacks",
+			Responses: []openapi.Reply{openapi.OK(models.Stack{})}},
+		stackAction("POST", "/api/v1/stacks/:name/start", "startStack", "Start a project in dependency order"),
+		stackAction("POST", "/api/v1/stacks/:name/stop", "stopStack", "Stop a project in reverse dependency order"),
+		stackAction("POST", "/api/v1/stacks/:name/restart", "restartStack", "Restart a project"),
+		stackAction("DELETE", "/api/v1/stacks/:name", "removeStack", "Remove a project's containers", force),
+		stackAction("POST", "/api/v1/stacks/:name/services/:service/start", "startStackService", "Start a single service"),
+		stackAction("POST", "/api/v1/stacks/:name/services/:service/stop", "stopStackService", "Stop a single service"),
+		stackAction("POST", "/api/v1/stacks/:name/services/:service/restart", "restartStackService", "Restart a single service"),
+		stackAction("DELETE", "/api/v1/stacks/:name/services/:service", "removeStackService", "Remove a service's containers", force),
+		{Method: "POST", Path: "/api/v1/stacks/:name/plan", OperationID: "planStack", Summary: "Preview the changes a compose file would make", Tag: "stacks",
+			Params: []openapi.Parameter{removeOrphans}, Body: compose, Responses: []openapi.Reply{openapi.OK(models.StackPlan{})}},
+		{Method: "POST", Path: "/api/v1/stacks/:name/deploy", OperationID: "deployStack", Summary: "Deploy a compose file as a new revision", Tag: "stacks",
+			Params: []openapi.Parameter{removeOrphans}, Body: compose, Responses: []openapi.Reply{openapi.OK(models.StackDeployment{})}},
+		{Method: "GET", Path: "/api/v1/stacks/:name/revisions", OperationID: "listStackRevisions", Summary: "List deployed revisions", Tag: "stacks",
+			Responses: []openapi.Reply{openapi.OK([]database.StackRevision{})}},
+		{Method: "GET", Path: "/api/v1/stacks/:name/revisions/:revision", OperationID: "getStackRevision", Summary: "A revision's compose file", Tag: "stacks",
+			Responses: []openapi.Reply{openapi.OK(database.StackRevision{})}},
+		{Method: "POST", Path: "/api/v1/stacks/:name/revisions/:revision/rollback", OperationID: "rollbackStack", Summary: "Redeploy a previous revision", Tag: "stacks",
+			Params: []openapi.Parameter{removeOrphans}, Responses: []openapi.Reply{openapi.OK(models.StackDeployment{})}},
+
+		{Method: "GET", Path: "/api/v1/metrics", OperationID: "getOverallMetrics", Summary: "Metrics of all containers", Tag: "metrics",
+			Responses: []openapi.Reply{openapi.OK(handlers.OverallMetrics{})}},
+		{Method: "GET", Path: "/api/v1/metrics/:id", OperationID: "getContainerMetrics", Summary: "Metrics of a container", Tag: "metrics",
+			Responses: []openapi.Reply{openapi.OK(handlers.ContainerMetrics{})}},
+		{Method: "GET", Path: "/api/v1/metrics/historical", OperationID: "getHistoricalMetrics", Summary: "Historical metrics", Tag: "metrics",
+			Params: []openapi.Parameter{
+				openapi.Query("container_id", "Container to report on", ""),
+				openapi.Query("hours", "Hours of history (default 1)", 0),
+			},
+			Responses: []openapi.Reply{openapi.OK(openapi.Object{"container_id": "", "hours": 0, "metrics": []handlers.SystemMetrics{}})}},
+	}
+}
+
+func apiRoutes() []openapi.Route {
+	logFilters := []openapi.Parameter{
+		openapi.Query("container", "Container name", ""),
+		openapi.Query("actor", "User or token name", ""),
+		openapi.Query("q", "Full-text search", ""),
+		openapi.Query("action", "Comma-separated actions", ""),
+		openapi.Query("limit", "Page size", 0),
+		openapi.Query("cursor", "Return entries before this id", 0),
+		openapi.Query("since", "RFC 3339 timestamp or YYYY-MM-DD", ""),
+		openapi.Query("until", "RFC 3339 timestamp or YYYY-MM-DD", ""),
+	}
+	exportFormats := openapi.Enum(audit.FormatCSV, audit.FormatNDJSON, audit.FormatSyslog)
+	aggregate := func(items any) openapi.Object {
+		return openapi.Object{"items": items, "errors": []models.HostError{}}
+	}
+
+	return []openapi.Route{
+		{Method: "POST", Path: "/api/v1/auth/login", OperationID: "login", Summary: "Log in and receive a session cookie", Tag: "auth", Public: true,
+			Body: openapi.JSON(models.LoginRequest{}), Responses: []openapi.Reply{openapi.OK(auth.Identity{})}},
+		{Method: "POST", Path: "/api/v1/auth/logout", OperationID: "logout", Summary: "End the current session", Tag: "auth",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/auth/me", OperationID: "getCurrentUser", Summary: "The authenticated user", Tag: "auth",
+			Responses: []openapi.Reply{openapi.OK(auth.Identity{})}},
+		{Method: "PUT", Path: "/api/v1/auth/password", OperationID: "changePassword", Summary: "Change the current user's password", Tag: "auth",
+			Body: openapi.JSON(models.ChangePasswordRequest{}), Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/auth/users", OperationID: "listUsers", Summary: "List users", Tag: "auth",
+			Responses: []openapi.Reply{openapi.OK([]database.User{})}},
+		{Method: "POST", Path: "/api/v1/auth/users", OperationID: "createUser", Summary: "Create a user", Tag: "auth",
+			Body: openapi.JSON(models.CreateUserRequest{}), Responses: []openapi.Reply{openapi.Created(database.User{})}},
+		{Method: "DELETE", Path: "/api/v1/auth/users/:id", OperationID: "deleteUser", Summary: "Delete a user with their sessions and tokens", Tag: "auth",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/auth/tokens", OperationID: "listTokens", Summary: "List the current user's API tokens", Tag: "auth",
+			Responses: []openapi.Reply{openapi.OK([]database.APIToken{})}},
+		{Method: "POST", Path: "/api/v1/auth/tokens", OperationID: "createToken", Summary: "Create an API token", Tag: "auth",
+			Body: openapi.JSON(models.CreateTokenRequest{}), Responses: []openapi.Reply{openapi.Created(openapi.Object{"token": "", "details": database.APIToken{}})}},
+		{Method: "DELETE", Path: "/api/v1/auth/tokens/:id", OperationID: "revokeToken", Summary: "Revoke an API token", Tag: "auth",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/auth/me/permissions", OperationID: "getMyPermissions", Summary: "The current user's effective permissions", Tag: "roles",
+			Responses: []openapi.Reply{openapi.OK([]models.EffectivePermission{})}},
+		{Method: "GET", Path: "/api/v1/auth/permissions", OperationID: "listPermissions", Summary: "Known permissions and the actions they guard", Tag: "roles",
+			Responses: []openapi.Reply{openapi.OK(openapi.Object{"permissions": []string{}, "actions": map[string]string{}})}},
+		{Method: "GET", Path: "/api/v1/auth/roles", OperationID: "listRoles", Summary: "List roles", Tag: "roles",
+			Responses: []openapi.Reply{openapi.OK([]database.Role{})}},
+		{Method: "POST", Path: "/api/v1/auth/roles", OperationID: "createRole", Summary: "Create a role", Tag: "roles",
+			Body: openapi.JSON(models.RoleRequest{}), Responses: []openapi.Reply{openapi.Created(database.Role{})}},
+		{Method: "PUT", Path: "/api/v1/auth/roles/:name", OperationID: "updateRole", Summary: "Update a role", Tag: "roles",
+			Body: openapi.JSON(models.RoleRequest{}), Responses: []openapi.Reply{openapi.OK(database.Role{})}},
+		{Method: "DELETE", Path: "/api/v1/auth/roles/:name", OperationID: "deleteRole", Summary: "Delete a role", Tag: "roles",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/auth/users/:id/roles", OperationID: "listUserRoles", Summary: "List a user's role bindings", Tag: "roles",
+			Responses: []openapi.Reply{openapi.OK([]database.RoleBinding{})}},
+		{Method: "POST", Path: "/api/v1/auth/users/:id/roles", OperationID: "assignRole", Summary: "Bind a role to a user", Tag: "roles",
+			Body: openapi.JSON(models.AssignRoleRequest{}), Responses: []openapi.Reply{openapi.Created(database.RoleBinding{})}},
+		{Method: "DELETE", Path: "/api/v1/auth/users/:id/roles/:binding", OperationID: "unassignRole", Summary: "Remove a role binding", Tag: "roles",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+
+		{Method: "GET", Path: "/api/v1/hosts", OperationID: "listHosts", Summary: "List Docker hosts", Tag: "hosts",
+			Params:    []openapi.Parameter{openapi.Query("status", "Check each daemon (default true)", false)},
+			Responses: []openapi.Reply{openapi.OK([]models.DockerHost{})}},
+		{Method: "POST", Path: "/api/v1/hosts", OperationID: "createHost", Summary: "Register a Docker host", Tag: "hosts",
+			Body: openapi.JSON(models.HostRequest{}), Responses: []openapi.Reply{openapi.Created(models.DockerHost{})}},
+		{Method: "GET", Path: "/api/v1/hosts/all/containers", OperationID: "listAllContainers", Summary: "Containers of every host", Tag: "hosts",
+			Params: []openapi.Parameter{openapi.Query("all", "Include stopped containers (default true)", false)}, Responses: []openapi.Reply{openapi.OK(aggregate([]models.Container{}))}},
+		{Method: "GET", Path: "/api/v1/hosts/all/images", OperationID: "listAllImages", Summary: "Images of every host", Tag: "hosts",
+			Responses: []openapi.Reply{openapi.OK(aggregate([]models.Image{}))}},
+		{Method: "GET", Path: "/api/v1/hosts/all/volumes", OperationID: "listAllVolumes", Summary: "Volumes of every host", Tag: "hosts",
+			Responses: []openapi.Reply{openapi.OK(aggregate([]models.Volume{}))}},
+		{Method: "GET", Path: "/api/v1/hosts/all/networks", OperationID: "listAllNetworks", Summary: "Networks of every host", Tag: "hosts",
+			Responses: []openapi.Reply{openapi.OK(aggregate([]models.NetworkResource{}))}},
+		{Method: "GET", Path: "/api/v1/hosts/:host", OperationID: "getHost", Summary: "Docker host details and status", Tag: "hosts",
+			Responses: []openapi.Reply{openapi.OK(models.DockerHost{})}},
+		{Method: "PUT", Path: "/api/v1/hosts/:host", OperationID: "updateHost", Summary: "Update a Docker host", Tag: "hosts",
+			Body: openapi.JSON(models.HostRequest{}), Responses: []openapi.Reply{openapi.OK(models.DockerHost{})}},
+		{Method: "DELETE", Path: "/api/v1/hosts/:host", OperationID: "deleteHost", Summary: "Remove a Docker host", Tag: "hosts",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+		{Method: "GET", Path: "/api/v1/ssh-keys", OperationID: "listSSHKeys", Summary: "List SSH keys", Tag: "hosts",
+			Responses: []openapi.Reply{openapi.OK([]models.SSHKey{})}},
+		{Method: "POST", Path: "/api/v1/ssh-keys", OperationID: "createSSHKey", Summary: "Generate or import an SSH key", Tag: "hosts",
+			Body: openapi.JSON(models.SSHKeyRequest{}), Responses: []openapi.Reply{openapi.Created(models.SSHKey{})}},
+		{Method: "DELETE", Path: "/api/v1/ssh-keys/:name", OperationID: "deleteSSHKey", Summary: "Delete an unused SSH key", Tag: "hosts",
+			Responses: []openapi.Reply{openapi.OK(messageResponse)}},
+
+		{Method: "GET", Path: "/api/v1/audit/health", OperationID: "getAuditHealth", Summary: "Audit writer health and counters", Tag: "activity",
+			Responses: []openapi.Reply{
+				openapi.OK(audit.Stats{}),
+				{Status: http.StatusServiceUnavailable, Description: "Audit writer unhealthy", ContentType: "application/json", Value: audit.Stats{}},
+			}},
+		{Method: "GET", Path: "/api/v1/audit/verify", OperationID: "verifyAuditLog", Summary: "Verify the activity log hash chain", Tag: "activity",
+			Responses: []openapi.Reply{openapi.OK(audit.Verification{})}},
+		{Method: "GET", Path: "/api/v1/audit/checkpoints", OperationID: "listAuditCheckpoints", Summary: "List signed activity log checkpoints", Tag: "activity",
+			Responses: []openapi.Reply{openapi.OK([]database.AuditCheckpoint{})}},
+		{Method: "GET", Path: "/api/v1/logs", OperationID: "searchActivityLogs", Summary: "Search activity logs", Tag: "activity",
+			Params: logFilters, Responses: []openapi.Reply{openapi.OK(database.LogPage{})}},
+		{Method: "GET", Path: "/api/v1/logs/export", OperationID: "exportActivityLogs", Summary: "Export activity logs", Tag: "activity",
+			Params: append([]openapi.Parameter{
+				openapi.Query("format", "Export format (default csv)", exportFormats),
+				openapi.Query("containerId", "Container id", ""),
+			}, logFilters...),
+			Responses: []openapi.Reply{
+				{Status: http.StatusOK, ContentType: "text/csv", Value: openapi.Text()},
+				{Status: http.StatusOK, ContentType: "application/x-ndjson", Value: openapi.Text()},
+				{Status: http.StatusOK, ContentType: "text/plain", Value: openapi.Text()},
+			}},
+		{Method: "GET", Path: "/api/v1/logs/:id", OperationID: "searchContainerActivityLogs", Summary: "Search activity logs of a container", Tag: "activity",
+			Params: logFilters, Responses: []openapi.Reply{openapi.OK(database.LogPage{})}},
+
+		{Method: "GET", Path: openAPIPath, OperationID: "getOpenAPI", Summary: "This document", Tag: "meta", Public: true,
+			Responses: []openapi.Reply{openapi.OK(&openapi.Schema{Type: "object"})}},
+		{Method: "GET", Path: "/health", OperationID: "getHealth", Summary: "Service and persistence health", Tag: "meta", Public: true,
+			Responses: []openapi.Reply{openapi.OK(openapi.Object{"status": openapi.Enum("ok", "degraded"), "persistence": database.PersistenceStatus{}})}},
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"docker-gui-backend/internal/openapi"
+)
+
+const specFile = "../../api/openapi.json"
+
+var updateSpec = flag.Bool("update", false, "rewrite api/openapi.json from the route table")
+
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	srv := newTestServer(t)
+	spec := apiSpec()
+
+	routed := map[string]bool{}
+	for _, route := range srv.router.Routes() {
+		key := route.Method + " " + openapi.PathTemplate(route.Path)
+		routed[key] = true
+
+		item, ok := spec.Paths[openapi.PathTemplate(route.Path)]
+		if !ok || item[strings.ToLower(route.Method)] == nil {
+			t.Errorf("route %s %s is not in the OpenAPI document", route.Method, route.Path)
+		}
+	}
+
+	ids := map[string]string{}
+	for path, item := range spec.Paths {
+		for method, op := range item {
+			key := strings.ToUpper(method) + " " + path
+			if !routed[key] {
+				t.Errorf("OpenAPI documents %s, which the router does not serve", key)
+			}
+			if other, dup := ids[op.OperationID]; dup {
+				t.Errorf("operationId %s is used by %s and %s", op.OperationID, other, key)
+			}
+			ids[op.OperationID] = key
+
+			for _, param := range op.Parameters {
+				if param.In == "path" && !strings.Contains(path, "{"+param.Name+"}") {
+					t.Errorf("%s documents path parameter %s that is not in the path", key, param.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPICoversModels(t *testing.T) {
+	spec := apiSpec()
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "../../pkg/models", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, s := range gen.Specs {
+					typeSpec := s.(*ast.TypeSpec)
+					if _, isStruct := typeSpec.Type.(*ast.StructType); !isStruct || !typeSpec.Name.IsExported() {
+						continue
+					}
+					if _, ok := spec.Components.Schemas[typeSpec.Name.Name]; !ok {
+						t.Errorf("models.%s is not described in the OpenAPI document", typeSpec.Name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	for name, schema := range spec.Components.Schemas {
+		if schema.Type == "object" && len(schema.Properties) == 0 && schema.AdditionalProperties == nil {
+			t.Errorf("component %s has no properties", name)
+		}
+	}
+}
+
+func TestOpenAPIDocumentIsCurrent(t *testing.T) {
+	current, err := json.MarshalIndent(apiSpec(), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current = append(current, '\n')
+
+	if *updateSpec {
+		if err := os.WriteFile(specFile, current, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	committed, err := os.ReadFile(specFile)
+	if err != nil {
+		t.Fatalf("%v (run go test ./cmd/server -run TestOpenAPIDocumentIsCurrent -update)", err)
+	}
+	if !bytes.Equal(committed, current) {
+		t.Errorf("api/openapi.json is out of date with the routes or models; review the change and run go test ./cmd/server -run TestOpenAPIDocumentIsCurrent -update")
+	}
+}
+
+func TestOpenAPIServed(t *testing.T) {
+	srv := newTestServer(t)
+
+	resp, err := http.Get(srv.URL + openAPIPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+
+	var served openapi.Document
+	if err := json.NewDecoder(resp.Body).Decode(&served); err != nil {
+		t.Fatal(err)
+	}
+	if served.OpenAPI != openapi.Version || len(served.Paths) != len(apiSpec().Paths) {
+		t.Errorf("served document differs: openapi %q, %d paths", served.OpenAPI, len(served.Paths))
+	}
+}
+
+func checkResponse(spec *openapi.Document, route string, resp *http.Response, body []byte) error {
+	method, path, _ := strings.Cut(route, " ")
+	item, ok := spec.Paths[openapi.PathTemplate(path)]
+	if !ok || item[strings.ToLower(method)] == nil {
+		return fmt.Errorf("not documented")
+	}
+	op := item[strings.ToLower(method)]
+
+	response, ok := op.Responses[fmt.Sprint(resp.StatusCode)]
+	if !ok {
+		if resp.StatusCode < 400 {
+			return fmt.Errorf("status %d is not documented", resp.StatusCode)
+		}
+		response = op.Responses["default"]
+	}
+
+	contentType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	media, ok := response.Content[contentType]
+	if !ok {
+		if len(response.Content) == 0 && len(body) == 0 {
+			return nil
+		}
+		return fmt.Errorf("content type %q for status %d is not documented", contentType, resp.StatusCode)
+	}
+	if contentType != "application/json" {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+	if problems := validate(spec, media.Schema, value, "$"); len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("status %d does not match the schema:\n\t%s", resp.StatusCode, strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
+
+func validate(spec *openapi.Document, schema *openapi.Schema, value any, at string) []string {
+	if schema.Ref != "" {
+		return validate(spec, spec.Components.Schemas[openapi.RefName(schema.Ref)], value, at)
+	}
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.AllOf) == 0) {
+			return nil
+		}
+		return []string{at + ": null is not allowed"}
+	}
+
+	var problems []string
+	for _, sub := range schema.AllOf {
+		problems = append(problems, validate(spec, sub, value, at)...)
+	}
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, sub := range schema.OneOf {
+			if len(validate(spec, sub, value, at)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			problems = append(problems, fmt.Sprintf("%s: matches %d of the oneOf schemas", at, matches))
+		}
+	}
+
+	mismatch := func() []string {
+		return append(problems, fmt.Sprintf("%s: %T is not %s", at, value, schema.Type))
+	}
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", at, name))
+			}
+		}
+		for name, v := range object {
+			switch property, ok := schema.Properties[name]; {
+			case ok:
+				problems = append(problems, validate(spec, property, v, at+"."+name)...)
+			case schema.AdditionalProperties != nil:
+				problems = append(problems, validate(spec, schema.AdditionalProperties, v, at+"."+name)...)
+			case len(schema.Properties) > 0:
+				problems = append(problems, fmt.Sprintf("%s: undocumented property %s", at, name))
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return mismatch()
+		}
+		for i, v := range array {
+			problems = append(problems, validate(spec, schema.Items, v, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		if len(schema.Enum) > 0 && !containsValue(schema.Enum, s) {
+			problems = append(problems, fmt.Sprintf("%s: %q is not one of %v", at, s, schema.Enum))
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok || strings.ContainsAny(n.String(), ".eE") {
+			return mismatch()
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return mismatch()
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch()
+		}
+	}
+	return problems
+}
+
+func containsValue(values []any, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"docker-gui-backend/internal/docker/dockertest"
+	"docker-gui-backend/pkg/models"
+)
+
+func TestScopedContainerAccess(t *testing.T) {
+	srv := newTestServer(t)
+	admin := srv.login(t)
+
+	web := srv.vars["web"]
+	srv.fake.AddContainer(dockertest.Container{Name: web[:12], Image: "redis:7"})
+	twin := web[:6] + "0"
+	if web[6] == '0' {
+		twin = web[:6] + "1"
+	}
+	srv.fake.AddContainer(dockertest.Container{ID: twin, Name: "twin", Image: "redis:7"})
+
+	srv.run(t, admin, []routeCase{
+		{method: "POST", path: "/api/v1/auth/users", body: `{"username":"webops","password":"webops-password-1"}`, want: 201, save: "user"},
+		{method: "POST", path: "/api/v1/auth/users/{user}/roles", body: `{"role":"operator","scopeName":"web"}`, want: 201},
+	}, map[string]bool{})
+
+	webops := srv.loginAs(t, "webops", "webops-password-1")
+	srv.run(t, webops, []routeCase{
+		{method: "POST", path: "/api/v1/containers/" + web[:12] + "/stop", want: http.StatusForbidden},
+		{method: "POST", path: "/api/v1/containers/" + web[:6] + "/stop", want: http.StatusBadRequest},
+		{method: "POST", path: "/api/v1/containers/" + web[:8] + "/stop", want: http.StatusOK},
+		{method: "GET", path: "/api/v1/containers/web/files/download?path=/etc/nginx/nginx.conf", want: http.StatusForbidden},
+		{method: "GET", path: "/api/v1/volumes/data/export", want: http.StatusForbidden},
+	}, map[string]bool{})
+
+	if c, _ := srv.fake.Container(web[:12]); c.State != "running" {
+		t.Errorf("container named like the web ID prefix is %s, want running", c.State)
+	}
+	if c, _ := srv.fake.Container(web); c.State != "exited" {
+		t.Errorf("web is %s, want exited", c.State)
+	}
+}
+
+func TestScopedStackAccess(t *testing.T) {
+	srv := newTestServer(t)
+	admin := srv.login(t)
+
+	other := `{"compose":"services:\n  db:\n    image: redis:7\n"}`
+	srv.run(t, admin, []routeCase{
+		{method: "POST", path: "/api/v1/stacks/shop/deploy", body: composeFile, want: 200},
+		{method: "POST", path: "/api/v1/stacks/billing/deploy", body: other, want: 200},
+		{method: "POST", path: "/api/v1/auth/users", body: `{"username":"shopops","password":"shopops-password-1"}`, want: 201, save: "user"},
+		{method: "POST", path: "/api/v1/auth/users/{user}/roles", body: `{"role":"operator","scopeLabel":"com.docker.compose.project=shop"}`, want: 201},
+	}, map[string]bool{})
+
+	shopops := srv.loginAs(t, "shopops", "shopops-password-1")
+	srv.run(t, shopops, []routeCase{
+		{method: "GET", path: "/api/v1/stacks/shop", want: http.StatusOK},
+		{method: "POST", path: "/api/v1/stacks/shop/restart", want: http.StatusOK, check: stackStates("shop", "running", "running")},
+		{method: "POST", path: "/api/v1/stacks/shop/services/web/stop", want: http.StatusOK, check: stackStates("shop", "running", "exited")},
+		{method: "GET", path: "/api/v1/stacks/billing", want: http.StatusForbidden},
+		{method: "POST", path: "/api/v1/stacks/billing/stop", want: http.StatusForbidden, check: stackStates("billing", "running")},
+		{method: "POST", path: "/api/v1/stacks/shop/deploy", body: composeFile, want: http.StatusForbidden},
+	}, map[string]bool{})
+
+	resp, err := shopops.Get(srv.URL + "/api/v1/stacks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []models.Stack
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list) != 1 || list[0].Name != "shop" {
+		t.Errorf("scoped user sees stacks %+v, want only shop", list)
+	}
+}
+
+func TestScopedTopology(t *testing.T) {
+	srv := newTestServer(t)
+	admin := srv.login(t)
+
+	srv.run(t, admin, []routeCase{
+		{method: "POST", path: "/api/v1/auth/roles", body: `{"name":"netviewer","permissions":["networks:read"]}`, want: 201},
+		{method: "POST", path: "/api/v1/auth/users", body: `{"username":"netops","password":"netops-password-1"}`, want: 201, save: "user"},
+		{method: "POST", path: "/api/v1/auth/users/{user}/roles", body: `{"role":"netviewer"}`, want: 201},
+		{method: "POST", path: "/api/v1/auth/users", body: `{"username":"webops","password":"webops-password-1"}`, want: 201, save: "user"},
+		{method: "POST", path: "/api/v1/auth/users/{user}/roles", body: `{"role":"netviewer"}`, want: 201},
+		{method: "POST", path: "/api/v1/auth/users/{user}/roles", body: `{"role":"operator","scopeName":"web"}`, want: 201},
+	}, map[string]bool{})
+
+	netops := srv.loginAs(t, "netops", "netops-password-1")
+	srv.run(t, netops, []routeCase{
+		{method: "GET", path: "/api/v1/networks", want: http.StatusOK},
+		{method: "GET", path: "/api/v1/topology", want: http.StatusForbidden},
+	}, map[string]bool{})
+
+	webops := srv.loginAs(t, "webops", "webops-password-1")
+	resp, err := webops.Get(srv.URL + "/api/v1/topology")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var graph models.Topology
+	json.NewDecoder(resp.Body).Decode(&graph)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scoped topology returned %d", resp.StatusCode)
+	}
+
+	nodes := map[string]bool{}
+	var containers []string
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = true
+		if node.Type == "container" {
+			containers = append(containers, node.Label)
+		}
+	}
+	if len(containers) != 1 || containers[0] != "web" {
+		t.Errorf("scoped user sees containers %v, want only web", containers)
+	}
+	for _, edge := range graph.Edges {
+		if !nodes[edge.Source] || !nodes[edge.Target] {
+			t.Errorf("edge %s -- %s points outside the visible graph", edge.Source, edge.Target)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/auth"
+	"docker-gui-backend/internal/backup"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/handlers"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/internal/rbac"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+type services struct {
+	db             *database.Resilient
+	pool           *hosts.Pool
+	backups        *backup.Service
+	auth           *auth.Service
+	authorizer     *rbac.Authorizer
+	auditWriter    *audit.Writer
+	auditForwarder *audit.Forwarder
+	auditChain     *audit.Chain
+}
+
+func newServices(db *database.Resilient, dockerClient docker.API) (s *services, err error) {
+	pool, err := hosts.NewPool(db, dockerClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Docker hosts: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			pool.Close()
+		}
+	}()
+
+	s = &services{db: db, pool: pool}
+	if s.backups, err = backup.NewService(dockerClient, db); err != nil {
+		return nil, fmt.Errorf("failed to initialize backup service: %w", err)
+	}
+	if s.auth, err = auth.NewService(db); err != nil {
+		return nil, fmt.Errorf("failed to initialize authentication: %w", err)
+	}
+	if s.authorizer, err = rbac.NewAuthorizer(db, pool); err != nil {
+		return nil, fmt.Errorf("failed to initialize access control: %w", err)
+	}
+	if s.auditWriter, err = audit.NewWriter(db); err != nil {
+		return nil, fmt.Errorf("failed to initialize audit writer: %w", err)
+	}
+	if s.auditForwarder, err = audit.NewForwarder(db); err != nil {
+		return nil, fmt.Errorf("failed to initialize audit forwarding: %w", err)
+	}
+	if s.auditChain, err = audit.NewChain(db); err != nil {
+		return nil, fmt.Errorf("failed to initialize audit checkpoints: %w", err)
+	}
+	return s, nil
+}
+
+func newRouter(s *services) *gin.Engine {
+	r := gin.Default()
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Printf("Warning: ignoring TRUSTED_PROXIES: %v", err)
+		r.SetTrustedProxies(nil)
+	}
+
+	config := cors.DefaultConfig()
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		config.AllowOrigins = strings.Split(origins, ",")
+		config.AllowCredentials = true
+	} else {
+		config.AllowAllOrigins = true
+	}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", audit.RequestIDHeader}
+	config.ExposeHeaders = []string{audit.RequestIDHeader}
+	r.Use(cors.New(config))
+	r.Use(audit.RequestID())
+
+	containerHandler := handlers.NewContainerHandler(s.pool, s.db)
+	metricsHandler := handlers.NewMetricsHandler(s.pool)
+	imageHandler := handlers.NewImageHandler(s.pool)
+	authHandler := handlers.NewAuthHandler(s.auth, s.db)
+	roleHandler := handlers.NewRoleHandler(s.authorizer, s.db)
+	auditHandler := handlers.NewAuditHandler(s.auditWriter, s.auditForwarder, s.auditChain, s.db)
+	volumeHandler := handlers.NewVolumeHandler(s.pool)
+	backupHandler := handlers.NewBackupHandler(s.backups, s.pool, s.db)
+	networkHandler := handlers.NewNetworkHandler(s.pool)
+	topologyHandler := handlers.NewTopologyHandler(s.pool)
+	filesystemHandler := handlers.NewFilesystemHandler(s.pool)
+	stackHandler := handlers.NewStackHandler(s.pool, s.db)
+	hostHandler := handlers.NewHostHandler(s.pool)
+
+	registerDockerRoutes := func(group *gin.RouterGroup) {
+		containers := group.Group("/containers")
+		{
+			containers.GET("", s.authorizer.RequireContainerList(rbac.ContainersRead), containerHandler.ListContainers)
+			containers.POST("/:id/start", s.authorizer.RequireContainer(rbac.ContainersStart), containerHandler.StartContainer)
+			containers.POST("/:id/stop", s.authorizer.RequireContainer(rbac.ContainersStop), containerHandler.StopContainer)
+			containers.POST("/:id/restart", s.authorizer.RequireContainer(rbac.ContainersRestart), containerHandler.RestartContainer)
+			containers.DELETE("/:id", s.authorizer.RequireContainer(rbac.ContainersRemove), containerHandler.RemoveContainer)
+			containers.GET("/:id/logs", s.authorizer.RequireContainer(rbac.ContainersRead), containerHandler.GetContainerLogs)
+			containers.GET("/:id/stats", s.authorizer.RequireContainer(rbac.ContainersRead), containerHandler.GetContainerStats)
+			containers.POST("/:id/action", s.authorizer.RequireAction(), containerHandler.PerformAction)
+			containers.GET("/:id/top", s.authorizer.RequireContainer(rbac.ContainersRead), containerHandler.GetContainerTop)
+			containers.PATCH("/:id/resources", s.authorizer.RequireContainer(rbac.ContainersUpdate), containerHandler.UpdateContainerResources)
+			containers.GET("/:id/files", s.authorizer.RequireContainer(rbac.ContainersRead), filesystemHandler.ListFiles)
+			containers.GET("/:id/files/download", s.authorizer.RequireContainer(rbac.ContainersFiles), filesystemHandler.DownloadFile)
+			containers.POST("/:id/files/upload", s.authorizer.RequireContainer(rbac.ContainersUpload), filesystemHandler.UploadFiles)
+			containers.GET("/:id/changes", s.authorizer.RequireContainer(rbac.ContainersRead), filesystemHandler.GetChanges)
+		}
+
+		images := group.Group("/images")
+		{
+			images.GET("", s.authorizer.Require(rbac.ImagesRead), imageHandler.ListImages)
+			images.POST("/pull", s.authorizer.Require(rbac.ImagesPull), imageHandler.PullImage)
+			images.DELETE("/:id", s.authorizer.Require(rbac.ImagesRemove), imageHandler.RemoveImage)
+			images.POST("/prune", s.authorizer.Require(rbac.ImagesPrune), imageHandler.PruneImages)
+		}
+
+		volumes := group.Group("/volumes")
+		{
+			volumes.GET("", s.authorizer.Require(rbac.VolumesRead), volumeHandler.ListVolumes)
+			volumes.POST("", s.authorizer.Require(rbac.VolumesCreate), volumeHandler.CreateVolume)
+			volumes.GET("/:name", s.authorizer.Require(rbac.VolumesRead), volumeHandler.InspectVolume)
+			volumes.DELETE("/:name", s.authorizer.Require(rbac.VolumesRemove), volumeHandler.RemoveVolume)
+			volumes.POST("/prune", s.authorizer.Require(rbac.VolumesRemove), volumeHandler.PruneVolumes)
+			volumes.GET("/:name/backups", s.authorizer.Require(rbac.BackupsRead), backupHandler.ListVolumeBackups)
+			volumes.POST("/:name/backups", s.authorizer.Require(rbac.BackupsCreate), backupHandler.CreateBackup)
+			volumes.GET("/:name/export", s.authorizer.Require(rbac.VolumesExport), backupHandler.ExportVolume)
+		}
+
+		backups := group.Group("/backups")
+		{
+			backups.GET("", s.authorizer.Require(rbac.BackupsRead), backupHandler.ListBackups)
+			backups.GET("/:id", s.authorizer.Require(rbac.BackupsRead), backupHandler.GetBackup)
+			backups.GET("/:id/download", s.authorizer.Require(rbac.BackupsDownload), backupHandler.DownloadBackup)
+			backups.POST("/:id/restore", s.authorizer.Require(rbac.BackupsRestore), backupHandler.RestoreBackup)
+			backups.DELETE("/:id", s.authorizer.Require(rbac.BackupsDelete), backupHandler.DeleteBackup)
+		}
+
+		networks := group.Group("/networks")
+		{
+			networks.GET("", s.authorizer.Require(rbac.NetworksRead), networkHandler.ListNetworks)
+			networks.POST("", s.authorizer.Require(rbac.NetworksWrite), networkHandler.CreateNetwork)
+			networks.GET("/:id", s.authorizer.Require(rbac.NetworksRead), networkHandler.InspectNetwork)
+			networks.DELETE("/:id", s.authorizer.Require(rbac.NetworksRemove), networkHandler.RemoveNetwork)
+			networks.POST("/prune", s.authorizer.Require(rbac.NetworksRemove), networkHandler.PruneNetworks)
+			networks.POST("/:id/connect", s.authorizer.Require(rbac.NetworksWrite), networkHandler.ConnectContainer)
+			networks.POST("/:id/disconnect", s.authorizer.Require(rbac.NetworksWrite), networkHandler.DisconnectContainer)
+		}
+
+		group.GET("/topology", s.authorizer.Require(rbac.NetworksRead), s.authorizer.RequireContainerList(rbac.ContainersRead), topologyHandler.GetTopology)
+
+		stackRoutes := group.Group("/stacks")
+		{
+			stackRoutes.GET("", s.authorizer.RequireContainerList(rbac.StacksRead), stackHandler.ListStacks)
+			stackRoutes.GET("/:name", s.authorizer.RequireStack(rbac.StacksRead), stackHandler.GetStack)
+			stackRoutes.POST("/:name/start", s.authorizer.RequireStack(rbac.StacksOperate), stackHandler.StartStack)
+			stackRoutes.POST("/:name/stop", s.authorizer.RequireStack(rbac.StacksOperate), stackHandler.StopStack)
+			stackRoutes.POST("/:name/restart", s.authorizer.RequireStack(rbac.StacksOperate), stackHandler.RestartStack)
+			stackRoutes.DELETE("/:name", s.authorizer.RequireStack(rbac.StacksRemove), stackHandler.RemoveStack)
+			stackRoutes.POST("/:name/services/:service/start", s.authorizer.RequireStack(rbac.StacksOperate), stackHandler.StartStack)
+			stackRoutes.POST("/:name/services/:service/stop", s.authorizer.RequireStack(rbac.StacksOperate), stackHandler.StopStack)
+			stackRoutes.POST("/:name/services/:service/restart", s.authorizer.RequireStack(rbac.StacksOperate), stackHandler.RestartStack)
+			stackRoutes.DELETE("/:name/services/:service", s.authorizer.RequireStack(rbac.StacksRemove), stackHandler.RemoveStack)
+			stackRoutes.POST("/:name/plan", s.authorizer.Require(rbac.StacksDeploy), stackHandler.PlanStack)
+			stackRoutes.POST("/:name/deploy", s.authorizer.Require(rbac.StacksDeploy), stackHandler.DeployStack)
+			stackRoutes.GET("/:name/revisions", s.authorizer.RequireStack(rbac.StacksRead), stackHandler.ListRevisions)
+			stackRoutes.GET("/:name/revisions/:revision", s.authorizer.RequireStack(rbac.StacksRead), stackHandler.GetRevision)
+			stackRoutes.POST("/:name/revisions/:revision/rollback", s.authorizer.Require(rbac.StacksDeploy), stackHandler.RollbackStack)
+		}
+
+		metrics := group.Group("/metrics")
+		{
+			metrics.GET("", s.authorizer.Require(rbac.MetricsRead), metricsHandler.GetOverallMetrics)
+			metrics.GET("/:id", s.authorizer.RequireContainer(rbac.MetricsRead), metricsHandler.GetContainerMetrics)
+			metrics.GET("/historical", s.authorizer.Require(rbac.MetricsRead), metricsHandler.GetHistoricalMetrics)
+		}
+	}
+
+	api := r.Group("/api/v1")
+	api.Use(s.auth.Middleware("/api/v1/auth/login", openAPIPath), s.auditWriter.Middleware())
+	{
+		api.GET("/openapi.json", apiSpec().Handler())
+
+		authRoutes := api.Group("/auth")
+		{
+			authRoutes.POST("/login", authHandler.Login)
+			authRoutes.POST("/logout", authHandler.Logout)
+			authRoutes.GET("/me", authHandler.Me)
+			authRoutes.PUT("/password", authHandler.ChangePassword)
+			authRoutes.GET("/users", s.authorizer.Require(rbac.UsersManage), authHandler.ListUsers)
+			authRoutes.POST("/users", s.authorizer.Require(rbac.UsersManage), authHandler.CreateUser)
+			authRoutes.DELETE("/users/:id", s.authorizer.Require(rbac.UsersManage), authHandler.DeleteUser)
+			authRoutes.GET("/tokens", authHandler.ListTokens)
+			authRoutes.POST("/tokens", authHandler.CreateToken)
+			authRoutes.DELETE("/tokens/:id", authHandler.RevokeToken)
+			authRoutes.GET("/me/permissions", roleHandler.MyPermissions)
+			authRoutes.GET("/permissions", s.authorizer.Require(rbac.RolesManage), roleHandler.ListPermissions)
+			authRoutes.GET("/roles", s.authorizer.Require(rbac.RolesManage), roleHandler.ListRoles)
+			authRoutes.POST("/roles", s.authorizer.Require(rbac.RolesManage), roleHandler.CreateRole)
+			authRoutes.PUT("/roles/:name", s.authorizer.Require(rbac.RolesManage), roleHandler.UpdateRole)
+			authRoutes.DELETE("/roles/:name", s.authorizer.Require(rbac.RolesManage), roleHandler.DeleteRole)
+			authRoutes.GET("/users/:id/roles", s.authorizer.Require(rbac.UsersManage), roleHandler.ListUserRoles)
+			authRoutes.POST("/users/:id/roles", s.authorizer.Require(rbac.UsersManage), roleHandler.AssignRole)
+			authRoutes.DELETE("/users/:id/roles/:binding", s.authorizer.Require(rbac.UsersManage), roleHandler.UnassignRole)
+		}
+
+		registerDockerRoutes(api.Group("", s.pool.Middleware()))
+		registerDockerRoutes(api.Group("/hosts/:host", s.pool.Middleware()))
+
+		hostRoutes := api.Group("/hosts")
+		{
+			hostRoutes.GET("", s.authorizer.Require(rbac.HostsRead), hostHandler.ListHosts)
+			hostRoutes.POST("", s.authorizer.Require(rbac.HostsManage), hostHandler.CreateHost)
+			hostRoutes.GET("/all/containers", s.authorizer.RequireContainerList(rbac.ContainersRead), hostHandler.ListContainers)
+			hostRoutes.GET("/all/images", s.authorizer.Require(rbac.ImagesRead), hostHandler.ListImages)
+			hostRoutes.GET("/all/volumes", s.authorizer.Require(rbac.VolumesRead), hostHandler.ListVolumes)
+			hostRoutes.GET("/all/networks", s.authorizer.Require(rbac.NetworksRead), hostHandler.ListNetworks)
+			hostRoutes.GET("/:host", s.authorizer.Require(rbac.HostsRead), hostHandler.GetHost)
+			hostRoutes.PUT("/:host", s.authorizer.Require(rbac.HostsManage), hostHandler.UpdateHost)
+			hostRoutes.DELETE("/:host", s.authorizer.Require(rbac.HostsManage), hostHandler.DeleteHost)
+		}
+
+		keyRoutes := api.Group("/ssh-keys")
+		{
+			keyRoutes.GET("", s.authorizer.Require(rbac.HostsRead), hostHandler.ListSSHKeys)
+			keyRoutes.POST("", s.authorizer.Require(rbac.HostsManage), hostHandler.CreateSSHKey)
+			keyRoutes.DELETE("/:name", s.authorizer.Require(rbac.HostsManage), hostHandler.DeleteSSHKey)
+		}
+
+		api.GET("/audit/health", s.authorizer.Require(rbac.LogsRead), auditHandler.Health)
+		api.GET("/audit/verify", s.authorizer.Require(rbac.LogsRead), auditHandler.Verify)
+		api.GET("/audit/checkpoints", s.authorizer.Require(rbac.LogsRead), auditHandler.ListCheckpoints)
+
+		logs := api.Group("/logs")
+		{
+			logs.GET("", s.authorizer.Require(rbac.LogsRead), containerHandler.GetActivityLogs)
+			logs.GET("/export", s.authorizer.Require(rbac.LogsRead), auditHandler.Export)
+			logs.GET("/:id", s.authorizer.RequireContainer(rbac.LogsRead), containerHandler.GetContainerActivityLogs)
+		}
+
+	}
+
+	r.GET("/health", func(c *gin.Context) {
+		persistence := s.db.Status()
+		status := "ok"
+		if persistence.State != database.StateConnected {
+			status = "degraded"
+		}
+		c.JSON(200, gin.H{"status": status, "persistence": persistence})
+	})
+
+	r.NoRoute(func(c *gin.Context) {
+		apierror.Respond(c, apierror.NotFound("route not found"))
+	})
+
+	return r
+}
+
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
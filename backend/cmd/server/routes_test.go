@@ -0,0 +1,655 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker/dockertest"
+	"docker-gui-backend/internal/openapi"
+	"docker-gui-backend/internal/stacks"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	adminUser     = "admin"
+	adminPassword = "test-password-1"
+	sshHostKey    = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKDtt4tYhvNmvr1jP3ZjU3+TC8NE4qSeEITX01R6k7Kf"
+)
+
+type routeCase struct {
+	method      string
+	path        string
+	body        string
+	contentType string
+	want        int
+	save        string
+	field       string
+	check       func(t *testing.T, fake *dockertest.Fake)
+}
+
+type testServer struct {
+	*httptest.Server
+	fake   *dockertest.Fake
+	router *gin.Engine
+	spec   *openapi.Document
+	vars   map[string]string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("DATABASE_DRIVER", database.DriverMemory)
+	t.Setenv("PERSISTENCE_QUEUE_PATH", filepath.Join(dir, "queue.jsonl"))
+	t.Setenv("BACKUP_DIR", filepath.Join(dir, "backups"))
+	t.Setenv("AUDIT_MODE", "compliance")
+	t.Setenv("AUDIT_CHECKPOINT_KEY", "route-test-checkpoint-key")
+	t.Setenv("SSH_KEY_SECRET", "route-test-ssh-key-secret")
+	t.Setenv("AUDIT_FORWARD_ADDR", "")
+	t.Setenv("DOCKER_DEFAULT_HOST", "")
+	t.Setenv("ADMIN_USERNAME", adminUser)
+	t.Setenv("ADMIN_PASSWORD", adminPassword)
+
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+
+	fake, web := seedFake()
+
+	db, err := database.Open()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	svc, err := newServices(db, fake)
+	if err != nil {
+		t.Fatalf("init services: %v", err)
+	}
+	t.Cleanup(svc.pool.Close)
+
+	router := newRouter(svc)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	return &testServer{Server: srv, fake: fake, router: router, spec: apiSpec(), vars: map[string]string{"web": web}}
+}
+
+func seedFake() (*dockertest.Fake, string) {
+	fake := dockertest.New()
+	fake.AddImage("nginx:1.27", 187_000_000)
+	fake.AddImage("redis:7", 117_000_000)
+	fake.AddImage("busybox:latest", 4_200_000)
+	fake.AddVolume("data", nil)
+	fake.AddVolume("scratch", nil)
+	fake.SetVolumeData("data", volumeArchive(map[string]string{"index.html": "<h1>hello</h1>\n"}))
+
+	now := time.Now().UTC()
+	web := fake.AddContainer(dockertest.Container{
+		Name:   "web",
+		Image:  "nginx:1.27",
+		Labels: map[string]string{"tier": "frontend"},
+		Ports:  []models.Port{{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"}},
+		Mounts: []models.Mount{{Type: "volume", Name: "data", Source: "data", Destination: "/usr/share/nginx/html", RW: true}},
+		Logs: []models.LogEntry{
+			{Timestamp: now.Add(-2 * time.Second), Message: "[notice] 1#1: start worker processes", Stream: "stdout"},
+			{Timestamp: now.Add(-time.Second), Message: "GET / 200", Stream: "stdout"},
+			{Timestamp: now, Message: "[error] 29#29: upstream timed out", Stream: "stderr"},
+		},
+		Stats: models.ContainerStats{
+			CPUUsage: 2.5,
+			Memory:   models.Memory{Usage: 24 << 20, Limit: 512 << 20, Percent: 4.7},
+		},
+		Files: map[string]string{
+			"/etc/nginx/nginx.conf":            "worker_processes 1;\n",
+			"/usr/share/nginx/html/index.html": "<h1>hello</h1>\n",
+		},
+		Links: map[string]string{"/etc/nginx/conf.d/default.conf": "../nginx.conf"},
+	})
+	fake.AddContainer(dockertest.Container{Name: "worker", Image: "redis:7", State: "exited", ExitCode: 0})
+	return fake, web
+}
+
+func (s *testServer) login(t *testing.T) *http.Client {
+	t.Helper()
+	return s.loginAs(t, adminUser, adminPassword)
+}
+
+func (s *testServer) loginAs(t *testing.T, username, password string) *http.Client {
+	t.Helper()
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	body := fmt.Sprintf(`{"username":%q,"password":%q}`, username, password)
+	resp, err := client.Post(s.URL+"/api/v1/auth/login", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("login: status %d", resp.StatusCode)
+	}
+	return client
+}
+
+func (s *testServer) run(t *testing.T, client *http.Client, cases []routeCase, covered map[string]bool) {
+	t.Helper()
+
+	routes := s.router.Routes()
+	for _, tc := range cases {
+		path := tc.path
+		for name, value := range s.vars {
+			path = strings.ReplaceAll(path, "{"+name+"}", value)
+		}
+
+		req, err := http.NewRequest(tc.method, s.URL+path, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, path, err)
+		}
+		switch {
+		case tc.contentType != "":
+			req.Header.Set("Content-Type", tc.contentType)
+		case tc.body != "":
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s: status %d, want %d: %s", tc.method, path, resp.StatusCode, tc.want, body)
+		}
+		if tc.check != nil && resp.StatusCode == tc.want {
+			tc.check(t, s.fake)
+		}
+		if tc.save != "" && resp.StatusCode < 300 {
+			value, ok := lookupField(body, tc.field)
+			if !ok {
+				t.Fatalf("%s %s: nothing to save in %s", tc.method, path, body)
+			}
+			s.vars[tc.save] = value
+		}
+
+		if route := matchRoute(routes, tc.method, strings.SplitN(path, "?", 2)[0]); route != "" {
+			covered[route] = true
+			if err := checkResponse(s.spec, route, resp, body); err != nil {
+				t.Errorf("%s %s: %v", tc.method, path, err)
+			}
+		}
+	}
+}
+
+func lookupField(body []byte, field string) (string, bool) {
+	if field == "" {
+		field = "id"
+	}
+
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+	for _, key := range strings.Split(field, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		value = object[key]
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		return v.String(), true
+	case string:
+		return v, v != ""
+	default:
+		return "", false
+	}
+}
+
+func matchRoute(routes gin.RoutesInfo, method, path string) string {
+	segments := strings.Split(path, "/")
+	best, bestScore := "", -1
+
+	for _, route := range routes {
+		if route.Method != method {
+			continue
+		}
+		pattern := strings.Split(route.Path, "/")
+		if len(pattern) != len(segments) {
+			continue
+		}
+
+		score := 0
+		for i, part := range pattern {
+			switch {
+			case strings.HasPrefix(part, ":"):
+			case part == segments[i]:
+				score++
+			default:
+				score = -1
+			}
+			if score < 0 {
+				break
+			}
+		}
+		if score > bestScore {
+			best, bestScore = route.Method+" "+route.Path, score
+		}
+	}
+	return best
+}
+
+func volumeArchive(files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func multipartUpload(t *testing.T, dir string, files map[string]string) (string, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	w.WriteField("path", dir)
+	for name, content := range files {
+		part, err := w.CreateFormFile("files", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	w.Close()
+	return buf.String(), w.FormDataContentType()
+}
+
+func containerState(name, want string) func(*testing.T, *dockertest.Fake) {
+	return func(t *testing.T, fake *dockertest.Fake) {
+		t.Helper()
+
+		c, ok := fake.Container(name)
+		if !ok || c.State != want {
+			t.Errorf("container %s is %q (exists %v), want %q", name, c.State, ok, want)
+		}
+	}
+}
+
+func stackStates(project string, want ...string) func(*testing.T, *dockertest.Fake) {
+	return func(t *testing.T, fake *dockertest.Fake) {
+		t.Helper()
+
+		containers, err := fake.ListContainers(context.Background(), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var services []string
+		states := map[string]string{}
+		for _, c := range containers {
+			if c.Labels[stacks.LabelProject] == project {
+				service := c.Labels[stacks.LabelService]
+				services = append(services, service)
+				states[service] = c.State
+			}
+		}
+		sort.Strings(services)
+
+		var got []string
+		for _, service := range services {
+			got = append(got, states[service])
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("stack %s services %v are %v, want %v", project, services, got, want)
+		}
+	}
+}
+
+const composeFile = `{"compose":"services:\n  web:\n    image: nginx:1.27\n    ports:\n      - \"8081:80\"\n  cache:\n    image: redis:7\n"}`
+
+func dockerCases(t *testing.T, prefix string) []routeCase {
+	upload, uploadType := multipartUpload(t, "/etc/nginx", map[string]string{"default.conf": "server { listen 80; }\n"})
+	p := "/api/v1" + prefix
+
+	return []routeCase{
+		{method: "GET", path: p + "/containers", want: 200},
+		{method: "GET", path: p + "/containers/web/logs?lines=2", want: 200},
+		{method: "GET", path: p + "/containers/web/logs?format=structured&stream=stderr&level=error&since=1h", want: 200},
+		{method: "GET", path: p + "/containers/web/logs?q=upstream&regex=timed%5Cs+out", want: 200},
+		{method: "GET", path: p + "/containers/web/logs?format=xml", want: 400},
+		{method: "GET", path: p + "/containers/web/logs?regex=%28", want: 400},
+		{method: "GET", path: p + "/containers/web/logs?level=loud", want: 400},
+		{method: "GET", path: p + "/containers/web/logs?since=yesterday", want: 400},
+		{method: "GET", path: p + "/containers/web/logs?format=structured&query=level%3Derror+AND+NOT+stream%3Dstdout", want: 200},
+		{method: "GET", path: p + "/containers/web/logs?query=level%3D", want: 400},
+		{method: "GET", path: p + "/containers/web/stats", want: 200},
+		{method: "GET", path: p + "/containers/web/top", want: 200},
+		{method: "GET", path: p + "/containers/web/top?ps_args=-ef%3Breboot", want: 400},
+		{method: "POST", path: p + "/containers/worker/start", want: 200, check: containerState("worker", "running")},
+		{method: "POST", path: p + "/containers/worker/stop", want: 200, check: containerState("worker", "exited")},
+		{method: "POST", path: p + "/containers/worker/restart", want: 200, check: containerState("worker", "running")},
+		{method: "POST", path: p + "/containers/worker/action", body: `{"action":"stop"}`, want: 200, check: containerState("worker", "exited")},
+		{method: "POST", path: p + "/containers/worker/action", body: `{"action":"explode"}`, want: 400},
+		{method: "PATCH", path: p + "/containers/web/resources", body: `{"memory":268435456}`, want: 200, check: func(t *testing.T, fake *dockertest.Fake) {
+			if c, _ := fake.Container("web"); c.Resources.Memory != 268435456 {
+				t.Errorf("web memory limit is %d after the update, want 268435456", c.Resources.Memory)
+			}
+		}},
+		{method: "GET", path: p + "/containers/web/files?path=/etc", want: 200},
+		{method: "GET", path: p + "/containers/web/files?path=/etc/nginx/nginx.conf", want: 400},
+		{method: "GET", path: p + "/containers/web/files/download?path=/etc/nginx/nginx.conf", want: 200},
+		{method: "POST", path: p + "/containers/web/files/upload", body: upload, contentType: uploadType, want: 200, check: func(t *testing.T, fake *dockertest.Fake) {
+			if content, ok := fake.File("web", "/etc/nginx/default.conf"); content != "server { listen 80; }\n" {
+				t.Errorf("uploaded file = %q (exists %v)", content, ok)
+			}
+		}},
+		{method: "GET", path: p + "/containers/web/changes", want: 200},
+		{method: "DELETE", path: p + "/containers/worker", want: 200, check: func(t *testing.T, fake *dockertest.Fake) {
+			if _, ok := fake.Container("worker"); ok {
+				t.Error("worker container still exists after DELETE")
+			}
+		}},
+
+		{method: "GET", path: p + "/images", want: 200},
+		{method: "POST", path: p + "/images/pull", body: `{"imageName":"alpine:3.20"}`, want: 200},
+		{method: "DELETE", path: p + "/images/alpine:3.20", want: 200},
+		{method: "POST", path: p + "/images/prune", want: 200},
+
+		{method: "GET", path: p + "/volumes", want: 200},
+		{method: "POST", path: p + "/volumes", body: `{"name":"uploads"}`, want: 201},
+		{method: "GET", path: p + "/volumes/data", want: 200},
+		{method: "DELETE", path: p + "/volumes/uploads", want: 200},
+		{method: "POST", path: p + "/volumes/prune", want: 200},
+		{method: "POST", path: p + "/volumes/data/backups", want: 201, save: "backup"},
+		{method: "GET", path: p + "/volumes/data/backups", want: 200},
+		{method: "GET", path: p + "/volumes/data/export", want: 200},
+
+		{method: "GET", path: p + "/backups", want: 200},
+		{method: "GET", path: p + "/backups/{backup}", want: 200},
+		{method: "GET", path: p + "/backups/{backup}/download", want: 200},
+		{method: "POST", path: p + "/backups/{backup}/restore", body: `{"targetVolume":"restored"}`, want: 200, check: func(t *testing.T, fake *dockertest.Fake) {
+			restored, ok := fake.VolumeData("restored")
+			original, _ := fake.VolumeData("data")
+			if !ok || !bytes.Equal(restored, original) {
+				t.Errorf("restored volume holds %d bytes (exists %v), want the %d bytes backed up from data", len(restored), ok, len(original))
+			}
+		}},
+		{method: "DELETE", path: p + "/backups/{backup}", want: 200},
+		{method: "GET", path: p + "/backups/{backup}", want: 404},
+
+		{method: "GET", path: p + "/networks", want: 200},
+		{method: "POST", path: p + "/networks", body: `{"name":"backend"}`, want: 201},
+		{method: "GET", path: p + "/networks/backend", want: 200},
+		{method: "POST", path: p + "/networks/backend/connect", body: `{"container":"web"}`, want: 200},
+		{method: "POST", path: p + "/networks/backend/disconnect", body: `{"container":"web"}`, want: 200},
+		{method: "DELETE", path: p + "/networks/backend", want: 200},
+		{method: "POST", path: p + "/networks/prune", want: 200},
+		{method: "GET", path: p + "/topology", want: 200},
+
+		{method: "POST", path: p + "/stacks/shop/plan", body: composeFile, want: 200},
+		{method: "POST", path: p + "/stacks/shop/deploy", body: composeFile, want: 200, check: stackStates("shop", "running", "running")},
+		{method: "GET", path: p + "/stacks", want: 200},
+		{method: "GET", path: p + "/stacks/shop", want: 200},
+		{method: "POST", path: p + "/stacks/shop/stop", want: 200, check: stackStates("shop", "exited", "exited")},
+		{method: "POST", path: p + "/stacks/shop/start", want: 200, check: stackStates("shop", "running", "running")},
+		{method: "POST", path: p + "/stacks/shop/restart", want: 200, check: stackStates("shop", "running", "running")},
+		{method: "POST", path: p + "/stacks/shop/services/web/stop", want: 200, check: stackStates("shop", "running", "exited")},
+		{method: "POST", path: p + "/stacks/shop/restart", want: 200, check: stackStates("shop", "running", "running")},
+		{method: "POST", path: p + "/stacks/shop/services/web/stop", want: 200, check: stackStates("shop", "running", "exited")},
+		{method: "POST", path: p + "/stacks/shop/services/web/start", want: 200, check: stackStates("shop", "running", "running")},
+		{method: "POST", path: p + "/stacks/shop/services/web/restart", want: 200, check: stackStates("shop", "running", "running")},
+		{method: "GET", path: p + "/stacks/shop/revisions", want: 200},
+		{method: "GET", path: p + "/stacks/shop/revisions/1", want: 200},
+		{method: "POST", path: p + "/stacks/shop/revisions/1/rollback", want: 200, check: stackStates("shop", "running", "running")},
+		{method: "DELETE", path: p + "/stacks/shop/services/cache?force=true", want: 200, check: stackStates("shop", "running")},
+		{method: "DELETE", path: p + "/stacks/shop?force=true", want: 200, check: stackStates("shop")},
+		{method: "GET", path: p + "/stacks/shop", want: 404},
+
+		{method: "GET", path: p + "/metrics", want: 200},
+		{method: "GET", path: p + "/metrics/{web}", want: 200},
+		{method: "GET", path: p + "/metrics/historical", want: 200},
+	}
+}
+
+func apiCases() []routeCase {
+	return []routeCase{
+		{method: "GET", path: "/health", want: 200},
+		{method: "GET", path: "/api/v1/auth/me", want: 200},
+		{method: "GET", path: "/api/v1/auth/me/permissions", want: 200},
+		{method: "GET", path: "/api/v1/auth/users", want: 200},
+		{method: "POST", path: "/api/v1/auth/users", body: `{"username":"alice","password":"alice-password-1"}`, want: 201, save: "user"},
+		{method: "POST", path: "/api/v1/auth/users", body: `{"username":"alice","password":"alice-password-1"}`, want: 409},
+		{method: "GET", path: "/api/v1/auth/tokens", want: 200},
+		{method: "POST", path: "/api/v1/auth/tokens", body: `{"name":"ci","expiresInDays":7}`, want: 201, save: "token", field: "details.id"},
+		{method: "DELETE", path: "/api/v1/auth/tokens/{token}", want: 200},
+		{method: "GET", path: "/api/v1/auth/permissions", want: 200},
+		{method: "GET", path: "/api/v1/auth/roles", want: 200},
+		{method: "POST", path: "/api/v1/auth/roles", body: `{"name":"auditor","description":"Reads logs","permissions":["logs:read"]}`, want: 201},
+		{method: "PUT", path: "/api/v1/auth/roles/auditor", body: `{"description":"Reads logs and metrics","permissions":["logs:read","metrics:read"]}`, want: 200},
+		{method: "POST", path: "/api/v1/auth/users/{user}/roles", body: `{"role":"auditor"}`, want: 201, save: "binding"},
+		{method: "GET", path: "/api/v1/auth/users/{user}/roles", want: 200},
+		{method: "DELETE", path: "/api/v1/auth/users/{user}/roles/{binding}", want: 200},
+		{method: "DELETE", path: "/api/v1/auth/roles/auditor", want: 200},
+		{method: "DELETE", path: "/api/v1/auth/users/{user}", want: 200},
+		{method: "PUT", path: "/api/v1/auth/password", body: fmt.Sprintf(`{"currentPassword":%q,"newPassword":"rotated-password-2"}`, adminPassword), want: 200},
+
+		{method: "GET", path: "/api/v1/hosts?status=false", want: 200},
+		{method: "POST", path: "/api/v1/hosts", body: `{"name":"edge","endpoint":"tcp://127.0.0.1:1"}`, want: 201},
+		{method: "POST", path: "/api/v1/hosts", body: `{"name":"edge","endpoint":"tcp://127.0.0.1:1"}`, want: 409},
+		{method: "GET", path: "/api/v1/hosts/edge", want: 200},
+		{method: "PUT", path: "/api/v1/hosts/edge", body: `{"endpoint":"tcp://127.0.0.1:1","labels":{"site":"lab"}}`, want: 200},
+		{method: "GET", path: "/api/v1/hosts/all/containers", want: 200},
+		{method: "GET", path: "/api/v1/hosts/all/images", want: 200},
+		{method: "GET", path: "/api/v1/hosts/all/volumes", want: 200},
+		{method: "GET", path: "/api/v1/hosts/all/networks", want: 200},
+		{method: "DELETE", path: "/api/v1/hosts/edge", want: 200},
+		{method: "GET", path: "/api/v1/hosts/edge", want: 404},
+		{method: "DELETE", path: "/api/v1/hosts/local", want: 400},
+
+		{method: "GET", path: "/api/v1/ssh-keys", want: 200},
+		{method: "POST", path: "/api/v1/ssh-keys", body: `{"name":"deploy"}`, want: 201},
+		{method: "POST", path: "/api/v1/ssh-keys", body: `{"name":"broken","privateKey":"not a key"}`, want: 400},
+		{method: "POST", path: "/api/v1/hosts", body: `{"name":"bastion","endpoint":"ssh://deploy@127.0.0.1:1","sshKey":"deploy"}`, want: 400},
+		{method: "POST", path: "/api/v1/hosts", body: `{"name":"bastion","endpoint":"ssh://deploy@127.0.0.1:1","sshKey":"deploy","sshKnownHosts":"[127.0.0.1]:1 ` + sshHostKey + `"}`, want: 201},
+		{method: "DELETE", path: "/api/v1/ssh-keys/deploy", want: 409},
+		{method: "DELETE", path: "/api/v1/hosts/bastion", want: 200},
+		{method: "DELETE", path: "/api/v1/ssh-keys/deploy", want: 200},
+
+		{method: "GET", path: "/api/v1/audit/health", want: 200},
+		{method: "GET", path: "/api/v1/audit/verify", want: 200},
+		{method: "GET", path: "/api/v1/audit/checkpoints", want: 200},
+		{method: "GET", path: "/api/v1/logs?limit=10", want: 200},
+		{method: "GET", path: "/api/v1/logs/export?format=ndjson", want: 200},
+		{method: "GET", path: "/api/v1/logs/web", want: 200},
+
+		{method: "POST", path: "/api/v1/auth/logout", want: 200},
+		{method: "GET", path: "/api/v1/auth/me", want: 401},
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	covered := map[string]bool{}
+	var routes gin.RoutesInfo
+
+	for _, prefix := range []string{"", "/hosts/local"} {
+		t.Run("docker"+prefix, func(t *testing.T) {
+			srv := newTestServer(t)
+			routes = srv.router.Routes()
+			client := srv.login(t)
+			srv.run(t, client, dockerCases(t, prefix), covered)
+
+			if len(srv.fake.Events()) == 0 {
+				t.Error("fake daemon recorded no events")
+			}
+
+			srv.fake.Fail("ListContainers", errors.New("daemon unavailable"))
+			srv.run(t, client, []routeCase{
+				{method: "GET", path: "/api/v1" + prefix + "/images", want: 200},
+				{method: "GET", path: "/api/v1" + prefix + "/containers", want: 500},
+			}, covered)
+		})
+	}
+
+	t.Run("api", func(t *testing.T) {
+		srv := newTestServer(t)
+		srv.run(t, http.DefaultClient, []routeCase{
+			{method: "GET", path: "/api/v1/containers", want: 401},
+			{method: "POST", path: "/api/v1/auth/login", body: `{"username":"admin","password":"wrong"}`, want: 401},
+			{method: "GET", path: "/api/v1/openapi.json", want: 200},
+		}, covered)
+		srv.run(t, srv.login(t), apiCases(), covered)
+	})
+
+	for _, route := range routes {
+		if !covered[route.Method+" "+route.Path] {
+			t.Errorf("route %s %s has no test case", route.Method, route.Path)
+		}
+	}
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+	srv.fake.Fail("RemoveImage", errdefs.Conflict(errors.New("image is being used by running container")))
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"POST", "/api/v1/containers/missing/start", "", http.StatusNotFound, "not_found"},
+		{"DELETE", "/api/v1/images/nginx:1.27", "", http.StatusConflict, "conflict"},
+		{"POST", "/api/v1/containers/web/action", `{"action":"explode"}`, http.StatusBadRequest, "invalid_argument"},
+		{"POST", "/api/v1/auth/users", `{"username":"admin","password":"another-password-1"}`, http.StatusConflict, "conflict"},
+		{"GET", "/api/v1/hosts/nowhere", "", http.StatusNotFound, "not_found"},
+		{"GET", "/api/v1/does-not-exist", "", http.StatusNotFound, "not_found"},
+	}
+
+	for _, tc := range cases {
+		req, _ := http.NewRequest(tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+		if tc.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tc.method, tc.path, err)
+		}
+
+		var envelope models.ErrorResponse
+		err = json.NewDecoder(resp.Body).Decode(&envelope)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s %s: decode envelope: %v", tc.method, tc.path, err)
+		}
+
+		if resp.StatusCode != tc.status || envelope.Error.Code != tc.code {
+			t.Errorf("%s %s: got %d %q, want %d %q", tc.method, tc.path, resp.StatusCode, envelope.Error.Code, tc.status, tc.code)
+		}
+		if envelope.Error.Message == "" {
+			t.Errorf("%s %s: empty error message", tc.method, tc.path)
+		}
+		if id := resp.Header.Get("X-Request-ID"); id == "" || envelope.Error.RequestID != id {
+			t.Errorf("%s %s: requestId %q does not match header %q", tc.method, tc.path, envelope.Error.RequestID, id)
+		}
+	}
+}
+
+func TestContainerLogFilters(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+
+	now := time.Now().UTC()
+	srv.fake.AddContainer(dockertest.Container{
+		Name:  "payments",
+		Image: "busybox:latest",
+		Logs: []models.LogEntry{
+			{Timestamp: now.Add(-3 * time.Second), Message: `{"level":"info","msg":"charge accepted","service":"payments","http":{"status":200}}`, Stream: "stdout"},
+			{Timestamp: now.Add(-2 * time.Second), Message: `{"severity":"ERROR","message":"charge failed","service":"payments","traceId":"abc123","http":{"status":502}}`, Stream: "stdout"},
+			{Timestamp: now.Add(-time.Second), Message: `time=2024-05-01T10:00:00Z level=error msg="ledger unavailable" service=ledger trace_id=def456`, Stream: "stderr"},
+			{Timestamp: now, Message: `{"level":50,"time":1714557600000,"msg":"refund failed","service":"payments"}`, Stream: "stdout"},
+		},
+	})
+
+	cases := []struct {
+		container string
+		query     string
+		messages  []string
+	}{
+		{"web", "format=structured", []string{"[notice] 1#1: start worker processes", "GET / 200", "[error] 29#29: upstream timed out"}},
+		{"web", "format=structured&stream=stdout&lines=1", []string{"GET / 200"}},
+		{"web", "format=structured&level=info,error", []string{"[notice] 1#1: start worker processes", "[error] 29#29: upstream timed out"}},
+		{"web", "format=structured&q=UPSTREAM", []string{"[error] 29#29: upstream timed out"}},
+		{"web", "format=structured&regex=%5EGET", []string{"GET / 200"}},
+		{"payments", "format=structured&query=level%3Derror+AND+service%3Dpayments", []string{"charge failed", "refund failed"}},
+		{"payments", "format=structured&query=trace_id%3Ddef456+OR+http.status%3E%3D500", []string{"charge failed", "ledger unavailable"}},
+		{"payments", "format=structured&query=NOT+%28level%3Derror%29", []string{"charge accepted"}},
+		{"payments", "format=structured&query=msg~%22REFUND%22", []string{"refund failed"}},
+	}
+
+	for _, tc := range cases {
+		resp, err := client.Get(srv.URL + "/api/v1/containers/" + tc.container + "/logs?" + tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var logs models.ContainerLogs
+		err = json.NewDecoder(resp.Body).Decode(&logs)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.query, err)
+		}
+
+		var messages []string
+		for _, entry := range logs.Entries {
+			if msg, ok := entry.Fields["msg"]; ok {
+				messages = append(messages, msg)
+			} else {
+				messages = append(messages, entry.Message)
+			}
+		}
+		if strings.Join(messages, "\n") != strings.Join(tc.messages, "\n") {
+			t.Errorf("%s: got %q, want %q", tc.query, messages, tc.messages)
+		}
+	}
+}
+
+func TestComposeSizeLimit(t *testing.T) {
+	srv := newTestServer(t)
+	client := srv.login(t)
+
+	raw := "services:\n  web:\n    image: nginx:1.27\n# " + strings.Repeat("x", 2<<20) + "\n"
+	body, _ := json.Marshal(models.DeployStackRequest{Compose: raw})
+	srv.run(t, client, []routeCase{
+		{method: "POST", path: "/api/v1/stacks/shop/plan", body: string(body), want: http.StatusRequestEntityTooLarge},
+		{method: "POST", path: "/api/v1/stacks/shop/plan", body: raw, contentType: "application/yaml", want: http.StatusRequestEntityTooLarge},
+	}, map[string]bool{})
+}
@@ -0,0 +1,133 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	CodeInvalidArgument = "invalid_argument"
+	CodeUnauthenticated = "unauthenticated"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeTooLarge        = "payload_too_large"
+	CodeInternal        = "internal"
+	CodeNotImplemented  = "not_implemented"
+	CodeUpstream        = "upstream_error"
+	CodeUnavailable     = "unavailable"
+	CodeTimeout         = "timeout"
+
+	requestIDHeader = "X-Request-ID"
+)
+
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details any
+	cause   error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+func (e *Error) WithDetails(details any) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+func New(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func Wrap(err error, status int, code string) *Error {
+	return &Error{Status: status, Code: code, Message: err.Error(), cause: err}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, CodeInvalidArgument, message)
+}
+
+func Unauthenticated(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthenticated, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+func Unavailable(message string) *Error {
+	return New(http.StatusServiceUnavailable, CodeUnavailable, message)
+}
+
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &tooLarge):
+		return New(http.StatusRequestEntityTooLarge, CodeTooLarge, fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit))
+	case errdefs.IsNotFound(err), errors.Is(err, database.ErrNotFound):
+		return Wrap(err, http.StatusNotFound, CodeNotFound)
+	case errdefs.IsConflict(err):
+		return Wrap(err, http.StatusConflict, CodeConflict)
+	case errdefs.IsInvalidParameter(err):
+		return Wrap(err, http.StatusBadRequest, CodeInvalidArgument)
+	case errdefs.IsUnauthorized(err):
+		return Wrap(err, http.StatusUnauthorized, CodeUnauthenticated)
+	case errdefs.IsForbidden(err):
+		return Wrap(err, http.StatusForbidden, CodeForbidden)
+	case errdefs.IsNotImplemented(err):
+		return Wrap(err, http.StatusNotImplemented, CodeNotImplemented)
+	case errdefs.IsUnavailable(err), errors.Is(err, database.ErrUnavailable), errors.Is(err, database.ErrQueueFull):
+		return Wrap(err, http.StatusServiceUnavailable, CodeUnavailable)
+	case errdefs.IsDeadline(err), errors.Is(err, context.DeadlineExceeded):
+		return Wrap(err, http.StatusGatewayTimeout, CodeTimeout)
+	default:
+		return Wrap(err, http.StatusInternalServerError, CodeInternal)
+	}
+}
+
+func Response(c *gin.Context, err error) (int, models.ErrorResponse) {
+	apiErr := From(err)
+	return apiErr.Status, models.ErrorResponse{Error: models.APIError{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Details:   apiErr.Details,
+		RequestID: c.Writer.Header().Get(requestIDHeader),
+	}}
+}
+
+func Respond(c *gin.Context, err error) {
+	c.JSON(Response(c, err))
+}
+
+func Abort(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(Response(c, err))
+}
@@ -0,0 +1,67 @@
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"docker-gui-backend/internal/auth"
+	"docker-gui-backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+	Anonymous       = "anonymous"
+
+	requestIDKey = "audit.request_id"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+func RequestIDFrom(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+func Actor(c *gin.Context) database.Actor {
+	return ActorFor(c, auth.IdentityFrom(c))
+}
+
+func ActorFor(c *gin.Context, identity *auth.Identity) database.Actor {
+	actor := database.Actor{
+		User:       Anonymous,
+		ClientIP:   c.ClientIP(),
+		RemoteAddr: c.RemoteIP(),
+		UserAgent:  c.Request.UserAgent(),
+		RequestID:  RequestIDFrom(c),
+	}
+
+	if identity != nil {
+		actor.User = identity.Username
+		actor.TokenName = identity.TokenName
+	}
+
+	return actor
+}
+
+func newRequestID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
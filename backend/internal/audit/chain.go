@@ -0,0 +1,239 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/keyfile"
+)
+
+const (
+	defaultCheckpointInterval = time.Hour
+	defaultCheckpointKeyFile  = "docker-gui-audit.key"
+)
+
+type BrokenLink struct {
+	LogID        int    `json:"logId"`
+	CheckpointID int    `json:"checkpointId,omitempty"`
+	Reason       string `json:"reason"`
+	Expected     string `json:"expected,omitempty"`
+	Actual       string `json:"actual,omitempty"`
+}
+
+type Verification struct {
+	Valid               bool        `json:"valid"`
+	Checked             int         `json:"checked"`
+	HeadID              int         `json:"headId,omitempty"`
+	HeadHash            string      `json:"headHash,omitempty"`
+	Checkpoints         int         `json:"checkpoints"`
+	VerifiedCheckpoints int         `json:"verifiedCheckpoints"`
+	LastCheckpointID    int         `json:"lastCheckpointId,omitempty"`
+	FirstBroken         *BrokenLink `json:"firstBroken,omitempty"`
+	VerifiedAt          time.Time   `json:"verifiedAt"`
+}
+
+type Chain struct {
+	store    database.Store
+	key      []byte
+	interval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+}
+
+func NewChain(store database.Store) (*Chain, error) {
+	key, _, err := keyfile.Load(keyfile.Source{
+		Name:        "audit checkpoint key",
+		Env:         "AUDIT_CHECKPOINT_KEY",
+		FileEnv:     "AUDIT_CHECKPOINT_KEY_FILE",
+		DefaultFile: defaultCheckpointKeyFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	interval := defaultCheckpointInterval
+	if v := os.Getenv("AUDIT_CHECKPOINT_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid AUDIT_CHECKPOINT_INTERVAL %q", v)
+		}
+		interval = d
+	}
+
+	return &Chain{
+		store:    store,
+		key:      []byte(key),
+		interval: interval,
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+func (ch *Chain) Start() {
+	ch.wg.Add(1)
+	go func() {
+		defer ch.wg.Done()
+
+		ticker := time.NewTicker(ch.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := ch.Checkpoint(); err != nil {
+				log.Println("Failed to write audit checkpoint:", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-ch.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (ch *Chain) Close() {
+	close(ch.stop)
+	ch.wg.Wait()
+}
+
+func (ch *Chain) Checkpoint() (*database.AuditCheckpoint, error) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	head, err := ch.store.LatestLogLink()
+	if err != nil || head == nil {
+		return nil, err
+	}
+
+	checkpoints, err := ch.store.ListAuditCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+	if n := len(checkpoints); n > 0 && checkpoints[n-1].LogID == head.ID {
+		return &checkpoints[n-1], nil
+	}
+
+	return ch.store.CreateAuditCheckpoint(head.ID, head.Hash, ch.sign(head.ID, head.Hash))
+}
+
+func (ch *Chain) Checkpoints() ([]database.AuditCheckpoint, error) {
+	return ch.store.ListAuditCheckpoints()
+}
+
+func (ch *Chain) sign(logID int, hash string) string {
+	mac := hmac.New(sha256.New, ch.key)
+	fmt.Fprintf(mac, "%d:%s", logID, hash)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (ch *Chain) Verify() (*Verification, error) {
+	checkpoints, err := ch.store.ListAuditCheckpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Verification{Checkpoints: len(checkpoints), VerifiedAt: time.Now().UTC()}
+	pending := make(map[int][]database.AuditCheckpoint)
+	for _, checkpoint := range checkpoints {
+		if !hmac.Equal([]byte(checkpoint.Signature), []byte(ch.sign(checkpoint.LogID, checkpoint.Hash))) {
+			result.FirstBroken = &BrokenLink{
+				LogID:        checkpoint.LogID,
+				CheckpointID: checkpoint.ID,
+				Reason:       "checkpoint signature is invalid",
+			}
+			return result, nil
+		}
+		pending[checkpoint.LogID] = append(pending[checkpoint.LogID], checkpoint)
+	}
+
+	prev, version := "", database.HashVersionLegacy
+	after := 0
+	for {
+		links, err := ch.store.ScanLogChain(after, exportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, link := range links {
+			if broken := verifyLink(prev, version, link); broken != nil {
+				result.FirstBroken = broken
+				return result, nil
+			}
+			for _, checkpoint := range pending[link.ID] {
+				if checkpoint.Hash != link.Hash {
+					result.FirstBroken = &BrokenLink{
+						LogID:        link.ID,
+						CheckpointID: checkpoint.ID,
+						Reason:       "entry does not match signed checkpoint",
+						Expected:     checkpoint.Hash,
+						Actual:       link.Hash,
+					}
+					return result, nil
+				}
+				result.VerifiedCheckpoints++
+				result.LastCheckpointID = checkpoint.ID
+			}
+			delete(pending, link.ID)
+
+			prev, version = link.Hash, link.HashVersion
+			result.Checked++
+			result.HeadID = link.ID
+			result.HeadHash = link.Hash
+		}
+
+		if len(links) < exportBatchSize {
+			break
+		}
+		after = links[len(links)-1].ID
+	}
+
+	for _, checkpoint := range checkpoints {
+		if _, missing := pending[checkpoint.LogID]; missing {
+			result.FirstBroken = &BrokenLink{
+				LogID:        checkpoint.LogID,
+				CheckpointID: checkpoint.ID,
+				Reason:       "entry covered by signed checkpoint is missing",
+				Expected:     checkpoint.Hash,
+			}
+			return result, nil
+		}
+	}
+
+	result.Valid = true
+	return result, nil
+}
+
+func verifyLink(prev string, version int, link database.ChainLink) *BrokenLink {
+	if link.PrevHash != prev {
+		return &BrokenLink{
+			LogID:    link.ID,
+			Reason:   "previous entry is missing or was modified",
+			Expected: prev,
+			Actual:   link.PrevHash,
+		}
+	}
+	if link.HashVersion < version {
+		return &BrokenLink{
+			LogID:    link.ID,
+			Reason:   "entry uses an older hash format than the entry before it",
+			Expected: fmt.Sprintf("v%d", version),
+			Actual:   fmt.Sprintf("v%d", link.HashVersion),
+		}
+	}
+	if hash := database.LogHash(link.HashVersion, link.PrevHash, link.ContainerLog); hash != link.Hash {
+		return &BrokenLink{
+			LogID:    link.ID,
+			Reason:   "entry content was modified",
+			Expected: link.Hash,
+			Actual:   hash,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package audit
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"docker-gui-backend/internal/database"
+)
+
+func newChainStore(t *testing.T, key string) (*Chain, *database.DB, *sql.DB) {
+	t.Helper()
+	t.Setenv("AUDIT_CHECKPOINT_KEY", key)
+
+	path := filepath.Join(t.TempDir(), "docker-gui.db")
+	store, err := database.NewSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	raw, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { raw.Close() })
+
+	chain, err := NewChain(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return chain, store, raw
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func rehashFrom(t *testing.T, store *database.DB, raw *sql.DB, id int) {
+	t.Helper()
+	links, err := store.ScanLogChain(0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := ""
+	for _, link := range links {
+		if link.ID >= id {
+			link.PrevHash, link.Hash = prev, database.LogHash(link.HashVersion, prev, link.ContainerLog)
+			mustExec(t, raw, `UPDATE container_logs SET prev_hash = ?, hash = ? WHERE id = ?`, link.PrevHash, link.Hash, link.ID)
+		}
+		prev = link.Hash
+	}
+}
+
+func TestChainVerify(t *testing.T) {
+	const columns = `container_id, container_name, action, timestamp, user_info, token_name, client_ip, remote_addr, user_agent, request_id, details, prev_hash, hash, hash_version`
+
+	cases := []struct {
+		name       string
+		tamper     func(t *testing.T, store *database.DB, raw *sql.DB)
+		verifyKey  string
+		broken     int
+		checkpoint bool
+		reason     string
+	}{
+		{name: "intact"},
+		{
+			name: "edited entry",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `UPDATE container_logs SET details = 'nothing happened' WHERE id = 2`)
+			},
+			broken: 2,
+			reason: "entry content was modified",
+		},
+		{
+			name: "edited entry with its own hash recomputed",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `UPDATE container_logs SET user_info = 'mallory' WHERE id = 2`)
+				links, _ := store.ScanLogChain(1, 1)
+				mustExec(t, raw, `UPDATE container_logs SET hash = ? WHERE id = 2`, database.LogHash(links[0].HashVersion, links[0].PrevHash, links[0].ContainerLog))
+			},
+			broken: 3,
+			reason: "previous entry is missing or was modified",
+		},
+		{
+			name: "edited entry with the rest of the chain rewritten",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `UPDATE container_logs SET action = 'start' WHERE id = 2`)
+				rehashFrom(t, store, raw, 2)
+			},
+			broken:     3,
+			checkpoint: true,
+			reason:     "entry does not match signed checkpoint",
+		},
+		{
+			name: "deleted entry",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `DELETE FROM container_logs WHERE id = 4`)
+			},
+			broken: 5,
+			reason: "previous entry is missing or was modified",
+		},
+		{
+			name: "truncated tail",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `DELETE FROM container_logs WHERE id >= 3`)
+			},
+			broken:     3,
+			checkpoint: true,
+			reason:     "entry covered by signed checkpoint is missing",
+		},
+		{
+			name: "reordered entries",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `UPDATE container_logs SET id = 100 WHERE id = 2`)
+				mustExec(t, raw, `UPDATE container_logs SET id = 2 WHERE id = 3`)
+				mustExec(t, raw, `UPDATE container_logs SET id = 3 WHERE id = 100`)
+			},
+			broken: 2,
+			reason: "previous entry is missing or was modified",
+		},
+		{
+			name: "reordered entries with the chain rewritten",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `INSERT INTO container_logs (`+columns+`) SELECT `+columns+` FROM container_logs WHERE id = 2`)
+				mustExec(t, raw, `DELETE FROM container_logs WHERE id = 2`)
+				rehashFrom(t, store, raw, 3)
+			},
+			broken:     3,
+			checkpoint: true,
+			reason:     "entry does not match signed checkpoint",
+		},
+		{
+			name: "forged checkpoint",
+			tamper: func(t *testing.T, store *database.DB, raw *sql.DB) {
+				mustExec(t, raw, `UPDATE audit_checkpoints SET hash = (SELECT hash FROM container_logs WHERE id = 2), log_id = 2`)
+			},
+			broken:     2,
+			checkpoint: true,
+			reason:     "checkpoint signature is invalid",
+		},
+		{
+			name:       "checkpoint signed with another key",
+			verifyKey:  "someone-elses-key",
+			broken:     3,
+			checkpoint: true,
+			reason:     "checkpoint signature is invalid",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain, store, raw := newChainStore(t, "chain-test-key")
+			for i := 1; i <= 5; i++ {
+				if err := store.LogContainerAction("c1", "web", "stop", database.Actor{User: "alice"}, fmt.Sprintf("entry %d", i)); err != nil {
+					t.Fatal(err)
+				}
+				if i == 3 {
+					if _, err := chain.Checkpoint(); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			if tc.tamper != nil {
+				tc.tamper(t, store, raw)
+			}
+			if tc.verifyKey != "" {
+				chain.key = []byte(tc.verifyKey)
+			}
+
+			result, err := chain.Verify()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Checkpoints != 1 {
+				t.Errorf("%d checkpoints, want 1", result.Checkpoints)
+			}
+
+			if tc.broken == 0 {
+				if !result.Valid || result.FirstBroken != nil || result.Checked != 5 || result.HeadID != 5 || result.VerifiedCheckpoints != 1 {
+					t.Errorf("verification = %+v, want a valid chain of 5 entries with 1 verified checkpoint", result)
+				}
+				return
+			}
+
+			broken := result.FirstBroken
+			if result.Valid || broken == nil {
+				t.Fatalf("verification = %+v, want a broken chain", result)
+			}
+			if broken.LogID != tc.broken || broken.Reason != tc.reason || (broken.CheckpointID != 0) != tc.checkpoint {
+				t.Errorf("first broken link = %+v, want entry %d (%s), checkpoint reported %v", broken, tc.broken, tc.reason, tc.checkpoint)
+			}
+		})
+	}
+}
+
+func TestChainVerifyHashVersions(t *testing.T) {
+	chain, store, raw := newChainStore(t, "chain-test-key")
+	for i := 1; i <= 4; i++ {
+		actor := database.Actor{User: "alice"}
+		if i%2 == 0 {
+			actor.RemoteAddr = "10.0.0.9:51000"
+		}
+		if err := store.LogContainerAction("c1", "web", "stop", actor, fmt.Sprintf("entry %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links, err := store.ScanLogChain(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range links {
+		if link.HashVersion != database.HashVersion {
+			t.Fatalf("entry %d written with hash format v%d, want v%d", link.ID, link.HashVersion, database.HashVersion)
+		}
+	}
+	entry := links[0].ContainerLog
+	withAddr := entry
+	withAddr.RemoteAddr = "10.0.0.9:51000"
+	if database.LogHash(database.HashVersion, "", entry) == database.LogHash(database.HashVersion, "", withAddr) {
+		t.Error("remote address is not part of the hash")
+	}
+	if database.LogHash(database.HashVersionLegacy, "", entry) == database.LogHash(database.HashVersion, "", entry) {
+		t.Error("legacy and current hash formats agree")
+	}
+	if database.LogHash(99, "", entry) != "" {
+		t.Error("unknown hash format produced a hash")
+	}
+
+	mustExec(t, raw, `UPDATE container_logs SET hash_version = 1 WHERE id <= 2`)
+	rehashFrom(t, store, raw, 1)
+	result, err := chain.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || result.Checked != 4 {
+		t.Fatalf("legacy entries followed by current ones: %+v, want a valid chain of 4", result)
+	}
+
+	mustExec(t, raw, `UPDATE container_logs SET hash_version = 1 WHERE id = 4`)
+	rehashFrom(t, store, raw, 4)
+	result, err = chain.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.FirstBroken == nil || result.FirstBroken.LogID != 4 || result.FirstBroken.Reason != "entry uses an older hash format than the entry before it" {
+		t.Errorf("downgraded entry: %+v, want entry 4 reported as downgraded", result.FirstBroken)
+	}
+}
@@ -0,0 +1,221 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"docker-gui-backend/internal/database"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatSyslog = "syslog"
+
+	exportBatchSize = 500
+
+	syslogAppName      = "docker-gui"
+	syslogFacility     = 13
+	syslogNotice       = 5
+	syslogWarning      = 4
+	syslogStructuredID = "audit@32473"
+)
+
+var csvHeader = []string{"id", "timestamp", "container_id", "container_name", "action", "user", "token_name", "client_ip", "remote_addr", "user_agent", "request_id", "details"}
+
+type Encoder interface {
+	Encode(entry database.ContainerLog) error
+	Flush() error
+}
+
+func ContentType(format string) (string, bool) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", true
+	case FormatNDJSON:
+		return "application/x-ndjson", true
+	case FormatSyslog:
+		return "text/plain; charset=utf-8", true
+	}
+	return "", false
+}
+
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvEncoder{w: cw}, nil
+	case FormatNDJSON:
+		return &jsonEncoder{enc: json.NewEncoder(w)}, nil
+	case FormatSyslog:
+		return &syslogEncoder{w: w, hostname: hostname()}, nil
+	}
+	return nil, fmt.Errorf("unsupported export format %q (expected %s, %s or %s)", format, FormatCSV, FormatNDJSON, FormatSyslog)
+}
+
+func Export(store database.Store, filter database.LogFilter, enc Encoder, flush func()) (int, error) {
+	after := 0
+	exported := 0
+	for {
+		batch, err := store.ScanLogs(filter, after, exportBatchSize)
+		if err != nil {
+			return exported, err
+		}
+		for _, entry := range batch {
+			if err := enc.Encode(entry); err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		if err := enc.Flush(); err != nil {
+			return exported, err
+		}
+		if flush != nil {
+			flush()
+		}
+		if len(batch) < exportBatchSize {
+			return exported, nil
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvEncoder) Encode(entry database.ContainerLog) error {
+	return e.w.Write([]string{
+		strconv.Itoa(entry.ID),
+		formatTimestamp(entry.Timestamp),
+		entry.ContainerID,
+		entry.ContainerName,
+		entry.Action,
+		entry.UserInfo,
+		entry.TokenName,
+		entry.ClientIP,
+		entry.RemoteAddr,
+		entry.UserAgent,
+		entry.RequestID,
+		entry.Details,
+	})
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonEncoder) Encode(entry database.ContainerLog) error {
+	entry.Timestamp = formatTimestamp(entry.Timestamp)
+	return e.enc.Encode(entry)
+}
+
+func (e *jsonEncoder) Flush() error {
+	return nil
+}
+
+type syslogEncoder struct {
+	w        io.Writer
+	hostname string
+}
+
+func (e *syslogEncoder) Encode(entry database.ContainerLog) error {
+	_, err := io.WriteString(e.w, SyslogMessage(entry, e.hostname)+"\n")
+	return err
+}
+
+func (e *syslogEncoder) Flush() error {
+	return nil
+}
+
+func SyslogMessage(entry database.ContainerLog, hostname string) string {
+	severity := syslogNotice
+	if entry.Action == "access_denied" || strings.HasSuffix(entry.Action, "_failed") {
+		severity = syslogWarning
+	}
+
+	params := [][2]string{
+		{"id", strconv.Itoa(entry.ID)},
+		{"container_id", entry.ContainerID},
+		{"container_name", entry.ContainerName},
+		{"user", entry.UserInfo},
+		{"token_name", entry.TokenName},
+		{"client_ip", entry.ClientIP},
+		{"remote_addr", entry.RemoteAddr},
+		{"user_agent", entry.UserAgent},
+		{"request_id", entry.RequestID},
+	}
+	var sd strings.Builder
+	sd.WriteString("[" + syslogStructuredID)
+	for _, p := range params {
+		if p[1] == "" {
+			continue
+		}
+		sd.WriteString(" " + p[0] + `="` + escapeParam(p[1]) + `"`)
+	}
+	sd.WriteString("]")
+
+	msg := fmt.Sprintf("<%d>1 %s %s %s - %s %s",
+		syslogFacility*8+severity,
+		formatTimestamp(entry.Timestamp),
+		syslogField(hostname, 255),
+		syslogAppName,
+		syslogField(entry.Action, 32),
+		sd.String(),
+	)
+	if details := strings.ReplaceAll(entry.Details, "\n", " "); details != "" {
+		msg += " " + details
+	}
+	return msg
+}
+
+func syslogField(value string, max int) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "-"
+	}
+	if b.Len() > max {
+		return b.String()[:max]
+	}
+	return b.String()
+}
+
+func escapeParam(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "]", `\]`).Replace(value)
+}
+
+func formatTimestamp(value string) string {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return value
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "-"
+	}
+	return name
+}
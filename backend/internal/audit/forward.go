@@ -0,0 +1,271 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"docker-gui-backend/internal/database"
+)
+
+const (
+	defaultForwardInterval = 2 * time.Second
+	forwardDialTimeout     = 5 * time.Second
+	forwardWriteTimeout    = 5 * time.Second
+)
+
+type ForwardStats struct {
+	Address     string     `json:"address"`
+	Connected   bool       `json:"connected"`
+	LastID      int        `json:"lastId"`
+	Forwarded   int64      `json:"forwarded"`
+	Failures    int64      `json:"failures"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+type Forwarder struct {
+	store    database.Store
+	network  string
+	address  string
+	interval time.Duration
+	hostname string
+
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	conn    net.Conn
+	started bool
+	next    int
+	saved   int
+
+	mu          sync.Mutex
+	connected   bool
+	lastID      int
+	forwarded   int64
+	failures    int64
+	lastError   error
+	lastErrorAt time.Time
+}
+
+func NewForwarder(store database.Store) (*Forwarder, error) {
+	target := os.Getenv("AUDIT_FORWARD_ADDR")
+	if target == "" {
+		return nil, nil
+	}
+
+	network, address := "tcp", target
+	if scheme, rest, ok := strings.Cut(target, "://"); ok {
+		network, address = scheme, rest
+	}
+	if network != "tcp" && network != "udp" {
+		return nil, fmt.Errorf("invalid AUDIT_FORWARD_ADDR %q (expected tcp:// or udp://)", target)
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, fmt.Errorf("invalid AUDIT_FORWARD_ADDR %q: %w", target, err)
+	}
+
+	interval := defaultForwardInterval
+	if v := os.Getenv("AUDIT_FORWARD_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid AUDIT_FORWARD_INTERVAL %q", v)
+		}
+		interval = d
+	}
+
+	return &Forwarder{
+		store:    store,
+		network:  network,
+		address:  address,
+		interval: interval,
+		hostname: hostname(),
+		stop:     make(chan struct{}),
+	}, nil
+}
+
+func (f *Forwarder) Start() {
+	if err := f.resume(); err != nil {
+		f.fail(err)
+	} else {
+		f.started = true
+	}
+
+	f.wg.Add(1)
+	go f.run()
+}
+
+func (f *Forwarder) Close() {
+	close(f.stop)
+	f.wg.Wait()
+
+	if f.conn != nil {
+		f.conn.Close()
+		f.conn = nil
+	}
+}
+
+func (f *Forwarder) run() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		f.forward()
+		select {
+		case <-ticker.C:
+		case <-f.stop:
+			f.forward()
+			return
+		}
+	}
+}
+
+func (f *Forwarder) forward() {
+	if !f.started {
+		if err := f.resume(); err != nil {
+			f.fail(err)
+			return
+		}
+		f.started = true
+	}
+	defer f.save()
+
+	if f.conn != nil && f.network == "tcp" && !peerOpen(f.conn) {
+		f.conn.Close()
+		f.conn = nil
+		f.setConnected(false)
+	}
+
+	for {
+		batch, err := f.store.ScanLogs(database.LogFilter{}, f.next, exportBatchSize)
+		if err != nil {
+			f.fail(err)
+			return
+		}
+		for _, entry := range batch {
+			if err := f.send(SyslogMessage(entry, f.hostname)); err != nil {
+				f.fail(err)
+				return
+			}
+			f.next = entry.ID
+			f.mu.Lock()
+			f.lastID = entry.ID
+			f.forwarded++
+			f.mu.Unlock()
+		}
+		if len(batch) < exportBatchSize {
+			return
+		}
+	}
+}
+
+func (f *Forwarder) resume() error {
+	next, err := f.store.GetForwardOffset(f.target())
+	if errors.Is(err, database.ErrNotFound) {
+		page, err := f.store.SearchLogs(database.LogFilter{Limit: 1})
+		if err != nil {
+			return err
+		}
+		if len(page.Logs) > 0 {
+			next = page.Logs[0].ID
+		}
+		if err := f.store.SetForwardOffset(f.target(), next); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	f.next, f.saved = next, next
+	f.mu.Lock()
+	f.lastID = next
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *Forwarder) save() {
+	if f.next == f.saved {
+		return
+	}
+	if err := f.store.SetForwardOffset(f.target(), f.next); err != nil {
+		f.fail(err)
+		return
+	}
+	f.saved = f.next
+}
+
+func (f *Forwarder) target() string {
+	return f.network + "://" + f.address
+}
+
+func (f *Forwarder) send(message string) error {
+	if f.conn == nil {
+		conn, err := net.DialTimeout(f.network, f.address, forwardDialTimeout)
+		if err != nil {
+			return err
+		}
+		f.conn = conn
+		f.setConnected(true)
+	}
+
+	payload := message
+	if f.network == "tcp" {
+		payload += "\n"
+	}
+
+	f.conn.SetWriteDeadline(time.Now().Add(forwardWriteTimeout))
+	if _, err := f.conn.Write([]byte(payload)); err != nil {
+		f.conn.Close()
+		f.conn = nil
+		f.setConnected(false)
+		return err
+	}
+	return nil
+}
+
+func peerOpen(conn net.Conn) bool {
+	conn.SetReadDeadline(time.Now().Add(time.Millisecond))
+	_, err := conn.Read(make([]byte, 1))
+	conn.SetReadDeadline(time.Time{})
+	return errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+func (f *Forwarder) setConnected(connected bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = connected
+}
+
+func (f *Forwarder) fail(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures++
+	f.lastError = err
+	f.lastErrorAt = time.Now()
+	log.Printf("Audit forwarding to %s failed: %v", f.target(), err)
+}
+
+func (f *Forwarder) Stats() ForwardStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := ForwardStats{
+		Address:   f.target(),
+		Connected: f.connected,
+		LastID:    f.lastID,
+		Forwarded: f.forwarded,
+		Failures:  f.failures,
+	}
+	if f.lastError != nil {
+		stats.LastError = f.lastError.Error()
+		at := f.lastErrorAt
+		stats.LastErrorAt = &at
+	}
+	return stats
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"docker-gui-backend/internal/database"
+)
+
+var syslogPattern = regexp.MustCompile(`^<109>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ \S+ docker-gui - (\S+) \[audit@32473 id="(\d+)"( [a-z_]+="(?:[^"\\\]]|\\.)*")*\] (.+)$`)
+
+func newForwardStore(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func logAction(t *testing.T, db *database.DB, action, details string) {
+	t.Helper()
+	if err := db.LogContainerAction("c1", "web", action, database.Actor{User: "alice", ClientIP: "10.0.0.1"}, details); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func startForwarder(t *testing.T, db *database.DB, target string) *Forwarder {
+	t.Helper()
+	t.Setenv("AUDIT_FORWARD_ADDR", target)
+	t.Setenv("AUDIT_FORWARD_INTERVAL", "10ms")
+	f, err := NewForwarder(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Start()
+	return f
+}
+
+func checkSyslog(t *testing.T, message, action, details string) {
+	t.Helper()
+	m := syslogPattern.FindStringSubmatch(message)
+	if m == nil {
+		t.Fatalf("not an RFC 5424 message: %q", message)
+	}
+	if m[1] != action || m[4] != details {
+		t.Fatalf("message %q: msgid %q and text %q, want %q and %q", message, m[1], m[4], action, details)
+	}
+}
+
+func TestForwardTCP(t *testing.T) {
+	db := newForwardStore(t)
+	logAction(t, db, "start", "before forwarding was enabled")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	accept := func() (net.Conn, *bufio.Reader) {
+		t.Helper()
+		listener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		return conn, bufio.NewReader(conn)
+	}
+	readLine := func(r *bufio.Reader) string {
+		t.Helper()
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		return line[:len(line)-1]
+	}
+
+	target := "tcp://" + listener.Addr().String()
+	f := startForwarder(t, db, target)
+	logAction(t, db, "stop", "first")
+	conn, r := accept()
+	checkSyslog(t, readLine(r), "stop", "first")
+
+	conn.Close()
+	logAction(t, db, "restart", "after the collector dropped the connection")
+	conn, r = accept()
+	checkSyslog(t, readLine(r), "restart", "after the collector dropped the connection")
+	if stats := f.Stats(); !stats.Connected || stats.Forwarded != 2 || stats.Address != target {
+		t.Errorf("stats = %+v, want 2 forwarded on a live connection to %s", stats, target)
+	}
+
+	f.Close()
+	conn.Close()
+	logAction(t, db, "kill", "while the forwarder was stopped")
+	logAction(t, db, "remove", "multi\nline")
+	f = startForwarder(t, db, target)
+	defer f.Close()
+	conn, r = accept()
+	defer conn.Close()
+	checkSyslog(t, readLine(r), "kill", "while the forwarder was stopped")
+	checkSyslog(t, readLine(r), "remove", "multi line")
+}
+
+func TestForwardUDP(t *testing.T) {
+	db := newForwardStore(t)
+
+	packets, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer packets.Close()
+
+	f := startForwarder(t, db, "udp://"+packets.LocalAddr().String())
+	defer f.Close()
+	logAction(t, db, "pause", `path="/srv" done`)
+	logAction(t, db, "unpause", "second")
+
+	buf := make([]byte, 2048)
+	for _, want := range [][2]string{{"pause", `path="/srv" done`}, {"unpause", "second"}} {
+		packets.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := packets.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSyslog(t, string(buf[:n]), want[0], want[1])
+	}
+}
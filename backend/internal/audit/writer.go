@@ -0,0 +1,300 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ModeAsync      = "async"
+	ModeCompliance = "compliance"
+
+	defaultBufferSize = 1000
+	defaultRetries    = 3
+	defaultBackoff    = 200 * time.Millisecond
+	enqueueTimeout    = time.Second
+
+	writerKey = "audit.writer"
+)
+
+var (
+	ErrBufferFull = errors.New("audit buffer is full")
+	ErrClosed     = errors.New("audit writer is closed")
+)
+
+type Entry struct {
+	ContainerID   string
+	ContainerName string
+	Action        string
+	Actor         database.Actor
+	Details       string
+}
+
+type Stats struct {
+	Mode        string     `json:"mode"`
+	Healthy     bool       `json:"healthy"`
+	Queued      int        `json:"queued"`
+	Capacity    int        `json:"capacity"`
+	Written     int64      `json:"written"`
+	Retried     int64      `json:"retried"`
+	Failed      int64      `json:"failed"`
+	Dropped     int64      `json:"dropped"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	LastWriteAt *time.Time `json:"lastWriteAt,omitempty"`
+
+	Forwarding *ForwardStats `json:"forwarding,omitempty"`
+}
+
+type Writer struct {
+	store      database.Store
+	compliance bool
+	retries    int
+	backoff    time.Duration
+
+	entries chan Entry
+	done    chan struct{}
+	closed  atomic.Bool
+	wg      sync.WaitGroup
+
+	written atomic.Int64
+	retried atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+
+	mu          sync.Mutex
+	lastError   error
+	lastErrorAt time.Time
+	lastWriteAt time.Time
+}
+
+func NewWriter(store database.Store) (*Writer, error) {
+	mode := os.Getenv("AUDIT_MODE")
+	if mode == "" {
+		mode = ModeAsync
+	}
+	if mode != ModeAsync && mode != ModeCompliance {
+		return nil, fmt.Errorf("invalid AUDIT_MODE %q (expected %s or %s)", mode, ModeAsync, ModeCompliance)
+	}
+
+	bufferSize, err := intFromEnv("AUDIT_BUFFER_SIZE", defaultBufferSize)
+	if err != nil {
+		return nil, err
+	}
+	retries, err := intFromEnv("AUDIT_MAX_RETRIES", defaultRetries)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Writer{
+		store:      store,
+		compliance: mode == ModeCompliance,
+		retries:    retries,
+		backoff:    defaultBackoff,
+		entries:    make(chan Entry, bufferSize),
+		done:       make(chan struct{}),
+	}
+
+	w.wg.Add(1)
+	go w.run()
+
+	return w, nil
+}
+
+func intFromEnv(name string, fallback int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return n, nil
+}
+
+func (w *Writer) Write(entry Entry) error {
+	if w.closed.Load() {
+		w.dropped.Add(1)
+		return ErrClosed
+	}
+
+	if w.compliance {
+		return w.write(entry)
+	}
+
+	select {
+	case w.entries <- entry:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+	select {
+	case w.entries <- entry:
+		return nil
+	case <-timer.C:
+		w.dropped.Add(1)
+		w.recordFailure(ErrBufferFull)
+		log.Printf("Audit entry dropped (%s on %s): %v", entry.Action, entry.ContainerName, ErrBufferFull)
+		return ErrBufferFull
+	}
+}
+
+func (w *Writer) run() {
+	defer w.wg.Done()
+	for {
+		select {
+		case entry := <-w.entries:
+			w.write(entry)
+		case <-w.done:
+			for {
+				select {
+				case entry := <-w.entries:
+					w.write(entry)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *Writer) write(entry Entry) error {
+	var err error
+	delay := w.backoff
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			w.retried.Add(1)
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		err = w.store.LogContainerAction(entry.ContainerID, entry.ContainerName, entry.Action, entry.Actor, entry.Details)
+		if err == nil {
+			w.written.Add(1)
+			w.mu.Lock()
+			w.lastWriteAt = time.Now()
+			w.mu.Unlock()
+			return nil
+		}
+	}
+
+	w.failed.Add(1)
+	w.recordFailure(err)
+	log.Printf("Audit write failed after %d attempts (%s on %s by %s): %v", w.retries+1, entry.Action, entry.ContainerName, entry.Actor.User, err)
+	return err
+}
+
+func (w *Writer) recordFailure(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lastError = err
+	w.lastErrorAt = time.Now()
+}
+
+func (w *Writer) Stats() Stats {
+	stats := Stats{
+		Mode:     ModeAsync,
+		Queued:   len(w.entries),
+		Capacity: cap(w.entries),
+		Written:  w.written.Load(),
+		Retried:  w.retried.Load(),
+		Failed:   w.failed.Load(),
+		Dropped:  w.dropped.Load(),
+	}
+	if w.compliance {
+		stats.Mode = ModeCompliance
+	}
+
+	w.mu.Lock()
+	if w.lastError != nil {
+		stats.LastError = w.lastError.Error()
+		at := w.lastErrorAt
+		stats.LastErrorAt = &at
+	}
+	if !w.lastWriteAt.IsZero() {
+		at := w.lastWriteAt
+		stats.LastWriteAt = &at
+	}
+	recentFailure := w.lastError != nil && w.lastErrorAt.After(w.lastWriteAt)
+	w.mu.Unlock()
+
+	stats.Healthy = !recentFailure && stats.Queued < stats.Capacity*9/10
+	return stats
+}
+
+func (w *Writer) Close(ctx context.Context) error {
+	if !w.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	close(w.done)
+
+	finished := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("audit writer did not drain %d entries: %w", len(w.entries), ctx.Err())
+	}
+}
+
+func (w *Writer) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(writerKey, w)
+
+		if w.compliance && c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead && c.Request.Method != http.MethodOptions {
+			err := w.Write(Entry{
+				ContainerID:   "system",
+				ContainerName: c.Request.URL.Path,
+				Action:        "request",
+				Actor:         Actor(c),
+				Details:       c.Request.Method + " " + c.Request.URL.Path,
+			})
+			if err != nil {
+				apierror.Abort(c, apierror.Unavailable("action refused because it could not be audited: "+err.Error()))
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func Record(c *gin.Context, containerID, containerName, action, details string) {
+	RecordEntry(c, Entry{
+		ContainerID:   containerID,
+		ContainerName: containerName,
+		Action:        action,
+		Actor:         Actor(c),
+		Details:       details,
+	})
+}
+
+func RecordEntry(c *gin.Context, entry Entry) {
+	v, ok := c.Get(writerKey)
+	if !ok {
+		log.Printf("Audit entry lost, no writer configured (%s on %s)", entry.Action, entry.ContainerName)
+		return
+	}
+	v.(*Writer).Write(entry)
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"docker-gui-backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+type failingStore struct {
+	database.Store
+	err error
+}
+
+func (s failingStore) LogContainerAction(containerID, containerName, action string, actor database.Actor, details string) error {
+	return s.err
+}
+
+func TestWriterCountsStoreFailures(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, mode := range []string{ModeAsync, ModeCompliance} {
+		t.Run(mode, func(t *testing.T) {
+			t.Setenv("AUDIT_MODE", mode)
+			t.Setenv("AUDIT_MAX_RETRIES", "1")
+
+			w, err := NewWriter(failingStore{err: errors.New("disk I/O error")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.backoff = 0
+
+			ran := false
+			r := gin.New()
+			r.Use(w.Middleware())
+			r.GET("/containers/:id/files", func(c *gin.Context) {
+				Record(c, c.Param("id"), "web", "list_files", "path=/")
+				c.Status(http.StatusOK)
+			})
+			r.POST("/containers/:id/stop", func(c *gin.Context) {
+				ran = true
+				Record(c, c.Param("id"), "web", "stop", "")
+				c.Status(http.StatusOK)
+			})
+
+			read := httptest.NewRecorder()
+			r.ServeHTTP(read, httptest.NewRequest(http.MethodGet, "/containers/c1/files", nil))
+			stop := httptest.NewRecorder()
+			r.ServeHTTP(stop, httptest.NewRequest(http.MethodPost, "/containers/c1/stop", nil))
+			w.Close(t.Context())
+
+			if read.Code != http.StatusOK {
+				t.Errorf("read: status %d, want %d", read.Code, http.StatusOK)
+			}
+			wantStop, wantFailed := http.StatusOK, int64(2)
+			if mode == ModeCompliance {
+				wantStop = http.StatusServiceUnavailable
+			}
+			if stop.Code != wantStop || ran != (mode == ModeAsync) {
+				t.Errorf("stop: status %d, handler ran %v; want %d", stop.Code, ran, wantStop)
+			}
+
+			stats := w.Stats()
+			if stats.Failed != wantFailed || stats.Retried != wantFailed || stats.Healthy || stats.LastError != "disk I/O error" {
+				t.Errorf("stats = %+v, want %d failed writes and unhealthy", stats, wantFailed)
+			}
+		})
+	}
+}
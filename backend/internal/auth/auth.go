@@ -0,0 +1,425 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/keyfile"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	SessionCookie = "docker_gui_session"
+	TokenPrefix   = "dgui_"
+
+	MethodSession = "session"
+	MethodToken   = "token"
+
+	defaultSessionTTL        = 24 * time.Hour
+	defaultAdminPasswordFile = "docker-gui-admin-password"
+	defaultCacheTTL          = 5 * time.Minute
+	minPasswordLength        = 8
+
+	maxCachedIdentities = 1024
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUnauthenticated    = errors.New("authentication required")
+	ErrExpired            = errors.New("credentials expired")
+	ErrRevoked            = errors.New("token revoked")
+	ErrWeakPassword       = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	ErrUsernameRequired   = errors.New("username is required")
+	ErrUserExists         = errors.New("username is already taken")
+	ErrTokenNameRequired  = errors.New("token name is required")
+)
+
+type Identity struct {
+	UserID    int    `json:"userId"`
+	Username  string `json:"username"`
+	Method    string `json:"method"`
+	TokenID   int    `json:"tokenId,omitempty"`
+	TokenName string `json:"tokenName,omitempty"`
+	SessionID string `json:"-"`
+}
+
+type Service struct {
+	db         database.Store
+	sessionTTL time.Duration
+	cacheTTL   time.Duration
+	dummyHash  []byte
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedIdentity
+}
+
+type cachedIdentity struct {
+	identity   Identity
+	expiresAt  *time.Time
+	verifiedAt time.Time
+}
+
+func NewService(db database.Store) (*Service, error) {
+	ttl := defaultSessionTTL
+	if v := os.Getenv("SESSION_TTL"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SESSION_TTL: %w", err)
+		}
+		ttl = parsed
+	}
+
+	cacheTTL := defaultCacheTTL
+	if v := os.Getenv("AUTH_CACHE_TTL"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid AUTH_CACHE_TTL %q", v)
+		}
+		cacheTTL = parsed
+	}
+
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("docker-gui"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	service := &Service{db: db, sessionTTL: ttl, cacheTTL: cacheTTL, dummyHash: dummyHash, cache: make(map[string]cachedIdentity)}
+	if err := service.Bootstrap(); err != nil {
+		if !errors.Is(err, database.ErrUnavailable) {
+			return nil, err
+		}
+		log.Println("Warning: database unavailable, initial user setup deferred until it returns")
+	}
+
+	return service, nil
+}
+
+func (s *Service) SessionTTL() time.Duration {
+	return s.sessionTTL
+}
+
+func (s *Service) Bootstrap() error {
+	count, err := s.db.CountUsers()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	username := os.Getenv("ADMIN_USERNAME")
+	if username == "" {
+		username = "admin"
+	}
+
+	password, generated, err := keyfile.Load(keyfile.Source{
+		Name:        "initial admin password",
+		Env:         "ADMIN_PASSWORD",
+		FileEnv:     "ADMIN_PASSWORD_FILE",
+		DefaultFile: defaultAdminPasswordFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.CreateUser(username, password); err != nil {
+		return fmt.Errorf("failed to create initial admin user: %w", err)
+	}
+
+	if generated {
+		log.Printf("Created initial user %q with the generated password; change it after logging in and delete the password file", username)
+	} else {
+		log.Printf("Created initial user %q from ADMIN_USERNAME/ADMIN_PASSWORD", username)
+	}
+
+	return nil
+}
+
+func (s *Service) CreateUser(username, password string) (*database.User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, ErrUsernameRequired
+	}
+	if len(password) < minPasswordLength {
+		return nil, ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.CreateUser(username, string(hash))
+	if errors.Is(err, database.ErrDuplicate) {
+		return nil, fmt.Errorf("%w: %s", ErrUserExists, username)
+	}
+	return user, err
+}
+
+func (s *Service) ChangePassword(identity *Identity, current, next string) error {
+	user, err := s.db.GetUser(identity.UserID)
+	if err != nil {
+		return err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)) != nil {
+		return ErrInvalidCredentials
+	}
+	if len(next) < minPasswordLength {
+		return ErrWeakPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.UpdateUserPassword(user.ID, string(hash)); err != nil {
+		return err
+	}
+
+	keep := identity.SessionID
+	if identity.Method != MethodSession {
+		keep = ""
+	}
+	s.forgetSessions(user.ID, "session:"+keep)
+	return s.db.DeleteUserSessions(user.ID, keep)
+}
+
+func (s *Service) Login(username, password string) (string, *Identity, error) {
+	user, err := s.db.GetUserByUsername(username)
+	if errors.Is(err, database.ErrNotFound) {
+		bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return "", nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	sessionID, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	if err := s.db.CreateSession(hashToken(sessionID), user.ID, now.Add(s.sessionTTL)); err != nil {
+		return "", nil, err
+	}
+	s.db.DeleteExpiredSessions(now)
+
+	return sessionID, &Identity{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Method:    MethodSession,
+		SessionID: hashToken(sessionID),
+	}, nil
+}
+
+func (s *Service) Logout(identity *Identity) error {
+	if identity.Method != MethodSession {
+		return nil
+	}
+	s.forget("session:" + identity.SessionID)
+	return s.db.DeleteSession(identity.SessionID)
+}
+
+func (s *Service) AuthenticateSession(sessionID string) (*Identity, error) {
+	key := "session:" + hashToken(sessionID)
+	identity, expiresAt, err := s.authenticateSession(hashToken(sessionID))
+	return s.remember(key, identity, expiresAt, err)
+}
+
+func (s *Service) authenticateSession(id string) (*Identity, *time.Time, error) {
+	session, err := s.db.GetSession(id)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		s.db.DeleteSession(session.ID)
+		return nil, nil, ErrExpired
+	}
+
+	user, err := s.db.GetUser(session.UserID)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &Identity{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Method:    MethodSession,
+		SessionID: session.ID,
+	}, &session.ExpiresAt, nil
+}
+
+func (s *Service) AuthenticateToken(raw string) (*Identity, error) {
+	if !strings.HasPrefix(raw, TokenPrefix) {
+		return nil, ErrUnauthenticated
+	}
+
+	key := "token:" + hashToken(raw)
+	identity, expiresAt, err := s.authenticateToken(hashToken(raw))
+	return s.remember(key, identity, expiresAt, err)
+}
+
+func (s *Service) authenticateToken(tokenHash string) (*Identity, *time.Time, error) {
+	token, err := s.db.GetAPITokenByHash(tokenHash)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil {
+		return nil, nil, ErrRevoked
+	}
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, nil, ErrExpired
+	}
+
+	user, err := s.db.GetUser(token.UserID)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.db.TouchAPIToken(token.ID, now)
+
+	return &Identity{
+		UserID:    user.ID,
+		Username:  user.Username,
+		Method:    MethodToken,
+		TokenID:   token.ID,
+		TokenName: token.Name,
+	}, token.ExpiresAt, nil
+}
+
+func (s *Service) remember(key string, identity *Identity, expiresAt *time.Time, err error) (*Identity, error) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if err == nil {
+		if len(s.cache) >= maxCachedIdentities {
+			s.pruneCache()
+		}
+		s.cache[key] = cachedIdentity{identity: *identity, expiresAt: expiresAt, verifiedAt: time.Now()}
+		return identity, nil
+	}
+
+	if !errors.Is(err, database.ErrUnavailable) {
+		delete(s.cache, key)
+		return nil, err
+	}
+
+	cached, ok := s.cache[key]
+	if !ok {
+		return nil, err
+	}
+	if time.Since(cached.verifiedAt) > s.cacheTTL {
+		delete(s.cache, key)
+		return nil, err
+	}
+	if cached.expiresAt != nil && time.Now().After(*cached.expiresAt) {
+		delete(s.cache, key)
+		return nil, ErrExpired
+	}
+
+	identity = new(Identity)
+	*identity = cached.identity
+	return identity, nil
+}
+
+func (s *Service) pruneCache() {
+	now := time.Now()
+	for key, cached := range s.cache {
+		if (cached.expiresAt != nil && now.After(*cached.expiresAt)) || now.Sub(cached.verifiedAt) > s.cacheTTL {
+			delete(s.cache, key)
+		}
+	}
+	for key := range s.cache {
+		if len(s.cache) < maxCachedIdentities {
+			break
+		}
+		delete(s.cache, key)
+	}
+}
+
+func (s *Service) forget(key string) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	delete(s.cache, key)
+}
+
+func (s *Service) forgetSessions(userID int, keep string) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	for key, cached := range s.cache {
+		if cached.identity.UserID == userID && strings.HasPrefix(key, "session:") && key != keep {
+			delete(s.cache, key)
+		}
+	}
+}
+
+func (s *Service) CreateToken(userID int, name string, ttl time.Duration) (string, *database.APIToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, ErrTokenNameRequired
+	}
+
+	secret, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+	raw := TokenPrefix + secret
+
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	token, err := s.db.CreateAPIToken(userID, name, hashToken(raw), raw[:len(TokenPrefix)+6], expiresAt)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return raw, token, nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
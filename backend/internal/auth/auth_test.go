@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"docker-gui-backend/internal/database"
+)
+
+func TestCachedIdentityDuringOutage(t *testing.T) {
+	t.Setenv("ADMIN_USERNAME", "admin")
+	t.Setenv("ADMIN_PASSWORD", "outage-password")
+	t.Setenv("AUTH_CACHE_TTL", "")
+
+	db, err := database.NewResilient(database.DriverMemory, database.NewMemory, filepath.Join(t.TempDir(), "queue.jsonl"), 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service, err := NewService(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session, _, err := service.Login("admin", "outage-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AuthenticateSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	db.Close()
+	if _, err := service.AuthenticateSession(session); err != nil {
+		t.Fatalf("cached session rejected during outage: %v", err)
+	}
+	if db.Available() {
+		t.Fatal("database still reported available after close")
+	}
+
+	service.cacheTTL = 0
+	if _, err := service.AuthenticateSession(session); !errors.Is(err, database.ErrUnavailable) {
+		t.Fatalf("stale cached session: err = %v, want %v", err, database.ErrUnavailable)
+	}
+	service.cacheTTL = time.Hour
+	if _, err := service.AuthenticateSession(session); !errors.Is(err, database.ErrUnavailable) {
+		t.Errorf("stale session was not evicted: err = %v", err)
+	}
+}
+
+func TestBootstrapGeneratedPassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admin-password")
+	t.Setenv("ADMIN_USERNAME", "admin")
+	t.Setenv("ADMIN_PASSWORD", "")
+	t.Setenv("ADMIN_PASSWORD_FILE", path)
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	db, err := database.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	service, err := NewService(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("password file mode %o, want 600", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(path)
+	password := strings.TrimSpace(string(data))
+	if strings.Contains(logs.String(), password) {
+		t.Errorf("generated password was logged:\n%s", logs.String())
+	}
+	if _, _, err := service.Login("admin", password); err != nil {
+		t.Errorf("login with the generated password: %v", err)
+	}
+}
+
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	t.Setenv("ADMIN_USERNAME", "admin")
+	t.Setenv("ADMIN_PASSWORD", "original-password")
+
+	db, err := database.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	service, err := NewService(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, identity, err := service.Login("admin", "original-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := service.Login("admin", "original-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AuthenticateSession(other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ChangePassword(identity, "original-password", "rotated-password"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AuthenticateSession(current); err != nil {
+		t.Errorf("session that changed the password was revoked: %v", err)
+	}
+	if _, err := service.AuthenticateSession(other); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("other session after a password change: err = %v, want %v", err, ErrUnauthenticated)
+	}
+
+	raw, _, err := service.CreateToken(identity.UserID, "ci", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenIdentity, err := service.AuthenticateToken(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ChangePassword(tokenIdentity, "rotated-password", "rotated-again"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AuthenticateSession(current); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("session after a password change through a token: err = %v, want %v", err, ErrUnauthenticated)
+	}
+}
+
+func TestCreateUserConcurrently(t *testing.T) {
+	t.Setenv("ADMIN_USERNAME", "admin")
+	t.Setenv("ADMIN_PASSWORD", "admin-password")
+
+	db, err := database.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	service, err := NewService(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			_, err := service.CreateUser("carol", "carol-password")
+			errs <- err
+		}()
+	}
+
+	created := 0
+	for i := 0; i < cap(errs); i++ {
+		switch err := <-errs; {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrUserExists):
+			t.Errorf("concurrent create: err = %v, want %v", err, ErrUserExists)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d concurrent creates succeeded, want 1", created)
+	}
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+const identityKey = "auth.identity"
+
+func (s *Service) Middleware(publicPaths ...string) gin.HandlerFunc {
+	public := make(map[string]bool, len(publicPaths))
+	for _, p := range publicPaths {
+		public[p] = true
+	}
+
+	return func(c *gin.Context) {
+		if public[c.FullPath()] || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		identity, err := s.authenticate(c)
+		if err != nil {
+			switch {
+			case errors.Is(err, database.ErrUnavailable):
+				apierror.Abort(c, apierror.Unavailable("credentials cannot be verified while the database is unavailable"))
+			case errors.Is(err, ErrUnauthenticated), errors.Is(err, ErrExpired), errors.Is(err, ErrRevoked):
+				apierror.Abort(c, apierror.Unauthenticated(err.Error()))
+			default:
+				apierror.Abort(c, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "failed to verify credentials"))
+			}
+			return
+		}
+
+		c.Set(identityKey, identity)
+		c.Next()
+	}
+}
+
+func (s *Service) authenticate(c *gin.Context) (*Identity, error) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return nil, ErrUnauthenticated
+		}
+		return s.AuthenticateToken(strings.TrimSpace(token))
+	}
+
+	if cookie, err := c.Cookie(SessionCookie); err == nil && cookie != "" {
+		return s.AuthenticateSession(cookie)
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+func IdentityFrom(c *gin.Context) *Identity {
+	if v, ok := c.Get(identityKey); ok {
+		if identity, ok := v.(*Identity); ok {
+			return identity
+		}
+	}
+	return nil
+}
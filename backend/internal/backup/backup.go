@@ -0,0 +1,290 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+const (
+	defaultDir         = "backups"
+	defaultHelperImage = "busybox:latest"
+	volumeMountPath    = "/volume"
+)
+
+var ErrChecksumMismatch = errors.New("backup checksum mismatch")
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+type Options struct {
+	StopContainers bool `json:"stopContainers"`
+}
+
+type RestoreOptions struct {
+	TargetVolume   string `json:"targetVolume"`
+	Replace        bool   `json:"replace"`
+	StopContainers bool   `json:"stopContainers"`
+}
+
+type Service struct {
+	dockerClient docker.API
+	db           database.Store
+	host         string
+	dir          string
+	helperImage  string
+}
+
+func NewService(dockerClient docker.API, db database.Store) (*Service, error) {
+	dir := os.Getenv("BACKUP_DIR")
+	if dir == "" {
+		dir = defaultDir
+	}
+
+	helperImage := os.Getenv("BACKUP_HELPER_IMAGE")
+	if helperImage == "" {
+		helperImage = defaultHelperImage
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	return &Service{
+		dockerClient: dockerClient,
+		db:           db,
+		host:         hosts.Local,
+		dir:          dir,
+		helperImage:  helperImage,
+	}, nil
+}
+
+func (s *Service) On(host string, dockerClient docker.API) *Service {
+	scoped := *s
+	scoped.host = host
+	scoped.dockerClient = dockerClient
+	return &scoped
+}
+
+func (s *Service) Backup(ctx context.Context, volumeName string, opts Options) (*database.VolumeBackup, error) {
+	if opts.StopContainers {
+		restart, err := s.stopDependents(ctx, volumeName)
+		if err != nil {
+			return nil, err
+		}
+		defer restart()
+	}
+
+	fileName := fmt.Sprintf("%s-%s.tar.gz", unsafeChars.ReplaceAllString(volumeName, "_"), time.Now().UTC().Format("20060102T150405.000Z"))
+	path := filepath.Join(s.dir, fileName)
+
+	tmp, err := os.CreateTemp(s.dir, fileName+".*.partial")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	err = s.archive(ctx, volumeName, io.MultiWriter(tmp, hash, counter))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	backup, err := s.db.CreateVolumeBackup(s.host, volumeName, fileName, counter.n, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+func (s *Service) Export(ctx context.Context, volumeName string, w io.Writer) error {
+	return s.archive(ctx, volumeName, w)
+}
+
+func (s *Service) Get(id int) (*database.VolumeBackup, error) {
+	backup, err := s.db.GetVolumeBackup(id)
+	if err != nil {
+		return nil, err
+	}
+	if backup.Host != s.host {
+		return nil, database.ErrNotFound
+	}
+	return backup, nil
+}
+
+func (s *Service) Open(id int) (*database.VolumeBackup, *os.File, error) {
+	backup, err := s.Get(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filepath.Join(s.dir, backup.FileName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return backup, file, nil
+}
+
+func (s *Service) Restore(ctx context.Context, id int, opts RestoreOptions) (*database.VolumeBackup, error) {
+	backup, file, err := s.Open(id)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := verifyChecksum(file, backup.Checksum); err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	target := opts.TargetVolume
+	if target == "" {
+		target = backup.VolumeName
+	}
+
+	exists, err := s.dockerClient.VolumeExists(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if _, err := s.dockerClient.CreateVolume(ctx, models.CreateVolumeRequest{Name: target}); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.StopContainers {
+		restart, err := s.stopDependents(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		defer restart()
+	}
+
+	script := "tar -xzf - -C " + volumeMountPath
+	if opts.Replace {
+		script = "find " + volumeMountPath + " -mindepth 1 -delete && " + script
+	}
+
+	err = s.dockerClient.RunHelper(ctx, docker.HelperOptions{
+		Image:   s.helperImage,
+		Cmd:     []string{"sh", "-c", script},
+		Purpose: "restore",
+		Mounts: []mount.Mount{{
+			Type:   mount.TypeVolume,
+			Source: target,
+			Target: volumeMountPath,
+		}},
+		Stdin: file,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
+
+func (s *Service) Delete(id int) error {
+	backup, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(s.dir, backup.FileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return s.db.DeleteVolumeBackup(id)
+}
+
+func (s *Service) archive(ctx context.Context, volumeName string, w io.Writer) error {
+	return s.dockerClient.RunHelper(ctx, docker.HelperOptions{
+		Image:   s.helperImage,
+		Cmd:     []string{"tar", "-czf", "-", "-C", volumeMountPath, "."},
+		Purpose: "backup",
+		Mounts: []mount.Mount{{
+			Type:     mount.TypeVolume,
+			Source:   volumeName,
+			Target:   volumeMountPath,
+			ReadOnly: true,
+		}},
+		Stdout: w,
+	})
+}
+
+func (s *Service) stopDependents(ctx context.Context, volumeName string) (func(), error) {
+	vol, err := s.dockerClient.InspectVolume(ctx, volumeName)
+	if err != nil {
+		return nil, err
+	}
+
+	var stopped []string
+	restart := func() {
+		for _, id := range stopped {
+			if err := s.dockerClient.StartContainer(context.Background(), id); err != nil {
+				log.Printf("Failed to restart container %s after volume operation: %v", id, err)
+			}
+		}
+	}
+
+	for _, user := range vol.UsedBy {
+		if user.State != "running" {
+			continue
+		}
+		if err := s.dockerClient.StopContainer(ctx, user.ContainerID); err != nil {
+			restart()
+			return nil, fmt.Errorf("failed to stop container %s: %w", user.ContainerName, err)
+		}
+		stopped = append(stopped, user.ContainerID)
+	}
+
+	return restart, nil
+}
+
+func verifyChecksum(r io.Reader, expected string) error {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, expected, actual)
+	}
+
+	return nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
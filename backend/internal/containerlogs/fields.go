@@ -0,0 +1,230 @@
+package containerlogs
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"docker-gui-backend/pkg/models"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatLogfmt = "logfmt"
+)
+
+const (
+	FieldLevel   = "level"
+	FieldMessage = "msg"
+	FieldTime    = "time"
+	FieldTraceID = "trace_id"
+)
+
+var fieldAliases = map[string]string{
+	"level":                        FieldLevel,
+	"lvl":                          FieldLevel,
+	"severity":                     FieldLevel,
+	"loglevel":                     FieldLevel,
+	"log.level":                    FieldLevel,
+	"levelname":                    FieldLevel,
+	"msg":                          FieldMessage,
+	"message":                      FieldMessage,
+	"@message":                     FieldMessage,
+	"time":                         FieldTime,
+	"ts":                           FieldTime,
+	"timestamp":                    FieldTime,
+	"@timestamp":                   FieldTime,
+	"trace_id":                     FieldTraceID,
+	"traceid":                      FieldTraceID,
+	"trace.id":                     FieldTraceID,
+	"dd.trace_id":                  FieldTraceID,
+	"oteltraceid":                  FieldTraceID,
+	"logging.googleapis.com/trace": FieldTraceID,
+}
+
+var numericLevels = map[string]string{
+	"10": LevelTrace,
+	"20": LevelDebug,
+	"30": LevelInfo,
+	"40": LevelWarn,
+	"50": LevelError,
+	"60": LevelFatal,
+}
+
+func CanonicalField(key string) string {
+	if canonical, ok := fieldAliases[strings.ToLower(key)]; ok {
+		return canonical
+	}
+	return key
+}
+
+func ParseFields(message string) (string, map[string]string) {
+	trimmed := strings.TrimSpace(message)
+	if strings.HasPrefix(trimmed, "{") {
+		if fields, ok := parseJSONFields(trimmed); ok {
+			return FormatJSON, normalizeFields(fields)
+		}
+	}
+	if fields, ok := parseLogfmt(trimmed); ok {
+		return FormatLogfmt, normalizeFields(fields)
+	}
+	return "", nil
+}
+
+func Parse(entry models.LogEntry) models.LogEntry {
+	entry.Format, entry.Fields = ParseFields(entry.Message)
+	if level, ok := NormalizeLevel(entry.Fields[FieldLevel]); ok {
+		entry.Level = level
+	} else {
+		entry.Level = DetectLevel(entry.Message)
+	}
+	return entry
+}
+
+func parseJSONFields(line string) (map[string]string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(line))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil || decoder.More() {
+		return nil, false
+	}
+	fields := map[string]string{}
+	flatten(fields, "", object)
+	return fields, true
+}
+
+func flatten(fields map[string]string, prefix string, object map[string]any) {
+	for key, value := range object {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(fields, key, v)
+		case string:
+			fields[key] = v
+		case json.Number:
+			fields[key] = v.String()
+		case bool:
+			fields[key] = strconv.FormatBool(v)
+		case nil:
+			fields[key] = ""
+		default:
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			encoder.Encode(v)
+			fields[key] = strings.TrimSpace(buf.String())
+		}
+	}
+}
+
+func parseLogfmt(line string) (map[string]string, bool) {
+	fields := map[string]string{}
+	for line != "" {
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 || strings.ContainsAny(line[:eq], " \t\"") {
+			return nil, false
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := closingQuote(line)
+			if end < 0 {
+				return nil, false
+			}
+			unquoted, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, false
+			}
+			value, line = unquoted, line[end+1:]
+			if line != "" && line[0] != ' ' && line[0] != '\t' {
+				return nil, false
+			}
+		} else {
+			end := strings.IndexAny(line, " \t")
+			if end < 0 {
+				end = len(line)
+			}
+			value, line = line[:end], line[end:]
+			if strings.Contains(value, `"`) {
+				return nil, false
+			}
+		}
+		fields[key] = value
+		line = strings.TrimLeft(line, " \t")
+	}
+	return fields, len(fields) >= 2
+}
+
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func normalizeFields(fields map[string]string) map[string]string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	normalized := make(map[string]string, len(fields))
+	for _, key := range keys {
+		canonical := CanonicalField(key)
+		if _, taken := fields[canonical]; canonical != key && taken {
+			canonical = key
+		}
+		if _, taken := normalized[canonical]; taken {
+			canonical = key
+		}
+		normalized[canonical] = fields[key]
+	}
+
+	if raw, ok := normalized[FieldLevel]; ok {
+		if level, ok := NormalizeLevel(raw); ok {
+			normalized[FieldLevel] = level
+		} else if level, ok := numericLevels[raw]; ok {
+			normalized[FieldLevel] = level
+		}
+	}
+	if raw, ok := normalized[FieldTime]; ok {
+		if t, ok := parseFieldTime(raw); ok {
+			normalized[FieldTime] = t.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	return normalized
+}
+
+func parseFieldTime(value string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, true
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+	switch {
+	case n >= 1e17:
+		return time.Unix(0, int64(n)), true
+	case n >= 1e14:
+		return time.UnixMicro(int64(n)), true
+	case n >= 1e11:
+		return time.UnixMilli(int64(n)), true
+	}
+	whole, frac := math.Modf(n)
+	return time.Unix(int64(whole), int64(frac*float64(time.Second))), true
+}
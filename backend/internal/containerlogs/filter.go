@@ -0,0 +1,116 @@
+package containerlogs
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/pkg/models"
+)
+
+const maxPatternLength = 512
+
+type Filter struct {
+	Since    time.Time
+	Until    time.Time
+	Stream   string
+	Contains string
+	Pattern  *regexp.Regexp
+	Levels   map[string]bool
+	Query    *Query
+}
+
+func (f Filter) Options(tail int) docker.LogOptions {
+	return docker.LogOptions{Tail: tail, Since: f.Since, Until: f.Until, Stream: f.Stream}
+}
+
+func (f Filter) Match(entry models.LogEntry) bool {
+	switch {
+	case f.Stream != "" && entry.Stream != f.Stream:
+		return false
+	case !f.Since.IsZero() && entry.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && entry.Timestamp.After(f.Until):
+		return false
+	case len(f.Levels) > 0 && !f.Levels[entry.Level]:
+		return false
+	case f.Contains != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(f.Contains)):
+		return false
+	case f.Pattern != nil && !f.Pattern.MatchString(entry.Message):
+		return false
+	case f.Query != nil && !f.Query.Match(entry):
+		return false
+	}
+	return true
+}
+
+func Apply(entries []models.LogEntry, f Filter) []models.LogEntry {
+	matched := make([]models.LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		entry = Parse(entry)
+		if f.Match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+func ParsePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	if len(pattern) > maxPatternLength {
+		return nil, fmt.Errorf("regex is longer than %d characters", maxPatternLength)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex: %w", err)
+	}
+	return re, nil
+}
+
+func ParseStream(stream string) (string, error) {
+	switch stream {
+	case "", docker.StreamStdout, docker.StreamStderr:
+		return stream, nil
+	}
+	return "", fmt.Errorf("stream must be %s or %s", docker.StreamStdout, docker.StreamStderr)
+}
+
+func ParseLevels(value string) (map[string]bool, error) {
+	var levels map[string]bool
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		level, ok := NormalizeLevel(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown level %q (use %s)", name, strings.Join(Levels, ", "))
+		}
+		if levels == nil {
+			levels = map[string]bool{}
+		}
+		levels[level] = true
+	}
+	return levels, nil
+}
+
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		whole := int64(seconds)
+		return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 timestamp, Unix time or duration such as 15m", value)
+}
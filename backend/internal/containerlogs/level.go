@@ -0,0 +1,89 @@
+package containerlogs
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	LevelTrace = "trace"
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+	LevelFatal = "fatal"
+)
+
+var Levels = []string{LevelTrace, LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal}
+
+var levelAliases = map[string]string{
+	"trace":       LevelTrace,
+	"debug":       LevelDebug,
+	"dbg":         LevelDebug,
+	"info":        LevelInfo,
+	"information": LevelInfo,
+	"notice":      LevelInfo,
+	"warn":        LevelWarn,
+	"warning":     LevelWarn,
+	"error":       LevelError,
+	"err":         LevelError,
+	"crit":        LevelFatal,
+	"critical":    LevelFatal,
+	"alert":       LevelFatal,
+	"emerg":       LevelFatal,
+	"emergency":   LevelFatal,
+	"fatal":       LevelFatal,
+	"panic":       LevelFatal,
+}
+
+var (
+	levelField   = regexp.MustCompile(`(?i)\b(?:level|lvl|severity|loglevel)"?\s*[:=]\s*"?([a-z]+)`)
+	klogPrefix   = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}`)
+	rubyPrefix   = regexp.MustCompile(`^([DIWEF]), \[`)
+	syslogPrefix = regexp.MustCompile(`^<([0-7])>`)
+	levelBracket = regexp.MustCompile(`(?i)[\[(<](trace|debug|dbg|info|notice|warn|warning|error|err|crit|critical|alert|emerg|fatal|panic)[\])>:]`)
+	levelWord    = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|ERR|CRIT|CRITICAL|ALERT|EMERG|FATAL|PANIC)\b`)
+)
+
+var (
+	letterLevels = map[string]string{"D": LevelDebug, "I": LevelInfo, "W": LevelWarn, "E": LevelError, "F": LevelFatal}
+	syslogLevels = []string{LevelFatal, LevelFatal, LevelFatal, LevelError, LevelWarn, LevelInfo, LevelInfo, LevelDebug}
+)
+
+const levelScanLength = 160
+
+func NormalizeLevel(level string) (string, bool) {
+	normalized, ok := levelAliases[strings.ToLower(strings.TrimSpace(level))]
+	return normalized, ok
+}
+
+func DetectLevel(message string) string {
+	if m := levelField.FindStringSubmatch(message); m != nil {
+		if level, ok := NormalizeLevel(m[1]); ok {
+			return level
+		}
+	}
+	if m := klogPrefix.FindStringSubmatch(message); m != nil {
+		return letterLevels[m[1]]
+	}
+	if m := rubyPrefix.FindStringSubmatch(message); m != nil {
+		return letterLevels[m[1]]
+	}
+	if m := syslogPrefix.FindStringSubmatch(message); m != nil {
+		return syslogLevels[m[1][0]-'0']
+	}
+
+	head := message
+	if len(head) > levelScanLength {
+		head = head[:levelScanLength]
+	}
+	if m := levelBracket.FindStringSubmatch(head); m != nil {
+		level, _ := NormalizeLevel(m[1])
+		return level
+	}
+	if m := levelWord.FindStringSubmatch(head); m != nil {
+		level, _ := NormalizeLevel(m[1])
+		return level
+	}
+	return ""
+}
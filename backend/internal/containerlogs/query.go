@@ -0,0 +1,314 @@
+package containerlogs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"docker-gui-backend/pkg/models"
+)
+
+const maxQueryLength = 1024
+
+type Query struct {
+	root condition
+}
+
+func (q *Query) Match(entry models.LogEntry) bool {
+	return q.root.match(entry)
+}
+
+type condition interface {
+	match(entry models.LogEntry) bool
+}
+
+type allOf []condition
+
+func (c allOf) match(entry models.LogEntry) bool {
+	for _, sub := range c {
+		if !sub.match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+type anyOf []condition
+
+func (c anyOf) match(entry models.LogEntry) bool {
+	for _, sub := range c {
+		if sub.match(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+type not struct {
+	condition
+}
+
+func (c not) match(entry models.LogEntry) bool {
+	return !c.condition.match(entry)
+}
+
+type comparison struct {
+	field string
+	op    string
+	value string
+}
+
+func (c comparison) match(entry models.LogEntry) bool {
+	actual, ok := fieldValue(entry, c.field)
+	switch c.op {
+	case "=":
+		return ok && strings.EqualFold(actual, c.value)
+	case "!=":
+		return !ok || !strings.EqualFold(actual, c.value)
+	case "~":
+		return ok && strings.Contains(strings.ToLower(actual), strings.ToLower(c.value))
+	}
+
+	if !ok {
+		return false
+	}
+	a, errA := strconv.ParseFloat(actual, 64)
+	b, errB := strconv.ParseFloat(c.value, 64)
+	if errA != nil || errB != nil {
+		return false
+	}
+	switch c.op {
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	}
+	return false
+}
+
+func fieldValue(entry models.LogEntry, field string) (string, bool) {
+	switch field {
+	case FieldLevel:
+		return entry.Level, entry.Level != ""
+	case "stream":
+		return entry.Stream, true
+	case FieldMessage:
+		if msg, ok := entry.Fields[FieldMessage]; ok {
+			return msg, true
+		}
+		return entry.Message, true
+	}
+	value, ok := entry.Fields[field]
+	return value, ok
+}
+
+func ParseQuery(query string) (*Query, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	if len(query) > maxQueryLength {
+		return nil, fmt.Errorf("query is longer than %d characters", maxQueryLength)
+	}
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	p := &queryParser{tokens: tokens}
+	root, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	return &Query{root: root}, nil
+}
+
+type token struct {
+	kind  string
+	value string
+}
+
+func (t token) String() string {
+	if t.kind == "word" {
+		return strconv.Quote(t.value)
+	}
+	return t.value
+}
+
+var queryOperators = []string{"!=", ">=", "<=", "=", "~", ">", "<"}
+
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		switch ch := query[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			i++
+			continue
+		case ch == '(' || ch == ')':
+			tokens = append(tokens, token{kind: string(ch), value: string(ch)})
+			i++
+			continue
+		case ch == '"':
+			end := closingQuote(query[i:])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			value, err := strconv.Unquote(query[i : i+end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d", i)
+			}
+			tokens = append(tokens, token{kind: "word", value: value})
+			i += end + 1
+			continue
+		}
+
+		if op := operatorAt(query[i:]); op != "" {
+			tokens = append(tokens, token{kind: "op", value: op})
+			i += len(op)
+			continue
+		}
+
+		start := i
+		for i < len(query) && !strings.ContainsRune(" \t\n()\"!=<>~", rune(query[i])) {
+			i++
+		}
+		if i == start {
+			return nil, fmt.Errorf("unexpected %q at %d", query[i], i)
+		}
+		word := query[start:i]
+		switch strings.ToUpper(word) {
+		case "AND", "OR", "NOT":
+			tokens = append(tokens, token{kind: strings.ToUpper(word), value: strings.ToUpper(word)})
+		default:
+			tokens = append(tokens, token{kind: "word", value: word})
+		}
+	}
+	return tokens, nil
+}
+
+func operatorAt(s string) string {
+	for _, op := range queryOperators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos].kind
+	}
+	return ""
+}
+
+func (p *queryParser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of query")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *queryParser) or() (condition, error) {
+	first, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	conditions := anyOf{first}
+	for p.peek() == "OR" {
+		p.pos++
+		next, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, next)
+	}
+	if len(conditions) == 1 {
+		return first, nil
+	}
+	return conditions, nil
+}
+
+func (p *queryParser) and() (condition, error) {
+	first, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	conditions := allOf{first}
+	for {
+		switch p.peek() {
+		case "AND":
+			p.pos++
+		case "word", "NOT", "(":
+		default:
+			if len(conditions) == 1 {
+				return first, nil
+			}
+			return conditions, nil
+		}
+		next, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, next)
+	}
+}
+
+func (p *queryParser) unary() (condition, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t.kind {
+	case "NOT":
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return not{inner}, nil
+	case "(":
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if closing, err := p.next(); err != nil || closing.kind != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		return inner, nil
+	case "word":
+		return p.comparison(t.value)
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func (p *queryParser) comparison(field string) (condition, error) {
+	op, err := p.next()
+	if err != nil || op.kind != "op" {
+		return nil, fmt.Errorf("expected an operator after %q", field)
+	}
+	value, err := p.next()
+	if err != nil || value.kind != "word" {
+		return nil, fmt.Errorf("expected a value after %s%s", field, op.value)
+	}
+
+	c := comparison{field: CanonicalField(field), op: op.value, value: value.value}
+	if c.field == FieldLevel && (c.op == "=" || c.op == "!=") {
+		if level, ok := NormalizeLevel(c.value); ok {
+			c.value = level
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,80 @@
+package containerlogs
+
+import (
+	"reflect"
+	"testing"
+
+	"docker-gui-backend/pkg/models"
+)
+
+func TestParse(t *testing.T) {
+	entry := Parse(models.LogEntry{Message: `{"severity":"WARNING","@timestamp":1714557600,"message":"slow query","traceId":"abc123","db":{"rows":12}}`})
+	want := map[string]string{"level": "warn", "time": "2024-05-01T10:00:00Z", "msg": "slow query", "trace_id": "abc123", "db.rows": "12"}
+	if entry.Format != FormatJSON || entry.Level != "warn" || !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("json: got %s %s %v", entry.Format, entry.Level, entry.Fields)
+	}
+
+	entry = Parse(models.LogEntry{Message: `ts=1714557600.5 lvl=dbg msg="cache miss" key=user:42`})
+	want = map[string]string{"level": "debug", "time": "2024-05-01T10:00:00.5Z", "msg": "cache miss", "key": "user:42"}
+	if entry.Format != FormatLogfmt || !reflect.DeepEqual(entry.Fields, want) {
+		t.Errorf("logfmt: got %s %v", entry.Format, entry.Fields)
+	}
+
+	for _, message := range []string{"GET / 200", "[error] upstream timed out", "{not json", "a=1"} {
+		if entry := Parse(models.LogEntry{Message: message}); entry.Format != "" {
+			t.Errorf("%q parsed as %s", message, entry.Format)
+		}
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	entries := []models.LogEntry{
+		Parse(models.LogEntry{Message: `{"level":"info","msg":"charge accepted","service":"payments","http":{"status":200}}`, Stream: "stdout"}),
+		Parse(models.LogEntry{Message: `{"severity":"ERROR","message":"charge failed","service":"payments","traceId":"abc123","http":{"status":502}}`, Stream: "stdout"}),
+		Parse(models.LogEntry{Message: `level=error msg="ledger unavailable" service=ledger trace_id=def456`, Stream: "stderr"}),
+		{Message: "GET / 200", Stream: "stdout"},
+	}
+
+	cases := []struct {
+		query string
+		want  []int
+	}{
+		{"level=error AND service=payments", []int{1}},
+		{"level=ERR service=payments", []int{1}},
+		{"trace_id=def456 OR http.status>=500", []int{1, 2}},
+		{"NOT (level=error)", []int{0, 3}},
+		{"level!=error", []int{0, 3}},
+		{`msg~"LEDGER"`, []int{2}},
+		{"msg~200", []int{3}},
+		{"stream=stderr", []int{2}},
+		{"http.status<300", []int{0}},
+		{"http.status>abc", nil},
+		{"traceId=abc123", []int{1}},
+	}
+
+	for _, tc := range cases {
+		q, err := ParseQuery(tc.query)
+		if err != nil {
+			t.Errorf("%s: %v", tc.query, err)
+			continue
+		}
+		var got []int
+		for i, entry := range entries {
+			if q.Match(entry) {
+				got = append(got, i)
+			}
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s matched %v, want %v", tc.query, got, tc.want)
+		}
+	}
+
+	if q, err := ParseQuery("  "); q != nil || err != nil {
+		t.Errorf("blank query = %v, %v", q, err)
+	}
+	for _, query := range []string{"level=", "(level=error", "level=error OR", "!level", `msg="open`, "level=error)"} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("query %q was accepted", query)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type User struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type Session struct {
+	ID        string
+	UserID    int
+	ExpiresAt time.Time
+}
+
+type APIToken struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  string     `json:"created_at"`
+}
+
+func (db *DB) CountUsers() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+func (db *DB) CreateUser(username, passwordHash string) (*User, error) {
+	result, err := db.conn.Exec(`INSERT INTO users (username, password_hash) VALUES (?, ?)`, username, passwordHash)
+	if isUniqueViolation(err) {
+		return nil, fmt.Errorf("%w: %v", ErrDuplicate, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetUser(int(id))
+}
+
+func isUniqueViolation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+func (db *DB) GetUser(id int) (*User, error) {
+	return db.scanUser(db.conn.QueryRow(`SELECT id, username, password_hash, created_at FROM users WHERE id = ?`, id))
+}
+
+func (db *DB) GetUserByUsername(username string) (*User, error) {
+	return db.scanUser(db.conn.QueryRow(`SELECT id, username, password_hash, created_at FROM users WHERE username = ?`, username))
+}
+
+func (db *DB) scanUser(row *sql.Row) (*User, error) {
+	var user User
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (db *DB) ListUsers() ([]User, error) {
+	rows, err := db.conn.Query(`SELECT id, username, password_hash, created_at FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (db *DB) UpdateUserPassword(id int, passwordHash string) error {
+	_, err := db.conn.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, id)
+	return err
+}
+
+func (db *DB) DeleteUser(id int) error {
+	queries := []string{
+		`DELETE FROM sessions WHERE user_id = ?`,
+		`DELETE FROM api_tokens WHERE user_id = ?`,
+		`DELETE FROM user_roles WHERE user_id = ?`,
+		`DELETE FROM users WHERE id = ?`,
+	}
+
+	for _, query := range queries {
+		if _, err := db.conn.Exec(query, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *DB) CreateSession(id string, userID int, expiresAt time.Time) error {
+	_, err := db.conn.Exec(`INSERT INTO sessions (id, user_id, expires_at) VALUES (?, ?, ?)`, id, userID, expiresAt.Unix())
+	return err
+}
+
+func (db *DB) GetSession(id string) (*Session, error) {
+	var session Session
+	var expiresAt int64
+	err := db.conn.QueryRow(`SELECT id, user_id, expires_at FROM sessions WHERE id = ?`, id).Scan(&session.ID, &session.UserID, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	session.ExpiresAt = time.Unix(expiresAt, 0)
+	return &session, nil
+}
+
+func (db *DB) DeleteSession(id string) error {
+	_, err := db.conn.Exec(`DELETE FROM sessions WHERE id = ?`, id)
+	return err
+}
+
+func (db *DB) DeleteUserSessions(userID int, keep string) error {
+	_, err := db.conn.Exec(`DELETE FROM sessions WHERE user_id = ? AND id != ?`, userID, keep)
+	return err
+}
+
+func (db *DB) DeleteExpiredSessions(now time.Time) error {
+	_, err := db.conn.Exec(`DELETE FROM sessions WHERE expires_at < ?`, now.Unix())
+	return err
+}
+
+func (db *DB) CreateAPIToken(userID int, name, tokenHash, prefix string, expiresAt *time.Time) (*APIToken, error) {
+	var expires interface{}
+	if expiresAt != nil {
+		expires = expiresAt.Unix()
+	}
+
+	result, err := db.conn.Exec(`
+	INSERT INTO api_tokens (user_id, name, token_hash, prefix, expires_at)
+	VALUES (?, ?, ?, ?, ?)
+	`, userID, name, tokenHash, prefix, expires)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.scanAPIToken(db.conn.QueryRow(apiTokenSelect+` WHERE id = ?`, id))
+}
+
+func (db *DB) GetAPITokenByHash(tokenHash string) (*APIToken, error) {
+	return db.scanAPIToken(db.conn.QueryRow(apiTokenSelect+` WHERE token_hash = ?`, tokenHash))
+}
+
+func (db *DB) ListAPITokens(userID int) ([]APIToken, error) {
+	rows, err := db.conn.Query(apiTokenSelect+` WHERE user_id = ? ORDER BY id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []APIToken{}
+	for rows.Next() {
+		token, err := db.scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (db *DB) RevokeAPIToken(id, userID int, now time.Time) error {
+	result, err := db.conn.Exec(`UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`, now.Unix(), id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) TouchAPIToken(id int, now time.Time) error {
+	_, err := db.conn.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, now.Unix(), id)
+	return err
+}
+
+const apiTokenSelect = `SELECT id, user_id, name, prefix, expires_at, revoked_at, last_used_at, created_at FROM api_tokens`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (db *DB) scanAPIToken(row rowScanner) (*APIToken, error) {
+	var token APIToken
+	var expiresAt, revokedAt, lastUsedAt sql.NullInt64
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Prefix, &expiresAt, &revokedAt, &lastUsedAt, &token.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token.ExpiresAt = unixOrNil(expiresAt)
+	token.RevokedAt = unixOrNil(revokedAt)
+	token.LastUsedAt = unixOrNil(lastUsedAt)
+	return &token, nil
+}
+
+func unixOrNil(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.Unix(v.Int64, 0).UTC()
+	return &t
+}
@@ -0,0 +1,107 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+)
+
+type VolumeBackup struct {
+	ID         int    `json:"id"`
+	Host       string `json:"host"`
+	VolumeName string `json:"volume_name"`
+	FileName   string `json:"file_name"`
+	SizeBytes  int64  `json:"size_bytes"`
+	Checksum   string `json:"checksum"`
+	CreatedAt  string `json:"created_at"`
+}
+
+var (
+	ErrNotFound  = errors.New("record not found")
+	ErrDuplicate = errors.New("record already exists")
+)
+
+func (db *DB) CreateVolumeBackup(host, volumeName, fileName string, sizeBytes int64, checksum string) (*VolumeBackup, error) {
+	query := `
+	INSERT INTO volume_backups (host, volume_name, file_name, size_bytes, checksum)
+	VALUES (?, ?, ?, ?, ?)
+	`
+
+	result, err := db.conn.Exec(query, host, volumeName, fileName, sizeBytes, checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetVolumeBackup(int(id))
+}
+
+func (db *DB) GetVolumeBackup(id int) (*VolumeBackup, error) {
+	query := `
+	SELECT id, host, volume_name, file_name, size_bytes, checksum, created_at
+	FROM volume_backups
+	WHERE id = ?
+	`
+
+	var backup VolumeBackup
+	err := db.conn.QueryRow(query, id).Scan(
+		&backup.ID,
+		&backup.Host,
+		&backup.VolumeName,
+		&backup.FileName,
+		&backup.SizeBytes,
+		&backup.Checksum,
+		&backup.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &backup, nil
+}
+
+func (db *DB) ListVolumeBackups(host, volumeName string) ([]VolumeBackup, error) {
+	query := `
+	SELECT id, host, volume_name, file_name, size_bytes, checksum, created_at
+	FROM volume_backups
+	WHERE (? = '' OR host = ?) AND (? = '' OR volume_name = ?)
+	ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := db.conn.Query(query, host, host, volumeName, volumeName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backups := []VolumeBackup{}
+	for rows.Next() {
+		var backup VolumeBackup
+		err := rows.Scan(
+			&backup.ID,
+			&backup.Host,
+			&backup.VolumeName,
+			&backup.FileName,
+			&backup.SizeBytes,
+			&backup.Checksum,
+			&backup.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, backup)
+	}
+
+	return backups, rows.Err()
+}
+
+func (db *DB) DeleteVolumeBackup(id int) error {
+	_, err := db.conn.Exec(`DELETE FROM volume_backups WHERE id = ?`, id)
+	return err
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	chainBatchSize   = 500
+	maxChainAttempts = 10
+
+	HashVersionLegacy = 1
+	HashVersion       = 2
+)
+
+var errChainContended = errors.New("activity log kept changing while appending to the hash chain")
+
+type ChainLink struct {
+	ContainerLog
+	PrevHash    string `json:"prev_hash"`
+	Hash        string `json:"hash"`
+	HashVersion int    `json:"hash_version"`
+}
+
+type AuditCheckpoint struct {
+	ID        int    `json:"id"`
+	LogID     int    `json:"log_id"`
+	Hash      string `json:"hash"`
+	Signature string `json:"signature"`
+	CreatedAt string `json:"created_at"`
+}
+
+func LogHash(version int, prevHash string, entry ContainerLog) string {
+	var fields []string
+	switch version {
+	case HashVersionLegacy:
+	case HashVersion:
+		fields = append(fields, "v2")
+	default:
+		return ""
+	}
+
+	fields = append(fields,
+		prevHash,
+		entry.ContainerID,
+		entry.ContainerName,
+		entry.Action,
+		normalizeTimestamp(entry.Timestamp),
+		entry.UserInfo,
+		entry.TokenName,
+		entry.ClientIP,
+		entry.UserAgent,
+		entry.RequestID,
+		entry.Details,
+	)
+	if version == HashVersion || entry.RemoteAddr != "" {
+		fields = append(fields, entry.RemoteAddr)
+	}
+	content, _ := json.Marshal(fields)
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeTimestamp(value string) string {
+	for _, layout := range []string{timestampLayout, time.RFC3339Nano, "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(timestampLayout)
+		}
+	}
+	return value
+}
+
+func (db *DB) lastLogHash() (string, error) {
+	var hash string
+	err := db.conn.QueryRow(`SELECT COALESCE((SELECT hash FROM container_logs ORDER BY id DESC LIMIT 1), '')`).Scan(&hash)
+	return hash, err
+}
+
+func (db *DB) sealUnhashedLogs() error {
+	db.chainMu.Lock()
+	defer db.chainMu.Unlock()
+
+	sealed := 0
+	for {
+		tx, err := db.conn.Begin()
+		if err != nil {
+			return err
+		}
+
+		var first int
+		err = tx.QueryRow(`SELECT COALESCE(MIN(id), 0) FROM container_logs WHERE hash = ''`).Scan(&first)
+		if err != nil || first == 0 {
+			tx.Rollback()
+			if err == nil && sealed > 0 {
+				log.Printf("Sealed %d existing activity log entries into the hash chain", sealed)
+			}
+			return err
+		}
+
+		var prev string
+		err = tx.QueryRow(`SELECT hash FROM container_logs WHERE id < ? ORDER BY id DESC LIMIT 1`, first).Scan(&prev)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			tx.Rollback()
+			return err
+		}
+
+		links, err := scanChain(tx.Query(chainQuery+` WHERE id >= ? AND hash = '' ORDER BY id ASC LIMIT ?`, first, chainBatchSize))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		for _, link := range links {
+			hash := LogHash(HashVersion, prev, link.ContainerLog)
+			if _, err := tx.Exec(`UPDATE container_logs SET prev_hash = ?, hash = ?, hash_version = ? WHERE id = ?`, prev, hash, HashVersion, link.ID); err != nil {
+				tx.Rollback()
+				return err
+			}
+			prev = hash
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		sealed += len(links)
+	}
+}
+
+const chainQuery = `
+	SELECT id, container_id, container_name, action, timestamp, COALESCE(user_info, ''), token_name, client_ip, remote_addr, user_agent, request_id, COALESCE(details, ''), prev_hash, hash, hash_version
+	FROM container_logs`
+
+func (db *DB) ScanLogChain(after, limit int) ([]ChainLink, error) {
+	return scanChain(db.conn.Query(chainQuery+` WHERE id > ? ORDER BY id ASC LIMIT ?`, after, limit))
+}
+
+func (db *DB) LatestLogLink() (*ChainLink, error) {
+	links, err := scanChain(db.conn.Query(chainQuery + ` ORDER BY id DESC LIMIT 1`))
+	if err != nil || len(links) == 0 {
+		return nil, err
+	}
+	return &links[0], nil
+}
+
+func scanChain(rows *sql.Rows, err error) ([]ChainLink, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []ChainLink
+	for rows.Next() {
+		var link ChainLink
+		err := rows.Scan(
+			&link.ID,
+			&link.ContainerID,
+			&link.ContainerName,
+			&link.Action,
+			&link.Timestamp,
+			&link.UserInfo,
+			&link.TokenName,
+			&link.ClientIP,
+			&link.RemoteAddr,
+			&link.UserAgent,
+			&link.RequestID,
+			&link.Details,
+			&link.PrevHash,
+			&link.Hash,
+			&link.HashVersion,
+		)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func (db *DB) CreateAuditCheckpoint(logID int, hash, signature string) (*AuditCheckpoint, error) {
+	result, err := db.conn.Exec(`INSERT INTO audit_checkpoints (log_id, hash, signature) VALUES (?, ?, ?)`, logID, hash, signature)
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint AuditCheckpoint
+	err = db.conn.QueryRow(`SELECT id, log_id, hash, signature, created_at FROM audit_checkpoints WHERE id = ?`, id).
+		Scan(&checkpoint.ID, &checkpoint.LogID, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+func (db *DB) ListAuditCheckpoints() ([]AuditCheckpoint, error) {
+	rows, err := db.conn.Query(`SELECT id, log_id, hash, signature, created_at FROM audit_checkpoints ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkpoints := []AuditCheckpoint{}
+	for rows.Next() {
+		var checkpoint AuditCheckpoint
+		if err := rows.Scan(&checkpoint.ID, &checkpoint.LogID, &checkpoint.Hash, &checkpoint.Signature, &checkpoint.CreatedAt); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	return checkpoints, rows.Err()
+}
+
+func (db *DB) GetForwardOffset(target string) (int, error) {
+	var lastID int
+	err := db.conn.QueryRow(`SELECT last_id FROM audit_forward_offsets WHERE target = ?`, target).Scan(&lastID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return lastID, err
+}
+
+func (db *DB) SetForwardOffset(target string, lastID int) error {
+	_, err := db.conn.Exec(`
+	INSERT INTO audit_forward_offsets (target, last_id) VALUES (?, ?)
+	ON CONFLICT(target) DO UPDATE SET last_id = excluded.last_id, updated_at = CURRENT_TIMESTAMP
+	`, target, lastID)
+	return err
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestChainAcrossConnections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker-gui.db")
+	var handles []*DB
+	for i := 0; i < 8; i++ {
+		db, err := NewSQLite(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		handles = append(handles, db)
+	}
+
+	const perHandle = 100
+	var wg sync.WaitGroup
+	errs := make(chan error, len(handles)*perHandle)
+	for i, db := range handles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < perHandle; n++ {
+				errs <- db.LogContainerAction(fmt.Sprintf("c%d", i), "web", "start", Actor{User: "alice"}, fmt.Sprint(n))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	links, err := handles[0].ScanLogChain(0, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != len(handles)*perHandle {
+		t.Fatalf("%d entries, want %d", len(links), len(handles)*perHandle)
+	}
+	if valid := chainValid(links); valid != fmt.Sprintf("%d valid", len(links)) {
+		t.Fatal(valid)
+	}
+}
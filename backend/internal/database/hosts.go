@@ -0,0 +1,128 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+type DockerHost struct {
+	Name          string            `json:"name"`
+	Endpoint      string            `json:"endpoint"`
+	TLSCA         string            `json:"tls_ca,omitempty"`
+	TLSCert       string            `json:"tls_cert,omitempty"`
+	TLSKey        string            `json:"tls_key,omitempty"`
+	TLSSkipVerify bool              `json:"tls_skip_verify"`
+	SSHKey        string            `json:"ssh_key,omitempty"`
+	SSHKnownHosts string            `json:"ssh_known_hosts,omitempty"`
+	Labels        map[string]string `json:"labels"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedAt     string            `json:"updated_at"`
+}
+
+const dockerHostSelect = `SELECT name, endpoint, tls_ca, tls_cert, tls_key, tls_skip_verify, ssh_key, ssh_known_hosts, labels, created_at, updated_at FROM docker_hosts`
+
+func (db *DB) CreateDockerHost(host DockerHost) (*DockerHost, error) {
+	labels, err := json.Marshal(host.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.conn.Exec(`
+	INSERT INTO docker_hosts (name, endpoint, tls_ca, tls_cert, tls_key, tls_skip_verify, ssh_key, ssh_known_hosts, labels)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, host.Name, host.Endpoint, host.TLSCA, host.TLSCert, host.TLSKey, host.TLSSkipVerify, host.SSHKey, host.SSHKnownHosts, string(labels))
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetDockerHost(host.Name)
+}
+
+func (db *DB) UpdateDockerHost(host DockerHost) (*DockerHost, error) {
+	labels, err := json.Marshal(host.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := db.conn.Exec(`
+	UPDATE docker_hosts
+	SET endpoint = ?, tls_ca = ?, tls_cert = ?, tls_key = ?, tls_skip_verify = ?, ssh_key = ?, ssh_known_hosts = ?, labels = ?, updated_at = CURRENT_TIMESTAMP
+	WHERE name = ?
+	`, host.Endpoint, host.TLSCA, host.TLSCert, host.TLSKey, host.TLSSkipVerify, host.SSHKey, host.SSHKnownHosts, string(labels), host.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrNotFound
+	}
+
+	return db.GetDockerHost(host.Name)
+}
+
+func (db *DB) GetDockerHost(name string) (*DockerHost, error) {
+	host, err := scanDockerHost(db.conn.QueryRow(dockerHostSelect+` WHERE name = ?`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return host, err
+}
+
+func (db *DB) ListDockerHosts() ([]DockerHost, error) {
+	rows, err := db.conn.Query(dockerHostSelect + ` ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hosts := []DockerHost{}
+	for rows.Next() {
+		host, err := scanDockerHost(rows)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, *host)
+	}
+
+	return hosts, rows.Err()
+}
+
+func (db *DB) DeleteDockerHost(name string) error {
+	result, err := db.conn.Exec(`DELETE FROM docker_hosts WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanDockerHost(row rowScanner) (*DockerHost, error) {
+	var host DockerHost
+	var labels string
+	err := row.Scan(&host.Name, &host.Endpoint, &host.TLSCA, &host.TLSCert, &host.TLSKey, &host.TLSSkipVerify, &host.SSHKey, &host.SSHKnownHosts, &labels, &host.CreatedAt, &host.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(labels), &host.Labels); err != nil {
+		return nil, err
+	}
+	if host.Labels == nil {
+		host.Labels = map[string]string{}
+	}
+
+	return &host, nil
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLogLimit = 100
+	MaxLogLimit     = 1000
+)
+
+type LogFilter struct {
+	ContainerID   string
+	ContainerName string
+	Actions       []string
+	Actor         string
+	Since         time.Time
+	Until         time.Time
+	Search        string
+	Before        int
+	Limit         int
+}
+
+type LogPage struct {
+	Logs       []ContainerLog `json:"logs"`
+	Total      int            `json:"total"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func (db *DB) SearchLogs(filter LogFilter) (*LogPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLogLimit
+	}
+	if limit > MaxLogLimit {
+		limit = MaxLogLimit
+	}
+
+	where, args := filter.conditions()
+
+	page := &LogPage{}
+	countQuery := `SELECT COUNT(*) FROM container_logs` + where
+	if err := db.conn.QueryRow(countQuery, args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
+
+	if filter.Before > 0 {
+		if where == "" {
+			where = " WHERE id < ?"
+		} else {
+			where += " AND id < ?"
+		}
+		args = append(args, filter.Before)
+	}
+
+	logs, err := db.queryLogs(where+" ORDER BY id DESC LIMIT ?", append(args, limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	page.Logs = logs
+
+	if len(page.Logs) > limit {
+		page.Logs = page.Logs[:limit]
+		page.NextCursor = strconv.Itoa(page.Logs[limit-1].ID)
+	}
+
+	return page, nil
+}
+
+func (db *DB) ScanLogs(filter LogFilter, after, limit int) ([]ContainerLog, error) {
+	where, args := filter.conditions()
+	if where == "" {
+		where = " WHERE id > ?"
+	} else {
+		where += " AND id > ?"
+	}
+	return db.queryLogs(where+" ORDER BY id ASC LIMIT ?", append(args, after, limit)...)
+}
+
+func (db *DB) queryLogs(clauses string, args ...interface{}) ([]ContainerLog, error) {
+	query := `
+	SELECT id, container_id, container_name, action, timestamp, user_info, token_name, client_ip, remote_addr, user_agent, request_id, details
+	FROM container_logs` + clauses
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []ContainerLog{}
+	for rows.Next() {
+		var log ContainerLog
+		err := rows.Scan(
+			&log.ID,
+			&log.ContainerID,
+			&log.ContainerName,
+			&log.Action,
+			&log.Timestamp,
+			&log.UserInfo,
+			&log.TokenName,
+			&log.ClientIP,
+			&log.RemoteAddr,
+			&log.UserAgent,
+			&log.RequestID,
+			&log.Details,
+		)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+
+	return logs, rows.Err()
+}
+
+func (f LogFilter) conditions() (string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	if f.ContainerID != "" {
+		clauses = append(clauses, "container_id = ?")
+		args = append(args, f.ContainerID)
+	}
+	if f.ContainerName != "" {
+		clause, arg := matchClause("container_name", f.ContainerName)
+		clauses = append(clauses, clause)
+		args = append(args, arg)
+	}
+	if len(f.Actions) > 0 {
+		var actions []string
+		for _, action := range f.Actions {
+			clause, arg := matchClause("action", action)
+			actions = append(actions, clause)
+			args = append(args, arg)
+		}
+		clauses = append(clauses, "("+strings.Join(actions, " OR ")+")")
+	}
+	if f.Actor != "" {
+		clauses = append(clauses, "(user_info = ? OR token_name = ?)")
+		args = append(args, f.Actor, f.Actor)
+	}
+	if !f.Since.IsZero() {
+		clauses = append(clauses, "timestamp >= ?")
+		args = append(args, f.Since.UTC().Format(timestampLayout))
+	}
+	if !f.Until.IsZero() {
+		clauses = append(clauses, "timestamp <= ?")
+		args = append(args, f.Until.UTC().Format(timestampLayout))
+	}
+	if f.Search != "" {
+		clauses = append(clauses, `details LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(f.Search)+"%")
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+func matchClause(column, pattern string) (string, interface{}) {
+	if !strings.Contains(pattern, "*") {
+		return column + " = ?", pattern
+	}
+	return column + ` LIKE ? ESCAPE '\'`, strings.ReplaceAll(escapeLike(pattern), "*", "%")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,201 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var ErrSchemaNewer = errors.New("database schema is newer than this binary supports")
+
+type Migration struct {
+	Version    int
+	Name       string
+	Statements []string
+}
+
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, rest, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q", name)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", other, name, version)
+		}
+		seen[version] = name
+
+		data, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version:    version,
+			Name:       rest,
+			Statements: splitStatements(string(data)),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be contiguous: expected %d, found %d", i+1, m.Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+func splitStatements(sql string) []string {
+	var statements []string
+	for _, part := range strings.Split(sql, ";") {
+		var lines []string
+		for _, line := range strings.Split(part, "\n") {
+			if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			statements = append(statements, strings.Join(lines, "\n"))
+		}
+	}
+	return statements
+}
+
+func (db *DB) migrate() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	latest := migrations[len(migrations)-1].Version
+
+	if _, err := db.conn.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`); err != nil {
+		return err
+	}
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > latest {
+		return fmt.Errorf("%w: found version %d, latest known is %d; upgrade docker-gui", ErrSchemaNewer, current, latest)
+	}
+
+	if current == 0 {
+		baseline, err := db.legacyBaseline()
+		if err != nil {
+			return err
+		}
+		if baseline > 0 {
+			if err := db.adopt(migrations[:baseline]); err != nil {
+				return err
+			}
+			log.Printf("Adopted existing database schema at version %d", baseline)
+			current = baseline
+		}
+	}
+
+	for _, m := range migrations[current:] {
+		if err := db.apply(m, true); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied database migration %d (%s)", m.Version, m.Name)
+	}
+
+	return nil
+}
+
+func (db *DB) SchemaVersion() (int, error) {
+	var version int
+	err := db.conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version)
+	return version, err
+}
+
+func (db *DB) apply(m Migration, run bool) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if run {
+		for _, statement := range m.Statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, name) VALUES (?, ?)`, m.Version, m.Name); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (db *DB) adopt(migrations []Migration) error {
+	for i, m := range migrations {
+		if err := db.apply(m, i == 0); err != nil {
+			return fmt.Errorf("failed to adopt migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+func (db *DB) legacyBaseline() (int, error) {
+	columns, err := db.tableColumns("container_logs")
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case len(columns) == 0:
+		return 0, nil
+	case columns["request_id"]:
+		return 2, nil
+	default:
+		return 1, nil
+	}
+}
+
+func (db *DB) tableColumns(table string) (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+
+	return columns, rows.Err()
+}
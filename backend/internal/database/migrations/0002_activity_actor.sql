@@ -0,0 +1,6 @@
+ALTER TABLE container_logs ADD COLUMN token_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE container_logs ADD COLUMN client_ip TEXT NOT NULL DEFAULT '';
+ALTER TABLE container_logs ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE container_logs ADD COLUMN request_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_container_logs_user_info ON container_logs(user_info);
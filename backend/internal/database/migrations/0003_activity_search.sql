@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_container_logs_action ON container_logs(action);
+CREATE INDEX IF NOT EXISTS idx_container_logs_container_name ON container_logs(container_name);
+CREATE INDEX IF NOT EXISTS idx_container_logs_token_name ON container_logs(token_name);
@@ -0,0 +1,12 @@
+ALTER TABLE container_logs ADD COLUMN prev_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE container_logs ADD COLUMN hash TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    log_id INTEGER NOT NULL,
+    hash TEXT NOT NULL,
+    signature TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_log_id ON audit_checkpoints(log_id);
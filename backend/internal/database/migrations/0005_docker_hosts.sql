@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS docker_hosts (
+    name TEXT PRIMARY KEY,
+    endpoint TEXT NOT NULL,
+    tls_ca TEXT NOT NULL DEFAULT '',
+    tls_cert TEXT NOT NULL DEFAULT '',
+    tls_key TEXT NOT NULL DEFAULT '',
+    tls_skip_verify INTEGER NOT NULL DEFAULT 0,
+    labels TEXT NOT NULL DEFAULT '{}',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE volume_backups ADD COLUMN host TEXT NOT NULL DEFAULT 'local';
+
+CREATE TABLE stack_revisions_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    host TEXT NOT NULL DEFAULT 'local',
+    project TEXT NOT NULL,
+    revision INTEGER NOT NULL,
+    compose TEXT NOT NULL,
+    action TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(host, project, revision)
+);
+
+INSERT INTO stack_revisions_new (id, project, revision, compose, action, created_at)
+SELECT id, project, revision, compose, action, created_at FROM stack_revisions;
+
+DROP TABLE stack_revisions;
+
+ALTER TABLE stack_revisions_new RENAME TO stack_revisions;
+
+CREATE INDEX IF NOT EXISTS idx_volume_backups_host ON volume_backups(host, volume_name);
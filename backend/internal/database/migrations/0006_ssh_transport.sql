@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS ssh_keys (
+    name TEXT PRIMARY KEY,
+    private_key TEXT NOT NULL,
+    public_key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE docker_hosts ADD COLUMN ssh_key TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE docker_hosts ADD COLUMN ssh_known_hosts TEXT NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE container_logs ADD COLUMN remote_addr TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS audit_forward_offsets (
+    target TEXT PRIMARY KEY,
+    last_id INTEGER NOT NULL,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
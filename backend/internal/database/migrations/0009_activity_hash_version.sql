@@ -0,0 +1 @@
+ALTER TABLE container_logs ADD COLUMN hash_version INTEGER NOT NULL DEFAULT 1;
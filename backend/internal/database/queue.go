@@ -0,0 +1,250 @@
+package database
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	queueKindActivity        = "activity"
+	queueKindContainerMetric = "container_metric"
+	queueKindSystemMetric    = "system_metric"
+)
+
+var (
+	ErrQueueFull = errors.New("persistence queue is full")
+
+	errMalformedEntry = errors.New("malformed queue entry")
+	errRejectedEntry  = errors.New("queue entry rejected by the database")
+)
+
+type queueEntry struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+type diskQueue struct {
+	mu      sync.Mutex
+	path    string
+	max     int
+	count   int
+	dropped int64
+	dead    int64
+}
+
+func openDiskQueue(path string, max int) (*diskQueue, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create queue directory: %w", err)
+		}
+	}
+
+	q := &diskQueue{path: path, max: max}
+	entries, err := q.read()
+	if err != nil {
+		return nil, err
+	}
+	q.count = len(entries)
+
+	return q, nil
+}
+
+func (q *diskQueue) push(kind string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(queueEntry{Kind: kind, Data: encoded})
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.count >= q.max {
+		q.dropped++
+		return ErrQueueFull
+	}
+
+	f, err := os.OpenFile(q.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	q.count++
+	return nil
+}
+
+func (q *diskQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.count
+}
+
+func (q *diskQueue) droppedCount() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
+
+func (q *diskQueue) deadLetterCount() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dead
+}
+
+func (q *diskQueue) drain(apply func(queueEntry) error) (int, error) {
+	q.mu.Lock()
+	entries, err := q.read()
+	q.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	applied, consumed := 0, 0
+	var applyErr error
+	for _, entry := range entries {
+		applyErr = apply(entry)
+		if errors.Is(applyErr, errMalformedEntry) || errors.Is(applyErr, errRejectedEntry) {
+			if applyErr = q.deadLetter(entry, applyErr); applyErr != nil {
+				break
+			}
+			consumed++
+			continue
+		}
+		if applyErr != nil {
+			break
+		}
+		applied++
+		consumed++
+	}
+
+	if consumed > 0 {
+		if err := q.remove(consumed); err != nil {
+			return applied, err
+		}
+	}
+
+	return applied, applyErr
+}
+
+func (q *diskQueue) deadLetter(entry queueEntry, cause error) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	f, err := os.OpenFile(q.path+".dead", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	q.dead++
+	log.Printf("Warning: moved queued %s write to %s.dead: %v", entry.Kind, q.path, cause)
+	return nil
+}
+
+func (q *diskQueue) remove(n int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.read()
+	if err != nil {
+		return err
+	}
+	if n > len(entries) {
+		n = len(entries)
+	}
+	remaining := entries[n:]
+
+	tmp := q.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, entry := range remaining {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		w.Write(append(line, '\n'))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return err
+	}
+
+	q.count = len(remaining)
+	return nil
+}
+
+func (q *diskQueue) read() ([]queueEntry, error) {
+	f, err := os.Open(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []queueEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry queueEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,210 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+type RoleBinding struct {
+	ID         int    `json:"id"`
+	UserID     int    `json:"user_id"`
+	Role       string `json:"role"`
+	ScopeLabel string `json:"scope_label,omitempty"`
+	ScopeName  string `json:"scope_name,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func (db *DB) CreateRole(name, description string, permissions []string) (*Role, error) {
+	encoded, err := json.Marshal(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.conn.Exec(`INSERT INTO roles (name, description, permissions) VALUES (?, ?, ?)`, name, description, string(encoded)); err != nil {
+		return nil, err
+	}
+
+	return db.GetRole(name)
+}
+
+func (db *DB) UpdateRole(name, description string, permissions []string) (*Role, error) {
+	encoded, err := json.Marshal(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := db.conn.Exec(`UPDATE roles SET description = ?, permissions = ? WHERE name = ?`, description, string(encoded), name)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, ErrNotFound
+	}
+
+	return db.GetRole(name)
+}
+
+func (db *DB) GetRole(name string) (*Role, error) {
+	role, err := scanRole(db.conn.QueryRow(`SELECT name, description, permissions, created_at FROM roles WHERE name = ?`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return role, err
+}
+
+func (db *DB) ListRoles() ([]Role, error) {
+	rows, err := db.conn.Query(`SELECT name, description, permissions, created_at FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (db *DB) DeleteRole(name string) error {
+	result, err := db.conn.Exec(`DELETE FROM roles WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanRole(row rowScanner) (*Role, error) {
+	var role Role
+	var description sql.NullString
+	var permissions string
+	if err := row.Scan(&role.Name, &description, &permissions, &role.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	role.Description = description.String
+	if err := json.Unmarshal([]byte(permissions), &role.Permissions); err != nil {
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+const roleBindingSelect = `SELECT id, user_id, role, scope_label, scope_name, created_at FROM user_roles`
+
+func (db *DB) CreateRoleBinding(userID int, role, scopeLabel, scopeName string) (*RoleBinding, error) {
+	result, err := db.conn.Exec(`
+	INSERT INTO user_roles (user_id, role, scope_label, scope_name)
+	VALUES (?, ?, ?, ?)
+	`, userID, role, nullIfEmpty(scopeLabel), nullIfEmpty(scopeName))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	binding, err := scanRoleBinding(db.conn.QueryRow(roleBindingSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return binding, err
+}
+
+func (db *DB) ListRoleBindings(userID int) ([]RoleBinding, error) {
+	return db.queryRoleBindings(roleBindingSelect+` WHERE user_id = ? ORDER BY id`, userID)
+}
+
+func (db *DB) ListRoleBindingsForRole(role string) ([]RoleBinding, error) {
+	return db.queryRoleBindings(roleBindingSelect+` WHERE role = ? ORDER BY id`, role)
+}
+
+func (db *DB) queryRoleBindings(query string, args ...interface{}) ([]RoleBinding, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bindings := []RoleBinding{}
+	for rows.Next() {
+		binding, err := scanRoleBinding(rows)
+		if err != nil {
+			return nil, err
+		}
+		bindings = append(bindings, *binding)
+	}
+
+	return bindings, rows.Err()
+}
+
+func (db *DB) CountRoleBindings() (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM user_roles`).Scan(&count)
+	return count, err
+}
+
+func (db *DB) DeleteRoleBinding(id, userID int) error {
+	result, err := db.conn.Exec(`DELETE FROM user_roles WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func scanRoleBinding(row rowScanner) (*RoleBinding, error) {
+	var binding RoleBinding
+	var scopeLabel, scopeName sql.NullString
+	if err := row.Scan(&binding.ID, &binding.UserID, &binding.Role, &scopeLabel, &scopeName, &binding.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	binding.ScopeLabel = scopeLabel.String
+	binding.ScopeName = scopeName.String
+	return &binding, nil
+}
+
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
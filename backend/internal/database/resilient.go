@@ -0,0 +1,660 @@
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	StateConnected   = "connected"
+	StateUnavailable = "unavailable"
+
+	defaultQueuePath     = "docker-gui-queue.jsonl"
+	defaultQueueSize     = 10000
+	defaultRetryInterval = 5 * time.Second
+	maxRetryInterval     = 2 * time.Minute
+)
+
+var ErrUnavailable = errors.New("database unavailable")
+
+type PersistenceStatus struct {
+	State         string     `json:"state"`
+	Driver        string     `json:"driver"`
+	SchemaVersion int        `json:"schema_version,omitempty"`
+	Queued        int        `json:"queued"`
+	QueueCapacity int        `json:"queue_capacity"`
+	Dropped       int64      `json:"dropped"`
+	DeadLettered  int64      `json:"dead_lettered"`
+	LastError     string     `json:"last_error,omitempty"`
+	Since         time.Time  `json:"since"`
+	LastFlush     *time.Time `json:"last_flush,omitempty"`
+}
+
+type Resilient struct {
+	connect func() (*DB, error)
+	driver  string
+	queue   *diskQueue
+	retry   time.Duration
+
+	mu        sync.RWMutex
+	db        *DB
+	available bool
+	lastErr   error
+	since     time.Time
+	lastFlush *time.Time
+	retrying  bool
+	hooks     []func()
+}
+
+func Open() (*Resilient, error) {
+	driver, connect, err := configFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	queuePath := os.Getenv("PERSISTENCE_QUEUE_PATH")
+	if queuePath == "" {
+		queuePath = defaultQueuePath
+	}
+
+	queueSize := defaultQueueSize
+	if v := os.Getenv("PERSISTENCE_QUEUE_SIZE"); v != "" {
+		queueSize, err = strconv.Atoi(v)
+		if err != nil || queueSize <= 0 {
+			return nil, fmt.Errorf("invalid PERSISTENCE_QUEUE_SIZE %q", v)
+		}
+	}
+
+	retry := defaultRetryInterval
+	if v := os.Getenv("PERSISTENCE_RETRY_INTERVAL"); v != "" {
+		retry, err = time.ParseDuration(v)
+		if err != nil || retry <= 0 {
+			return nil, fmt.Errorf("invalid PERSISTENCE_RETRY_INTERVAL %q", v)
+		}
+	}
+
+	return NewResilient(driver, connect, queuePath, queueSize, retry)
+}
+
+func NewResilient(driver string, connect func() (*DB, error), queuePath string, queueSize int, retry time.Duration) (*Resilient, error) {
+	queue, err := openDiskQueue(queuePath, queueSize)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Resilient{
+		connect: connect,
+		driver:  driver,
+		queue:   queue,
+		retry:   retry,
+		since:   time.Now(),
+	}
+
+	db, err := connect()
+	if errors.Is(err, ErrSchemaNewer) {
+		return nil, err
+	}
+	if err != nil {
+		log.Printf("Warning: database unavailable, starting in degraded mode: %v", err)
+		r.markUnavailable(err)
+		return r, nil
+	}
+
+	r.db = db
+	if flushed, err := r.flush(db); err != nil {
+		log.Printf("Warning: failed to flush persistence queue: %v", err)
+		if db.Ping() != nil {
+			r.markUnavailable(err)
+			return r, nil
+		}
+	} else if flushed > 0 {
+		now := time.Now()
+		r.lastFlush = &now
+	}
+	r.available = true
+
+	return r, nil
+}
+
+func (r *Resilient) OnReconnect(hook func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+func (r *Resilient) Status() PersistenceStatus {
+	r.mu.RLock()
+	status := PersistenceStatus{
+		State:         StateUnavailable,
+		Driver:        r.driver,
+		Queued:        r.queue.len(),
+		QueueCapacity: r.queue.max,
+		Dropped:       r.queue.droppedCount(),
+		DeadLettered:  r.queue.deadLetterCount(),
+		Since:         r.since,
+		LastFlush:     r.lastFlush,
+	}
+	if r.lastErr != nil {
+		status.LastError = r.lastErr.Error()
+	}
+	available, db := r.available, r.db
+	r.mu.RUnlock()
+
+	if available {
+		status.State = StateConnected
+		status.LastError = ""
+		if version, err := db.SchemaVersion(); err == nil {
+			status.SchemaVersion = version
+		}
+	}
+
+	return status
+}
+
+func (r *Resilient) Available() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.available
+}
+
+func (r *Resilient) current() (*DB, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.available {
+		return nil, ErrUnavailable
+	}
+	return r.db, nil
+}
+
+func (r *Resilient) markUnavailable(cause error) {
+	r.mu.Lock()
+	if r.available {
+		r.since = time.Now()
+	}
+	r.available = false
+	r.lastErr = cause
+	start := !r.retrying
+	r.retrying = true
+	r.mu.Unlock()
+
+	if start {
+		go r.reconnectLoop()
+	}
+}
+
+func (r *Resilient) reconnectLoop() {
+	delay := r.retry
+	for {
+		time.Sleep(delay)
+
+		if err := r.tryReconnect(); err != nil {
+			r.mu.Lock()
+			r.lastErr = err
+			r.mu.Unlock()
+
+			delay *= 2
+			if delay > maxRetryInterval {
+				delay = maxRetryInterval
+			}
+			continue
+		}
+
+		log.Println("Database connection restored")
+		return
+	}
+}
+
+func (r *Resilient) tryReconnect() error {
+	r.mu.RLock()
+	db := r.db
+	r.mu.RUnlock()
+
+	if db == nil {
+		connected, err := r.connect()
+		if err != nil {
+			return err
+		}
+		db = connected
+	} else if err := db.Ping(); err != nil {
+		return err
+	}
+
+	flushed, err := r.flush(db)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	more, err := r.flush(db)
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	if flushed+more > 0 {
+		now := time.Now()
+		r.lastFlush = &now
+	}
+	r.db = db
+	r.available = true
+	r.retrying = false
+	r.lastErr = nil
+	r.since = time.Now()
+	hooks := append([]func(){}, r.hooks...)
+	r.mu.Unlock()
+
+	for _, hook := range hooks {
+		hook()
+	}
+
+	return nil
+}
+
+func (r *Resilient) flush(db *DB) (int, error) {
+	if r.queue.len() == 0 {
+		return 0, nil
+	}
+
+	applied, err := r.queue.drain(func(entry queueEntry) error {
+		err := applyQueued(db, entry)
+		if err != nil && !errors.Is(err, errMalformedEntry) && db.Ping() == nil {
+			return fmt.Errorf("%w: %v", errRejectedEntry, err)
+		}
+		return err
+	})
+	if applied > 0 {
+		log.Printf("Flushed %d queued writes to the database", applied)
+	}
+
+	return applied, err
+}
+
+func applyQueued(db *DB, entry queueEntry) error {
+	switch entry.Kind {
+	case queueKindActivity:
+		var record activityRecord
+		if err := json.Unmarshal(entry.Data, &record); err != nil {
+			return fmt.Errorf("%w: %v", errMalformedEntry, err)
+		}
+		return db.insertActivity(record)
+	case queueKindContainerMetric:
+		var record containerMetricRecord
+		if err := json.Unmarshal(entry.Data, &record); err != nil {
+			return fmt.Errorf("%w: %v", errMalformedEntry, err)
+		}
+		return db.insertContainerMetric(record)
+	case queueKindSystemMetric:
+		var record systemMetricRecord
+		if err := json.Unmarshal(entry.Data, &record); err != nil {
+			return fmt.Errorf("%w: %v", errMalformedEntry, err)
+		}
+		return db.insertSystemMetric(record)
+	default:
+		return fmt.Errorf("%w: unknown kind %q", errMalformedEntry, entry.Kind)
+	}
+}
+
+func (r *Resilient) check(db *DB, err error) error {
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if pingErr := db.Ping(); pingErr != nil {
+		r.markUnavailable(err)
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return err
+}
+
+func (r *Resilient) enqueue(kind string, record interface{}, insert func(*DB) error) error {
+	for {
+		r.mu.RLock()
+		if !r.available {
+			err := r.queue.push(kind, record)
+			r.mu.RUnlock()
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrUnavailable, err)
+			}
+			return nil
+		}
+
+		db := r.db
+		if r.queue.len() > 0 {
+			r.mu.RUnlock()
+			if err := r.flushLocked(db); err != nil {
+				if db.Ping() == nil {
+					return err
+				}
+				r.markUnavailable(err)
+			}
+			continue
+		}
+
+		err := insert(db)
+		r.mu.RUnlock()
+		if err == nil || db.Ping() == nil {
+			return err
+		}
+		r.markUnavailable(err)
+	}
+}
+
+func (r *Resilient) flushLocked(db *DB) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	flushed, err := r.flush(db)
+	if flushed > 0 {
+		now := time.Now()
+		r.lastFlush = &now
+	}
+	return err
+}
+
+func query[T any](r *Resilient, fn func(*DB) (T, error)) (T, error) {
+	db, err := r.current()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	result, err := fn(db)
+	return result, r.check(db, err)
+}
+
+func (r *Resilient) exec(fn func(*DB) error) error {
+	db, err := r.current()
+	if err != nil {
+		return err
+	}
+	return r.check(db, fn(db))
+}
+
+func (r *Resilient) LogContainerAction(containerID, containerName, action string, actor Actor, details string) error {
+	record := activityRecord{
+		ContainerID:   containerID,
+		ContainerName: containerName,
+		Action:        action,
+		Actor:         actor,
+		Details:       details,
+		At:            time.Now(),
+	}
+	return r.enqueue(queueKindActivity, record, func(db *DB) error { return db.insertActivity(record) })
+}
+
+func (r *Resilient) StoreContainerMetrics(containerID, containerName string, cpuUsage, memoryUsage, memoryLimit, networkRx, networkTx, diskRead, diskWrite float64) error {
+	record := containerMetricRecord{
+		ContainerID:   containerID,
+		ContainerName: containerName,
+		CPUUsage:      cpuUsage,
+		MemoryUsage:   memoryUsage,
+		MemoryLimit:   memoryLimit,
+		NetworkRx:     networkRx,
+		NetworkTx:     networkTx,
+		DiskRead:      diskRead,
+		DiskWrite:     diskWrite,
+		At:            time.Now(),
+	}
+	return r.enqueue(queueKindContainerMetric, record, func(db *DB) error { return db.insertContainerMetric(record) })
+}
+
+func (r *Resilient) StoreSystemMetrics(totalContainers, runningContainers, stoppedContainers, pausedContainers int, totalCpuUsage, totalMemoryUsage float64) error {
+	record := systemMetricRecord{
+		TotalContainers:   totalContainers,
+		RunningContainers: runningContainers,
+		StoppedContainers: stoppedContainers,
+		PausedContainers:  pausedContainers,
+		TotalCPUUsage:     totalCpuUsage,
+		TotalMemoryUsage:  totalMemoryUsage,
+		At:                time.Now(),
+	}
+	return r.enqueue(queueKindSystemMetric, record, func(db *DB) error { return db.insertSystemMetric(record) })
+}
+
+func (r *Resilient) SearchLogs(filter LogFilter) (*LogPage, error) {
+	return query(r, func(db *DB) (*LogPage, error) { return db.SearchLogs(filter) })
+}
+
+func (r *Resilient) ScanLogs(filter LogFilter, after, limit int) ([]ContainerLog, error) {
+	return query(r, func(db *DB) ([]ContainerLog, error) { return db.ScanLogs(filter, after, limit) })
+}
+
+func (r *Resilient) ScanLogChain(after, limit int) ([]ChainLink, error) {
+	return query(r, func(db *DB) ([]ChainLink, error) { return db.ScanLogChain(after, limit) })
+}
+
+func (r *Resilient) LatestLogLink() (*ChainLink, error) {
+	return query(r, func(db *DB) (*ChainLink, error) { return db.LatestLogLink() })
+}
+
+func (r *Resilient) CreateAuditCheckpoint(logID int, hash, signature string) (*AuditCheckpoint, error) {
+	return query(r, func(db *DB) (*AuditCheckpoint, error) { return db.CreateAuditCheckpoint(logID, hash, signature) })
+}
+
+func (r *Resilient) ListAuditCheckpoints() ([]AuditCheckpoint, error) {
+	return query(r, func(db *DB) ([]AuditCheckpoint, error) { return db.ListAuditCheckpoints() })
+}
+
+func (r *Resilient) GetForwardOffset(target string) (int, error) {
+	return query(r, func(db *DB) (int, error) { return db.GetForwardOffset(target) })
+}
+
+func (r *Resilient) SetForwardOffset(target string, lastID int) error {
+	return r.exec(func(db *DB) error { return db.SetForwardOffset(target, lastID) })
+}
+
+func (r *Resilient) GetContainerMetrics(containerID string, hours int) ([]ContainerMetric, error) {
+	return query(r, func(db *DB) ([]ContainerMetric, error) { return db.GetContainerMetrics(containerID, hours) })
+}
+
+func (r *Resilient) GetSystemMetrics(hours int) ([]SystemMetric, error) {
+	return query(r, func(db *DB) ([]SystemMetric, error) { return db.GetSystemMetrics(hours) })
+}
+
+func (r *Resilient) CreateVolumeBackup(host, volumeName, fileName string, sizeBytes int64, checksum string) (*VolumeBackup, error) {
+	return query(r, func(db *DB) (*VolumeBackup, error) {
+		return db.CreateVolumeBackup(host, volumeName, fileName, sizeBytes, checksum)
+	})
+}
+
+func (r *Resilient) GetVolumeBackup(id int) (*VolumeBackup, error) {
+	return query(r, func(db *DB) (*VolumeBackup, error) { return db.GetVolumeBackup(id) })
+}
+
+func (r *Resilient) ListVolumeBackups(host, volumeName string) ([]VolumeBackup, error) {
+	return query(r, func(db *DB) ([]VolumeBackup, error) { return db.ListVolumeBackups(host, volumeName) })
+}
+
+func (r *Resilient) DeleteVolumeBackup(id int) error {
+	return r.exec(func(db *DB) error { return db.DeleteVolumeBackup(id) })
+}
+
+func (r *Resilient) CreateStackRevision(host, project, compose, action string) (*StackRevision, error) {
+	return query(r, func(db *DB) (*StackRevision, error) { return db.CreateStackRevision(host, project, compose, action) })
+}
+
+func (r *Resilient) GetStackRevision(host, project string, revision int) (*StackRevision, error) {
+	return query(r, func(db *DB) (*StackRevision, error) { return db.GetStackRevision(host, project, revision) })
+}
+
+func (r *Resilient) ListStackRevisions(host, project string) ([]StackRevision, error) {
+	return query(r, func(db *DB) ([]StackRevision, error) { return db.ListStackRevisions(host, project) })
+}
+
+func (r *Resilient) LatestStackRevision(host, project string) (*StackRevision, error) {
+	return query(r, func(db *DB) (*StackRevision, error) { return db.LatestStackRevision(host, project) })
+}
+
+func (r *Resilient) CreateDockerHost(host DockerHost) (*DockerHost, error) {
+	return query(r, func(db *DB) (*DockerHost, error) { return db.CreateDockerHost(host) })
+}
+
+func (r *Resilient) UpdateDockerHost(host DockerHost) (*DockerHost, error) {
+	return query(r, func(db *DB) (*DockerHost, error) { return db.UpdateDockerHost(host) })
+}
+
+func (r *Resilient) GetDockerHost(name string) (*DockerHost, error) {
+	return query(r, func(db *DB) (*DockerHost, error) { return db.GetDockerHost(name) })
+}
+
+func (r *Resilient) ListDockerHosts() ([]DockerHost, error) {
+	return query(r, func(db *DB) ([]DockerHost, error) { return db.ListDockerHosts() })
+}
+
+func (r *Resilient) DeleteDockerHost(name string) error {
+	return r.exec(func(db *DB) error { return db.DeleteDockerHost(name) })
+}
+
+func (r *Resilient) CreateSSHKey(key SSHKey) (*SSHKey, error) {
+	return query(r, func(db *DB) (*SSHKey, error) { return db.CreateSSHKey(key) })
+}
+
+func (r *Resilient) GetSSHKey(name string) (*SSHKey, error) {
+	return query(r, func(db *DB) (*SSHKey, error) { return db.GetSSHKey(name) })
+}
+
+func (r *Resilient) ListSSHKeys() ([]SSHKey, error) {
+	return query(r, func(db *DB) ([]SSHKey, error) { return db.ListSSHKeys() })
+}
+
+func (r *Resilient) UpdateSSHPrivateKey(name, privateKey string) error {
+	return r.exec(func(db *DB) error { return db.UpdateSSHPrivateKey(name, privateKey) })
+}
+
+func (r *Resilient) DeleteSSHKey(name string) error {
+	return r.exec(func(db *DB) error { return db.DeleteSSHKey(name) })
+}
+
+func (r *Resilient) CountUsers() (int, error) {
+	return query(r, func(db *DB) (int, error) { return db.CountUsers() })
+}
+
+func (r *Resilient) CreateUser(username, passwordHash string) (*User, error) {
+	return query(r, func(db *DB) (*User, error) { return db.CreateUser(username, passwordHash) })
+}
+
+func (r *Resilient) GetUser(id int) (*User, error) {
+	return query(r, func(db *DB) (*User, error) { return db.GetUser(id) })
+}
+
+func (r *Resilient) GetUserByUsername(username string) (*User, error) {
+	return query(r, func(db *DB) (*User, error) { return db.GetUserByUsername(username) })
+}
+
+func (r *Resilient) ListUsers() ([]User, error) {
+	return query(r, func(db *DB) ([]User, error) { return db.ListUsers() })
+}
+
+func (r *Resilient) UpdateUserPassword(id int, passwordHash string) error {
+	return r.exec(func(db *DB) error { return db.UpdateUserPassword(id, passwordHash) })
+}
+
+func (r *Resilient) DeleteUser(id int) error {
+	return r.exec(func(db *DB) error { return db.DeleteUser(id) })
+}
+
+func (r *Resilient) CreateSession(id string, userID int, expiresAt time.Time) error {
+	return r.exec(func(db *DB) error { return db.CreateSession(id, userID, expiresAt) })
+}
+
+func (r *Resilient) GetSession(id string) (*Session, error) {
+	return query(r, func(db *DB) (*Session, error) { return db.GetSession(id) })
+}
+
+func (r *Resilient) DeleteSession(id string) error {
+	return r.exec(func(db *DB) error { return db.DeleteSession(id) })
+}
+
+func (r *Resilient) DeleteUserSessions(userID int, keep string) error {
+	return r.exec(func(db *DB) error { return db.DeleteUserSessions(userID, keep) })
+}
+
+func (r *Resilient) DeleteExpiredSessions(now time.Time) error {
+	return r.exec(func(db *DB) error { return db.DeleteExpiredSessions(now) })
+}
+
+func (r *Resilient) CreateAPIToken(userID int, name, tokenHash, prefix string, expiresAt *time.Time) (*APIToken, error) {
+	return query(r, func(db *DB) (*APIToken, error) {
+		return db.CreateAPIToken(userID, name, tokenHash, prefix, expiresAt)
+	})
+}
+
+func (r *Resilient) GetAPITokenByHash(tokenHash string) (*APIToken, error) {
+	return query(r, func(db *DB) (*APIToken, error) { return db.GetAPITokenByHash(tokenHash) })
+}
+
+func (r *Resilient) ListAPITokens(userID int) ([]APIToken, error) {
+	return query(r, func(db *DB) ([]APIToken, error) { return db.ListAPITokens(userID) })
+}
+
+func (r *Resilient) RevokeAPIToken(id, userID int, now time.Time) error {
+	return r.exec(func(db *DB) error { return db.RevokeAPIToken(id, userID, now) })
+}
+
+func (r *Resilient) TouchAPIToken(id int, now time.Time) error {
+	return r.exec(func(db *DB) error { return db.TouchAPIToken(id, now) })
+}
+
+func (r *Resilient) CreateRole(name, description string, permissions []string) (*Role, error) {
+	return query(r, func(db *DB) (*Role, error) { return db.CreateRole(name, description, permissions) })
+}
+
+func (r *Resilient) UpdateRole(name, description string, permissions []string) (*Role, error) {
+	return query(r, func(db *DB) (*Role, error) { return db.UpdateRole(name, description, permissions) })
+}
+
+func (r *Resilient) GetRole(name string) (*Role, error) {
+	return query(r, func(db *DB) (*Role, error) { return db.GetRole(name) })
+}
+
+func (r *Resilient) ListRoles() ([]Role, error) {
+	return query(r, func(db *DB) ([]Role, error) { return db.ListRoles() })
+}
+
+func (r *Resilient) DeleteRole(name string) error {
+	return r.exec(func(db *DB) error { return db.DeleteRole(name) })
+}
+
+func (r *Resilient) CreateRoleBinding(userID int, role, scopeLabel, scopeName string) (*RoleBinding, error) {
+	return query(r, func(db *DB) (*RoleBinding, error) {
+		return db.CreateRoleBinding(userID, role, scopeLabel, scopeName)
+	})
+}
+
+func (r *Resilient) ListRoleBindings(userID int) ([]RoleBinding, error) {
+	return query(r, func(db *DB) ([]RoleBinding, error) { return db.ListRoleBindings(userID) })
+}
+
+func (r *Resilient) ListRoleBindingsForRole(role string) ([]RoleBinding, error) {
+	return query(r, func(db *DB) ([]RoleBinding, error) { return db.ListRoleBindingsForRole(role) })
+}
+
+func (r *Resilient) CountRoleBindings() (int, error) {
+	return query(r, func(db *DB) (int, error) { return db.CountRoleBindings() })
+}
+
+func (r *Resilient) DeleteRoleBinding(id, userID int) error {
+	return r.exec(func(db *DB) error { return db.DeleteRoleBinding(id, userID) })
+}
+
+func (r *Resilient) SchemaVersion() (int, error) {
+	return query(r, func(db *DB) (int, error) { return db.SchemaVersion() })
+}
+
+func (r *Resilient) Driver() string {
+	return r.driver
+}
+
+func (r *Resilient) Close() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.db == nil {
+		return nil
+	}
+	return r.db.Close()
+}
+
+var _ Store = (*Resilient)(nil)
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFlushDeadLettersMalformedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	queue, err := openDiskQueue(path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, action := range []string{"start", "stop"} {
+		if err := queue.push(queueKindActivity, activityRecord{ContainerID: "c1", Action: action, At: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	malformed := []byte(`{"kind":"activity","data":{"at":"yesterday"}}` + "\n" + `{"kind":"mystery","data":{}}` + "\n")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(malformed)
+	f.Close()
+	if err := queue.push(queueKindActivity, activityRecord{ContainerID: "c1", Action: "restart", At: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewResilient(DriverMemory, NewMemory, path, 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	status := r.Status()
+	if status.State != StateConnected || status.Queued != 0 || status.DeadLettered != 2 {
+		t.Fatalf("status = %+v, want connected with an empty queue and 2 dead-lettered entries", status)
+	}
+
+	page, err := r.SearchLogs(LogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 3 {
+		t.Errorf("%d activity entries flushed, want 3", page.Total)
+	}
+
+	dead, err := os.ReadFile(path + ".dead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Count(dead, []byte("\n")) != 2 || !bytes.Contains(dead, []byte("yesterday")) || !bytes.Contains(dead, []byte("mystery")) {
+		t.Errorf("dead letter file:\n%s", dead)
+	}
+}
+
+func TestFlushDeadLettersRejectedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.jsonl")
+	down := true
+	connect := func() (*DB, error) {
+		if down {
+			return nil, errors.New("connection refused")
+		}
+		db, err := NewMemory()
+		if err != nil {
+			return nil, err
+		}
+		_, err = db.conn.Exec(`CREATE TRIGGER reject_poison BEFORE INSERT ON container_metrics
+			WHEN NEW.container_id = 'poison' BEGIN SELECT RAISE(ABORT, 'constraint failed'); END`)
+		return db, err
+	}
+
+	r, err := NewResilient(DriverMemory, connect, path, 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if err := r.StoreContainerMetrics("poison", "poison", 1, 1, 1, 0, 0, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.LogContainerAction("c1", "web", "start", Actor{User: "alice"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if queued := r.Status().Queued; queued != 2 {
+		t.Fatalf("%d writes queued while unavailable, want 2", queued)
+	}
+
+	down = false
+	if err := r.tryReconnect(); err != nil {
+		t.Fatalf("reconnect with a rejected entry queued: %v", err)
+	}
+
+	status := r.Status()
+	if status.State != StateConnected || status.Queued != 0 || status.DeadLettered != 1 {
+		t.Fatalf("status = %+v, want connected with an empty queue and 1 dead-lettered entry", status)
+	}
+	page, err := r.SearchLogs(LogFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 {
+		t.Errorf("%d activity entries flushed, want 1", page.Total)
+	}
+	if err := r.StoreContainerMetrics("c1", "web", 1, 1, 1, 0, 0, 0, 0); err != nil {
+		t.Errorf("write after reconnecting: %v", err)
+	}
+	if err := r.StoreContainerMetrics("poison", "poison", 1, 1, 1, 0, 0, 0, 0); err == nil || !r.Available() {
+		t.Errorf("rejected direct write returned %v and left the store available=%v", err, r.Available())
+	}
+}
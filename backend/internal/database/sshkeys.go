@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+)
+
+type SSHKey struct {
+	Name        string `json:"name"`
+	PrivateKey  string `json:"private_key,omitempty"`
+	PublicKey   string `json:"public_key"`
+	Fingerprint string `json:"fingerprint"`
+	CreatedAt   string `json:"created_at"`
+}
+
+func (db *DB) CreateSSHKey(key SSHKey) (*SSHKey, error) {
+	_, err := db.conn.Exec(`
+	INSERT INTO ssh_keys (name, private_key, public_key, fingerprint)
+	VALUES (?, ?, ?, ?)
+	`, key.Name, key.PrivateKey, key.PublicKey, key.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.GetSSHKey(key.Name)
+}
+
+func (db *DB) GetSSHKey(name string) (*SSHKey, error) {
+	var key SSHKey
+	err := db.conn.QueryRow(`SELECT name, private_key, public_key, fingerprint, created_at FROM ssh_keys WHERE name = ?`, name).
+		Scan(&key.Name, &key.PrivateKey, &key.PublicKey, &key.Fingerprint, &key.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (db *DB) ListSSHKeys() ([]SSHKey, error) {
+	rows, err := db.conn.Query(`SELECT name, public_key, fingerprint, created_at FROM ssh_keys ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []SSHKey{}
+	for rows.Next() {
+		var key SSHKey
+		if err := rows.Scan(&key.Name, &key.PublicKey, &key.Fingerprint, &key.CreatedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (db *DB) UpdateSSHPrivateKey(name, privateKey string) error {
+	result, err := db.conn.Exec(`UPDATE ssh_keys SET private_key = ? WHERE name = ?`, privateKey, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteSSHKey(name string) error {
+	result, err := db.conn.Exec(`DELETE FROM ssh_keys WHERE name = ?`, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+)
+
+type StackRevision struct {
+	ID        int    `json:"id"`
+	Host      string `json:"host"`
+	Project   string `json:"project"`
+	Revision  int    `json:"revision"`
+	Compose   string `json:"compose,omitempty"`
+	Action    string `json:"action"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (db *DB) CreateStackRevision(host, project, compose, action string) (*StackRevision, error) {
+	query := `
+	INSERT INTO stack_revisions (host, project, revision, compose, action)
+	SELECT ?, ?, COALESCE(MAX(revision), 0) + 1, ?, ?
+	FROM stack_revisions
+	WHERE host = ? AND project = ?
+	`
+
+	if _, err := db.conn.Exec(query, host, project, compose, action, host, project); err != nil {
+		return nil, err
+	}
+
+	return db.LatestStackRevision(host, project)
+}
+
+func (db *DB) GetStackRevision(host, project string, revision int) (*StackRevision, error) {
+	query := `
+	SELECT id, host, project, revision, compose, action, created_at
+	FROM stack_revisions
+	WHERE host = ? AND project = ? AND (? = 0 OR revision = ?)
+	ORDER BY revision DESC
+	LIMIT 1
+	`
+
+	var rev StackRevision
+	err := db.conn.QueryRow(query, host, project, revision, revision).Scan(
+		&rev.ID,
+		&rev.Host,
+		&rev.Project,
+		&rev.Revision,
+		&rev.Compose,
+		&rev.Action,
+		&rev.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &rev, nil
+}
+
+func (db *DB) ListStackRevisions(host, project string) ([]StackRevision, error) {
+	query := `
+	SELECT id, host, project, revision, action, created_at
+	FROM stack_revisions
+	WHERE host = ? AND project = ?
+	ORDER BY revision DESC
+	`
+
+	rows, err := db.conn.Query(query, host, project)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []StackRevision{}
+	for rows.Next() {
+		var rev StackRevision
+		err := rows.Scan(
+			&rev.ID,
+			&rev.Host,
+			&rev.Project,
+			&rev.Revision,
+			&rev.Action,
+			&rev.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+func (db *DB) LatestStackRevision(host, project string) (*StackRevision, error) {
+	return db.GetStackRevision(host, project, 0)
+}
@@ -0,0 +1,79 @@
+package database
+
+import "time"
+
+type Store interface {
+	LogContainerAction(containerID, containerName, action string, actor Actor, details string) error
+	SearchLogs(filter LogFilter) (*LogPage, error)
+	ScanLogs(filter LogFilter, after, limit int) ([]ContainerLog, error)
+	ScanLogChain(after, limit int) ([]ChainLink, error)
+	LatestLogLink() (*ChainLink, error)
+	CreateAuditCheckpoint(logID int, hash, signature string) (*AuditCheckpoint, error)
+	ListAuditCheckpoints() ([]AuditCheckpoint, error)
+	GetForwardOffset(target string) (int, error)
+	SetForwardOffset(target string, lastID int) error
+
+	StoreContainerMetrics(containerID, containerName string, cpuUsage, memoryUsage, memoryLimit, networkRx, networkTx, diskRead, diskWrite float64) error
+	StoreSystemMetrics(totalContainers, runningContainers, stoppedContainers, pausedContainers int, totalCpuUsage, totalMemoryUsage float64) error
+	GetContainerMetrics(containerID string, hours int) ([]ContainerMetric, error)
+	GetSystemMetrics(hours int) ([]SystemMetric, error)
+
+	CreateVolumeBackup(host, volumeName, fileName string, sizeBytes int64, checksum string) (*VolumeBackup, error)
+	GetVolumeBackup(id int) (*VolumeBackup, error)
+	ListVolumeBackups(host, volumeName string) ([]VolumeBackup, error)
+	DeleteVolumeBackup(id int) error
+
+	CreateStackRevision(host, project, compose, action string) (*StackRevision, error)
+	GetStackRevision(host, project string, revision int) (*StackRevision, error)
+	ListStackRevisions(host, project string) ([]StackRevision, error)
+	LatestStackRevision(host, project string) (*StackRevision, error)
+
+	CreateDockerHost(host DockerHost) (*DockerHost, error)
+	UpdateDockerHost(host DockerHost) (*DockerHost, error)
+	GetDockerHost(name string) (*DockerHost, error)
+	ListDockerHosts() ([]DockerHost, error)
+	DeleteDockerHost(name string) error
+
+	CreateSSHKey(key SSHKey) (*SSHKey, error)
+	GetSSHKey(name string) (*SSHKey, error)
+	ListSSHKeys() ([]SSHKey, error)
+	UpdateSSHPrivateKey(name, privateKey string) error
+	DeleteSSHKey(name string) error
+
+	CountUsers() (int, error)
+	CreateUser(username, passwordHash string) (*User, error)
+	GetUser(id int) (*User, error)
+	GetUserByUsername(username string) (*User, error)
+	ListUsers() ([]User, error)
+	UpdateUserPassword(id int, passwordHash string) error
+	DeleteUser(id int) error
+
+	CreateSession(id string, userID int, expiresAt time.Time) error
+	GetSession(id string) (*Session, error)
+	DeleteSession(id string) error
+	DeleteUserSessions(userID int, keep string) error
+	DeleteExpiredSessions(now time.Time) error
+
+	CreateAPIToken(userID int, name, tokenHash, prefix string, expiresAt *time.Time) (*APIToken, error)
+	GetAPITokenByHash(tokenHash string) (*APIToken, error)
+	ListAPITokens(userID int) ([]APIToken, error)
+	RevokeAPIToken(id, userID int, now time.Time) error
+	TouchAPIToken(id int, now time.Time) error
+
+	CreateRole(name, description string, permissions []string) (*Role, error)
+	UpdateRole(name, description string, permissions []string) (*Role, error)
+	GetRole(name string) (*Role, error)
+	ListRoles() ([]Role, error)
+	DeleteRole(name string) error
+	CreateRoleBinding(userID int, role, scopeLabel, scopeName string) (*RoleBinding, error)
+	ListRoleBindings(userID int) ([]RoleBinding, error)
+	ListRoleBindingsForRole(role string) ([]RoleBinding, error)
+	CountRoleBindings() (int, error)
+	DeleteRoleBinding(id, userID int) error
+
+	SchemaVersion() (int, error)
+	Driver() string
+	Close() error
+}
+
+var _ Store = (*DB)(nil)
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type backend struct {
+	name string
+	open func(t *testing.T) *DB
+}
+
+func backends() []backend {
+	list := []backend{
+		{DriverMemory, func(t *testing.T) *DB {
+			db, err := NewMemory()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return db
+		}},
+		{DriverSQLite, func(t *testing.T) *DB {
+			db, err := NewSQLite(filepath.Join(t.TempDir(), "docker-gui.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return db
+		}},
+	}
+
+	if url, token := os.Getenv("TURSO_TEST_DATABASE_URL"), os.Getenv("TURSO_TEST_AUTH_TOKEN"); url != "" && token != "" {
+		list = append(list, backend{DriverTurso, func(t *testing.T) *DB {
+			db, err := NewTurso(url, token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return db
+		}})
+	}
+	return list
+}
+
+func TestStoreConformance(t *testing.T) {
+	var reference string
+	var referenceSchema, referenceTranscript []string
+
+	for _, b := range backends() {
+		t.Run(b.name, func(t *testing.T) {
+			db := b.open(t)
+			t.Cleanup(func() { db.Close() })
+
+			if db.Driver() != b.name {
+				t.Errorf("driver %q, want %q", db.Driver(), b.name)
+			}
+			schema := schemaOf(t, db)
+			transcript := exercise(t, db)
+
+			if reference == "" {
+				reference, referenceSchema, referenceTranscript = b.name, schema, transcript
+				return
+			}
+			if !reflect.DeepEqual(schema, referenceSchema) {
+				t.Errorf("schema differs from %s:\n%s\nwant:\n%s", reference, strings.Join(schema, "\n"), strings.Join(referenceSchema, "\n"))
+			}
+			for i := range transcript {
+				if i >= len(referenceTranscript) || transcript[i] != referenceTranscript[i] {
+					t.Errorf("behaviour differs from %s at step %d: %s", reference, i, transcript[i])
+					break
+				}
+			}
+			if len(transcript) != len(referenceTranscript) {
+				t.Errorf("%d steps, %s ran %d", len(transcript), reference, len(referenceTranscript))
+			}
+		})
+	}
+}
+
+func schemaOf(t *testing.T, db *DB) []string {
+	t.Helper()
+
+	rows, err := db.conn.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name NOT LIKE '\_%' ESCAPE '\' ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+
+	var schema []string
+	for _, table := range tables {
+		rows, err := db.conn.Query(`SELECT name, type, "notnull", COALESCE(dflt_value, ''), pk FROM pragma_table_info(?) ORDER BY cid`, table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			var name, kind, def string
+			var notNull, pk int
+			if err := rows.Scan(&name, &kind, &notNull, &def, &pk); err != nil {
+				t.Fatal(err)
+			}
+			schema = append(schema, fmt.Sprintf("%s.%s %s notnull=%d default=%s pk=%d", table, name, kind, notNull, def, pk))
+		}
+		rows.Close()
+	}
+	return schema
+}
+
+func exercise(t *testing.T, db *DB) []string {
+	t.Helper()
+
+	var transcript []string
+	step := func(name string, value any, err error) {
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		transcript = append(transcript, fmt.Sprintf("%s: %v", name, value))
+	}
+
+	version, err := db.SchemaVersion()
+	step("schema version", version, err)
+
+	alice, err := db.CreateUser("alice", "hash-a")
+	step("create user", alice.Username, err)
+	_, err = db.CreateUser("alice", "hash-b")
+	step("duplicate user rejected", errors.Is(err, ErrDuplicate), nil)
+	_, err = db.GetUser(alice.ID + 100)
+	step("missing user", errors.Is(err, ErrNotFound), nil)
+	bob, _ := db.CreateUser("bob", "hash-b")
+	users, err := db.ListUsers()
+	step("list users", usernames(users), err)
+
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	step("create session", nil, db.CreateSession("s1", alice.ID, expires))
+	session, err := db.GetSession("s1")
+	step("session expiry", session.ExpiresAt.Equal(expires), err)
+	step("create other session", nil, db.CreateSession("s2", alice.ID, expires))
+	step("delete other sessions", nil, db.DeleteUserSessions(alice.ID, "s1"))
+	_, err = db.GetSession("s2")
+	step("other session gone", errors.Is(err, ErrNotFound), nil)
+	_, err = db.GetSession("s1")
+	step("kept session", err == nil, nil)
+	step("expire sessions", nil, db.DeleteExpiredSessions(expires.Add(time.Second)))
+	_, err = db.GetSession("s1")
+	step("expired session gone", errors.Is(err, ErrNotFound), nil)
+
+	token, err := db.CreateAPIToken(alice.ID, "ci", "token-hash", "dg_abc", nil)
+	step("create token", token.Name+" expires="+fmt.Sprint(token.ExpiresAt), err)
+	step("revoke token", nil, db.RevokeAPIToken(token.ID, alice.ID, time.Now()))
+	step("revoke twice", errors.Is(db.RevokeAPIToken(token.ID, alice.ID, time.Now()), ErrNotFound), nil)
+	token, err = db.GetAPITokenByHash("token-hash")
+	step("token revoked", token.RevokedAt != nil, err)
+
+	role, err := db.CreateRole("auditor", "Reads logs", []string{"logs:read"})
+	step("create role", role.Permissions, err)
+	_, err = db.UpdateRole("nobody", "", []string{"logs:read"})
+	step("update missing role", errors.Is(err, ErrNotFound), nil)
+	binding, err := db.CreateRoleBinding(bob.ID, "auditor", "team=payments", "")
+	step("create binding", binding.Role+" "+binding.ScopeLabel, err)
+	count, err := db.CountRoleBindings()
+	step("count bindings", count, err)
+	step("delete user", nil, db.DeleteUser(bob.ID))
+	bindings, err := db.ListRoleBindingsForRole("auditor")
+	step("bindings removed with user", len(bindings), err)
+
+	for i, action := range []string{"start", "stop", "login_failed", "start"} {
+		err := db.LogContainerAction(fmt.Sprintf("c%d", i%2), "web", action, Actor{User: "alice", ClientIP: "10.0.0.1"}, "details "+action)
+		step("log "+action, nil, err)
+	}
+	page, err := db.SearchLogs(LogFilter{Actions: []string{"st*"}, Limit: 2})
+	step("search logs", fmt.Sprintf("total=%d ids=%v next=%s", page.Total, logIDs(page.Logs), page.NextCursor), err)
+	page, err = db.SearchLogs(LogFilter{Search: "login", Actor: "alice"})
+	step("search text", logIDs(page.Logs), err)
+	scanned, err := db.ScanLogs(LogFilter{ContainerID: "c0"}, 0, 10)
+	step("scan logs", logIDs(scanned), err)
+	chain, err := db.ScanLogChain(0, 10)
+	step("chain links", chainValid(chain), err)
+	_, err = db.GetForwardOffset("tcp://siem:514")
+	step("missing forward offset", errors.Is(err, ErrNotFound), nil)
+	for _, id := range []int{2, 4} {
+		step("save forward offset", id, db.SetForwardOffset("tcp://siem:514", id))
+	}
+	offset, err := db.GetForwardOffset("tcp://siem:514")
+	step("forward offset", offset, err)
+
+	backup, err := db.CreateVolumeBackup("local", "data", "data.tar.gz", 42, "sum")
+	step("create backup", backup.FileName, err)
+	backups, err := db.ListVolumeBackups("local", "")
+	step("list backups", len(backups), err)
+	step("delete backup", nil, db.DeleteVolumeBackup(backup.ID))
+	step("delete backup twice", errors.Is(db.DeleteVolumeBackup(backup.ID), ErrNotFound), nil)
+
+	for _, compose := range []string{"v1", "v2"} {
+		_, err := db.CreateStackRevision("local", "shop", compose, "deploy")
+		step("create revision", compose, err)
+	}
+	latest, err := db.LatestStackRevision("local", "shop")
+	step("latest revision", fmt.Sprintf("%d %s", latest.Revision, latest.Compose), err)
+
+	host, err := db.CreateDockerHost(DockerHost{Name: "edge", Endpoint: "tcp://edge:2376", Labels: map[string]string{"env": "prod"}})
+	step("create host", host.Labels, err)
+	_, err = db.GetDockerHost("nowhere")
+	step("missing host", errors.Is(err, ErrNotFound), nil)
+
+	return transcript
+}
+
+func usernames(users []User) []string {
+	var names []string
+	for _, user := range users {
+		names = append(names, user.Username)
+	}
+	return names
+}
+
+func logIDs(logs []ContainerLog) []int {
+	var ids []int
+	for _, log := range logs {
+		ids = append(ids, log.ID)
+	}
+	return ids
+}
+
+func chainValid(links []ChainLink) string {
+	prev := ""
+	for _, link := range links {
+		if link.PrevHash != prev || LogHash(link.HashVersion, prev, link.ContainerLog) != link.Hash {
+			return fmt.Sprintf("broken at %d", link.ID)
+		}
+		prev = link.Hash
+	}
+	return fmt.Sprintf("%d valid", len(links))
+}
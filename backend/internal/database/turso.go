@@ -5,19 +5,64 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 
 	_ "github.com/tursodatabase/libsql-client-go/libsql"
 )
 
 type DB struct {
-	conn *sql.DB
+	conn    *sql.DB
+	driver  string
+	chainMu sync.Mutex
 }
 
+const (
+	DriverTurso  = "turso"
+	DriverSQLite = "sqlite"
+	DriverMemory = "memory"
+
+	defaultSQLitePath = "docker-gui.db"
+)
+
 func NewDatabase() (*DB, error) {
-	dbURL := os.Getenv("TURSO_DATABASE_URL")
-	authToken := os.Getenv("TURSO_AUTH_TOKEN")
+	_, connect, err := configFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return connect()
+}
 
+func configFromEnv() (string, func() (*DB, error), error) {
+	driver := os.Getenv("DATABASE_DRIVER")
+	if driver == "" {
+		driver = DriverSQLite
+		if os.Getenv("TURSO_DATABASE_URL") != "" {
+			driver = DriverTurso
+		}
+	}
+
+	switch driver {
+	case DriverTurso:
+		dbURL, authToken := os.Getenv("TURSO_DATABASE_URL"), os.Getenv("TURSO_AUTH_TOKEN")
+		if dbURL == "" || authToken == "" {
+			return "", nil, fmt.Errorf("TURSO_DATABASE_URL and TURSO_AUTH_TOKEN environment variables are required")
+		}
+		return driver, func() (*DB, error) { return NewTurso(dbURL, authToken) }, nil
+	case DriverSQLite:
+		path := os.Getenv("SQLITE_PATH")
+		if path == "" {
+			path = defaultSQLitePath
+		}
+		return driver, func() (*DB, error) { return NewSQLite(path) }, nil
+	case DriverMemory:
+		return driver, NewMemory, nil
+	default:
+		return "", nil, fmt.Errorf("unknown DATABASE_DRIVER %q (expected %s, %s or %s)", driver, DriverTurso, DriverSQLite, DriverMemory)
+	}
+}
+
+func NewTurso(dbURL, authToken string) (*DB, error) {
 	if dbURL == "" || authToken == "" {
 		return nil, fmt.Errorf("TURSO_DATABASE_URL and TURSO_AUTH_TOKEN environment variables are required")
 	}
@@ -35,166 +80,178 @@ func NewDatabase() (*DB, error) {
 
 	log.Println("Successfully connected to Turso database")
 
-	database := &DB{conn: db}
+	return open(DriverTurso, db)
+}
+
+func open(driver string, conn *sql.DB) (*DB, error) {
+	database := &DB{conn: conn, driver: driver}
+
+	if err := database.migrate(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
 
-	if err := database.createTables(); err != nil {
-		return nil, fmt.Errorf("failed to create tables: %w", err)
+	if err := database.sealUnhashedLogs(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to seal activity log: %w", err)
 	}
 
 	return database, nil
 }
 
-func (db *DB) createTables() error {
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS container_logs (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			container_id TEXT NOT NULL,
-			container_name TEXT NOT NULL,
-			action TEXT NOT NULL,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
-			user_info TEXT,
-			details TEXT
-		)`,
-		`CREATE TABLE IF NOT EXISTS container_metrics (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			container_id TEXT NOT NULL,
-			container_name TEXT NOT NULL,
-			cpu_usage REAL,
-			memory_usage REAL,
-			memory_limit REAL,
-			network_rx REAL,
-			network_tx REAL,
-			disk_read REAL,
-			disk_write REAL,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS system_metrics (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			total_containers INTEGER,
-			running_containers INTEGER,
-			stopped_containers INTEGER,
-			paused_containers INTEGER,
-			total_cpu_usage REAL,
-			total_memory_usage REAL,
-			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_container_logs_container_id ON container_logs(container_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_container_logs_timestamp ON container_logs(timestamp)`,
-		`CREATE INDEX IF NOT EXISTS idx_container_metrics_container_id ON container_metrics(container_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_container_metrics_timestamp ON container_metrics(timestamp)`,
-		`CREATE INDEX IF NOT EXISTS idx_system_metrics_timestamp ON system_metrics(timestamp)`,
-	}
+func (db *DB) Driver() string {
+	return db.driver
+}
 
-	for _, query := range queries {
-		if _, err := db.conn.Exec(query); err != nil {
-			return err
-		}
-	}
+func (db *DB) Ping() error {
+	var one int
+	return db.conn.QueryRow(`SELECT 1`).Scan(&one)
+}
 
-	return nil
+type Actor struct {
+	User       string `json:"user"`
+	TokenName  string `json:"token_name,omitempty"`
+	ClientIP   string `json:"client_ip,omitempty"`
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
 }
 
-func (db *DB) LogContainerAction(containerID, containerName, action, userInfo, details string) error {
-	query := `
-	INSERT INTO container_logs (container_id, container_name, action, user_info, details)
-	VALUES (?, ?, ?, ?, ?)
-	`
+const timestampLayout = "2006-01-02 15:04:05"
 
-	_, err := db.conn.Exec(query, containerID, containerName, action, userInfo, details)
-	return err
+type activityRecord struct {
+	ContainerID   string    `json:"container_id"`
+	ContainerName string    `json:"container_name"`
+	Action        string    `json:"action"`
+	Actor         Actor     `json:"actor"`
+	Details       string    `json:"details"`
+	At            time.Time `json:"at"`
 }
 
-func (db *DB) StoreContainerMetrics(containerID, containerName string, cpuUsage, memoryUsage, memoryLimit, networkRx, networkTx, diskRead, diskWrite float64) error {
-	query := `
-	INSERT INTO container_metrics 
-	(container_id, container_name, cpu_usage, memory_usage, memory_limit, network_rx, network_tx, disk_read, disk_write)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-
-	_, err := db.conn.Exec(query, containerID, containerName, cpuUsage, memoryUsage, memoryLimit, networkRx, networkTx, diskRead, diskWrite)
-	return err
+type containerMetricRecord struct {
+	ContainerID   string    `json:"container_id"`
+	ContainerName string    `json:"container_name"`
+	CPUUsage      float64   `json:"cpu_usage"`
+	MemoryUsage   float64   `json:"memory_usage"`
+	MemoryLimit   float64   `json:"memory_limit"`
+	NetworkRx     float64   `json:"network_rx"`
+	NetworkTx     float64   `json:"network_tx"`
+	DiskRead      float64   `json:"disk_read"`
+	DiskWrite     float64   `json:"disk_write"`
+	At            time.Time `json:"at"`
 }
 
-func (db *DB) StoreSystemMetrics(totalContainers, runningContainers, stoppedContainers, pausedContainers int, totalCpuUsage, totalMemoryUsage float64) error {
-	query := `
-	INSERT INTO system_metrics 
-	(total_containers, running_containers, stopped_containers, paused_containers, total_cpu_usage, total_memory_usage)
-	VALUES (?, ?, ?, ?, ?, ?)
-	`
+type systemMetricRecord struct {
+	TotalContainers   int       `json:"total_containers"`
+	RunningContainers int       `json:"running_containers"`
+	StoppedContainers int       `json:"stopped_containers"`
+	PausedContainers  int       `json:"paused_containers"`
+	TotalCPUUsage     float64   `json:"total_cpu_usage"`
+	TotalMemoryUsage  float64   `json:"total_memory_usage"`
+	At                time.Time `json:"at"`
+}
 
-	_, err := db.conn.Exec(query, totalContainers, runningContainers, stoppedContainers, pausedContainers, totalCpuUsage, totalMemoryUsage)
-	return err
+func (db *DB) LogContainerAction(containerID, containerName, action string, actor Actor, details string) error {
+	return db.insertActivity(activityRecord{
+		ContainerID:   containerID,
+		ContainerName: containerName,
+		Action:        action,
+		Actor:         actor,
+		Details:       details,
+		At:            time.Now(),
+	})
 }
 
-func (db *DB) GetContainerLogs(containerID string, limit int) ([]ContainerLog, error) {
+func (db *DB) insertActivity(r activityRecord) error {
+	entry := ContainerLog{
+		ContainerID:   r.ContainerID,
+		ContainerName: r.ContainerName,
+		Action:        r.Action,
+		Timestamp:     r.At.UTC().Format(timestampLayout),
+		UserInfo:      r.Actor.User,
+		TokenName:     r.Actor.TokenName,
+		ClientIP:      r.Actor.ClientIP,
+		RemoteAddr:    r.Actor.RemoteAddr,
+		UserAgent:     r.Actor.UserAgent,
+		RequestID:     r.Actor.RequestID,
+		Details:       r.Details,
+	}
+
+	db.chainMu.Lock()
+	defer db.chainMu.Unlock()
+
 	query := `
-	SELECT id, container_id, container_name, action, timestamp, user_info, details
-	FROM container_logs
-	WHERE container_id = ?
-	ORDER BY timestamp DESC
-	LIMIT ?
+	INSERT INTO container_logs (container_id, container_name, action, timestamp, user_info, token_name, client_ip, remote_addr, user_agent, request_id, details, prev_hash, hash, hash_version)
+	SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+	WHERE COALESCE((SELECT hash FROM container_logs ORDER BY id DESC LIMIT 1), '') = ?
 	`
 
-	rows, err := db.conn.Query(query, containerID, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	for attempt := 0; attempt < maxChainAttempts; attempt++ {
+		prevHash, err := db.lastLogHash()
+		if err != nil {
+			return err
+		}
 
-	var logs []ContainerLog
-	for rows.Next() {
-		var log ContainerLog
-		err := rows.Scan(
-			&log.ID,
-			&log.ContainerID,
-			&log.ContainerName,
-			&log.Action,
-			&log.Timestamp,
-			&log.UserInfo,
-			&log.Details,
-		)
+		result, err := db.conn.Exec(query, entry.ContainerID, entry.ContainerName, entry.Action, entry.Timestamp,
+			entry.UserInfo, entry.TokenName, entry.ClientIP, entry.RemoteAddr, entry.UserAgent, entry.RequestID, entry.Details, prevHash, LogHash(HashVersion, prevHash, entry), HashVersion, prevHash)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if inserted, err := result.RowsAffected(); err != nil || inserted == 1 {
+			return err
 		}
-		logs = append(logs, log)
 	}
 
-	return logs, nil
+	return errChainContended
 }
 
-func (db *DB) GetAllLogs(limit int) ([]ContainerLog, error) {
+func (db *DB) StoreContainerMetrics(containerID, containerName string, cpuUsage, memoryUsage, memoryLimit, networkRx, networkTx, diskRead, diskWrite float64) error {
+	return db.insertContainerMetric(containerMetricRecord{
+		ContainerID:   containerID,
+		ContainerName: containerName,
+		CPUUsage:      cpuUsage,
+		MemoryUsage:   memoryUsage,
+		MemoryLimit:   memoryLimit,
+		NetworkRx:     networkRx,
+		NetworkTx:     networkTx,
+		DiskRead:      diskRead,
+		DiskWrite:     diskWrite,
+		At:            time.Now(),
+	})
+}
+
+func (db *DB) insertContainerMetric(r containerMetricRecord) error {
 	query := `
-	SELECT id, container_id, container_name, action, timestamp, user_info, details
-	FROM container_logs
-	ORDER BY timestamp DESC
-	LIMIT ?
+	INSERT INTO container_metrics 
+	(container_id, container_name, cpu_usage, memory_usage, memory_limit, network_rx, network_tx, disk_read, disk_write, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	rows, err := db.conn.Query(query, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	_, err := db.conn.Exec(query, r.ContainerID, r.ContainerName, r.CPUUsage, r.MemoryUsage, r.MemoryLimit, r.NetworkRx, r.NetworkTx, r.DiskRead, r.DiskWrite, r.At.UTC().Format(timestampLayout))
+	return err
+}
 
-	var logs []ContainerLog
-	for rows.Next() {
-		var log ContainerLog
-		err := rows.Scan(
-			&log.ID,
-			&log.ContainerID,
-			&log.ContainerName,
-			&log.Action,
-			&log.Timestamp,
-			&log.UserInfo,
-			&log.Details,
-		)
-		if err != nil {
-			return nil, err
-		}
-		logs = append(logs, log)
-	}
+func (db *DB) StoreSystemMetrics(totalContainers, runningContainers, stoppedContainers, pausedContainers int, totalCpuUsage, totalMemoryUsage float64) error {
+	return db.insertSystemMetric(systemMetricRecord{
+		TotalContainers:   totalContainers,
+		RunningContainers: runningContainers,
+		StoppedContainers: stoppedContainers,
+		PausedContainers:  pausedContainers,
+		TotalCPUUsage:     totalCpuUsage,
+		TotalMemoryUsage:  totalMemoryUsage,
+		At:                time.Now(),
+	})
+}
+
+func (db *DB) insertSystemMetric(r systemMetricRecord) error {
+	query := `
+	INSERT INTO system_metrics 
+	(total_containers, running_containers, stopped_containers, paused_containers, total_cpu_usage, total_memory_usage, timestamp)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
 
-	return logs, nil
+	_, err := db.conn.Exec(query, r.TotalContainers, r.RunningContainers, r.StoppedContainers, r.PausedContainers, r.TotalCPUUsage, r.TotalMemoryUsage, r.At.UTC().Format(timestampLayout))
+	return err
 }
 
 func (db *DB) GetContainerMetrics(containerID string, hours int) ([]ContainerMetric, error) {
@@ -279,6 +336,11 @@ type ContainerLog struct {
 	Action        string `json:"action"`
 	Timestamp     string `json:"timestamp"`
 	UserInfo      string `json:"user_info"`
+	TokenName     string `json:"token_name"`
+	ClientIP      string `json:"client_ip"`
+	RemoteAddr    string `json:"remote_addr"`
+	UserAgent     string `json:"user_agent"`
+	RequestID     string `json:"request_id"`
 	Details       string `json:"details"`
 }
 
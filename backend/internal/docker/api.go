@@ -0,0 +1,61 @@
+package docker
+
+import (
+	"context"
+	"io"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+type API interface {
+	Info(ctx context.Context) (*models.HostStatus, error)
+	Close() error
+
+	ListContainers(ctx context.Context, all bool) ([]models.Container, error)
+	CreateContainer(ctx context.Context, name string, config *container.Config, hostConfig *container.HostConfig, networking *network.NetworkingConfig) (string, error)
+	ContainerState(ctx context.Context, containerID string) (*types.ContainerState, error)
+	StartContainer(ctx context.Context, containerID string) error
+	StopContainer(ctx context.Context, containerID string) error
+	RestartContainer(ctx context.Context, containerID string) error
+	RemoveContainer(ctx context.Context, containerID string, force bool) error
+	RenameContainer(ctx context.Context, containerID, name string) error
+	GetContainerLogs(ctx context.Context, containerID string, opts LogOptions) ([]models.LogEntry, error)
+	GetContainerStats(ctx context.Context, containerID string) (*models.ContainerStats, error)
+	ContainerTop(ctx context.Context, containerID string, psArgs []string) (*models.ProcessList, error)
+	GetContainerResources(ctx context.Context, containerID string) (*models.ContainerResources, error)
+	UpdateContainerResources(ctx context.Context, containerID string, req models.UpdateResourcesRequest) ([]string, error)
+
+	ListDirectory(ctx context.Context, containerID, dir string) ([]models.FileEntry, error)
+	CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, *container.PathStat, error)
+	UploadFiles(ctx context.Context, containerID, destDir string, files []UploadFile, opts UploadOptions) error
+	ContainerChanges(ctx context.Context, containerID string) ([]models.FileChange, error)
+
+	ListImages(ctx context.Context) ([]models.Image, error)
+	ImageExists(ctx context.Context, imageName string) (bool, error)
+	PullImage(ctx context.Context, imageName string) error
+	RemoveImage(ctx context.Context, imageID string, force bool) error
+	PruneImages(ctx context.Context) error
+	RunHelper(ctx context.Context, opts HelperOptions) error
+
+	ListNetworks(ctx context.Context) ([]models.NetworkResource, error)
+	InspectNetwork(ctx context.Context, networkID string) (*models.NetworkResource, error)
+	NetworkExists(ctx context.Context, name string) (bool, error)
+	CreateNetwork(ctx context.Context, req models.CreateNetworkRequest) (*models.NetworkResource, error)
+	RemoveNetwork(ctx context.Context, networkID string) error
+	PruneNetworks(ctx context.Context) (*models.PruneNetworksResult, error)
+	ConnectNetwork(ctx context.Context, networkID string, req models.NetworkConnectRequest) error
+	DisconnectNetwork(ctx context.Context, networkID string, req models.NetworkDisconnectRequest) error
+
+	ListVolumes(ctx context.Context) ([]models.Volume, error)
+	InspectVolume(ctx context.Context, name string) (*models.Volume, error)
+	VolumeExists(ctx context.Context, name string) (bool, error)
+	CreateVolume(ctx context.Context, req models.CreateVolumeRequest) (*models.Volume, error)
+	RemoveVolume(ctx context.Context, name string, force bool) error
+	PruneVolumes(ctx context.Context, all bool) (*models.PruneVolumesResult, error)
+}
+
+var _ API = (*Client)(nil)
@@ -2,10 +2,13 @@ package docker
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
-	"strings"
+	"net/http"
+	"sort"
 	"time"
 
 	"docker-gui-backend/pkg/models"
@@ -19,6 +22,14 @@ import (
 
 type Client struct {
 	cli *client.Client
+	ssh *sshTransport
+}
+
+type TLSMaterial struct {
+	CA         string
+	Cert       string
+	Key        string
+	SkipVerify bool
 }
 
 func NewClient() (*Client, error) {
@@ -29,6 +40,70 @@ func NewClient() (*Client, error) {
 	return &Client{cli: cli}, nil
 }
 
+func NewRemoteClient(endpoint string, material *TLSMaterial) (*Client, error) {
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+
+	if material != nil {
+		tlsConfig, err := material.config()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithHTTPClient(&http.Client{
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				TLSClientConfig:     tlsConfig,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		}))
+	}
+	opts = append(opts, client.WithHost(endpoint))
+
+	cli, err := client.NewClientWithOpts(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cli: cli}, nil
+}
+
+func (m *TLSMaterial) config() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: m.SkipVerify}
+
+	if m.CA != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(m.CA)) {
+			return nil, fmt.Errorf("TLS CA is not a valid PEM certificate")
+		}
+		config.RootCAs = pool
+	}
+
+	if m.Cert != "" || m.Key != "" {
+		cert, err := tls.X509KeyPair([]byte(m.Cert), []byte(m.Key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+func (c *Client) Info(ctx context.Context) (*models.HostStatus, error) {
+	info, err := c.cli.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.HostStatus{
+		Online:        true,
+		ServerVersion: info.ServerVersion,
+		APIVersion:    c.cli.ClientVersion(),
+		OS:            info.OperatingSystem,
+		Arch:          info.Architecture,
+		Containers:    info.Containers,
+		Images:        info.Images,
+	}, nil
+}
+
 func (c *Client) ListContainers(ctx context.Context, all bool) ([]models.Container, error) {
 	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: all})
 	if err != nil {
@@ -61,18 +136,39 @@ func (c *Client) ListContainers(ctx context.Context, all bool) ([]models.Contain
 			}
 		}
 
+		var networks []models.ContainerNetwork
+		if container.NetworkSettings != nil {
+			for name, endpoint := range container.NetworkSettings.Networks {
+				if endpoint == nil {
+					continue
+				}
+				networks = append(networks, models.ContainerNetwork{
+					Name:        name,
+					NetworkID:   endpoint.NetworkID,
+					IPAddress:   endpoint.IPAddress,
+					IPv6Address: endpoint.GlobalIPv6Address,
+					MacAddress:  endpoint.MacAddress,
+					Aliases:     endpoint.Aliases,
+				})
+			}
+			sort.Slice(networks, func(i, j int) bool {
+				return networks[i].Name < networks[j].Name
+			})
+		}
+
 		result = append(result, models.Container{
-			ID:      container.ID,
-			Names:   container.Names,
-			Image:   container.Image,
-			ImageID: container.ImageID,
-			Command: container.Command,
-			Created: container.Created,
-			Ports:   ports,
-			Labels:  container.Labels,
-			State:   container.State,
-			Status:  container.Status,
-			Mounts:  mounts,
+			ID:       container.ID,
+			Names:    container.Names,
+			Image:    container.Image,
+			ImageID:  container.ImageID,
+			Command:  container.Command,
+			Created:  container.Created,
+			Ports:    ports,
+			Labels:   container.Labels,
+			State:    container.State,
+			Status:   container.Status,
+			Mounts:   mounts,
+			Networks: networks,
 		})
 	}
 
@@ -97,81 +193,8 @@ func (c *Client) RemoveContainer(ctx context.Context, containerID string, force
 	return c.cli.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: force})
 }
 
-func (c *Client) GetContainerLogs(ctx context.Context, containerID string, lines int) ([]models.LogEntry, error) {
-	options := container.LogsOptions{
-		ShowStdout: true,
-		ShowStderr: true,
-		Timestamps: true,
-		Tail:       fmt.Sprintf("%d", lines),
-	}
-
-	logs, err := c.cli.ContainerLogs(ctx, containerID, options)
-	if err != nil {
-		return nil, err
-	}
-	defer logs.Close()
-
-	content, err := io.ReadAll(logs)
-	if err != nil {
-		return nil, err
-	}
-
-	var logEntries []models.LogEntry
-	lines_str := strings.Split(string(content), "\n")
-	
-	for _, line := range lines_str {
-		if len(line) == 0 {
-			continue
-		}
-		
-		var stream string
-		var message string
-		
-		if len(line) >= 8 {
-			switch line[0] {
-			case 1:
-				stream = "stdout"
-			case 2:
-				stream = "stderr"
-			default:
-				stream = "stdout"
-			}
-			message = line[8:]
-		} else {
-			stream = "stdout"
-			message = line
-		}
-		
-		var timestamp time.Time
-		if strings.Contains(message, "T") && (strings.Contains(message, "Z") || strings.Contains(message, "+")) {
-			parts := strings.SplitN(message, " ", 2)
-			if len(parts) == 2 {
-				if parsedTime, err := time.Parse(time.RFC3339Nano, parts[0]); err == nil {
-					timestamp = parsedTime
-					message = parts[1]
-				} else if parsedTime, err := time.Parse(time.RFC3339, parts[0]); err == nil {
-					timestamp = parsedTime
-					message = parts[1]
-				} else {
-					timestamp = time.Now()
-				}
-			} else {
-				timestamp = time.Now()
-			}
-		} else {
-			timestamp = time.Now()
-		}
-		
-		if strings.TrimSpace(message) != "" {
-			logEntries = append(logEntries, models.LogEntry{
-				Timestamp: timestamp,
-				Message:   strings.TrimSpace(message),
-				Stream:    stream,
-			})
-		}
-	}
-
-	return logEntries, nil
+func (c *Client) RenameContainer(ctx context.Context, containerID, name string) error {
+	return c.cli.ContainerRename(ctx, containerID, name)
 }
 
 func (c *Client) GetContainerStats(ctx context.Context, containerID string) (*models.ContainerStats, error) {
@@ -241,6 +264,9 @@ func calculateCPUPercent(stats *types.StatsJSON) float64 {
 }
 
 func (c *Client) Close() error {
+	if c.ssh != nil {
+		c.ssh.Close()
+	}
 	return c.cli.Close()
 }
 
@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+func (c *Client) CreateContainer(ctx context.Context, name string, config *container.Config, hostConfig *container.HostConfig, networking *network.NetworkingConfig) (string, error) {
+	created, err := c.cli.ContainerCreate(ctx, config, hostConfig, networking, nil, name)
+	if err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (c *Client) ContainerState(ctx context.Context, containerID string) (*types.ContainerState, error) {
+	inspect, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	if inspect.State == nil {
+		return &types.ContainerState{}, nil
+	}
+	return inspect.State, nil
+}
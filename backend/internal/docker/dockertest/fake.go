@@ -0,0 +1,621 @@
+package dockertest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/errdefs"
+)
+
+type Container struct {
+	ID        string
+	Name      string
+	Image     string
+	Command   string
+	Labels    map[string]string
+	State     string
+	ExitCode  int
+	Health    string
+	Ports     []models.Port
+	Mounts    []models.Mount
+	Networks  []string
+	Logs      []models.LogEntry
+	Stats     models.ContainerStats
+	Processes []map[string]string
+	Resources models.ContainerResources
+	Files     map[string]string
+	Links     map[string]string
+
+	created time.Time
+	files   map[string]*file
+	changes []models.FileChange
+}
+
+type Event struct {
+	Type   string
+	Action string
+	ID     string
+	Name   string
+	Time   time.Time
+}
+
+type Fake struct {
+	mu         sync.Mutex
+	containers []*Container
+	images     []*image
+	networks   []*fakeNetwork
+	volumes    []*fakeVolume
+	events     []Event
+	failures   map[string]error
+}
+
+var _ docker.API = (*Fake)(nil)
+
+func New() *Fake {
+	f := &Fake{failures: make(map[string]error)}
+	for _, name := range []string{"bridge", "host", "none"} {
+		driver := name
+		if name == "none" {
+			driver = "null"
+		}
+		f.networks = append(f.networks, &fakeNetwork{
+			NetworkResource: models.NetworkResource{
+				ID:      newID(),
+				Name:    name,
+				Driver:  driver,
+				Scope:   "local",
+				Created: time.Now().UTC(),
+			},
+			builtin: true,
+		})
+	}
+	return f
+}
+
+func (f *Fake) AddContainer(c Container) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	added := c
+	if added.ID == "" {
+		added.ID = newID()
+	}
+	if added.Name == "" {
+		added.Name = added.ID[:12]
+	}
+	added.Name = strings.TrimPrefix(added.Name, "/")
+	if added.State == "" {
+		added.State = "running"
+	}
+	if added.Image != "" && f.findImage(added.Image) == nil {
+		f.images = append(f.images, newImage(added.Image, 0))
+	}
+	if added.Labels == nil {
+		added.Labels = map[string]string{}
+	}
+	if len(added.Networks) == 0 {
+		added.Networks = []string{"bridge"}
+	}
+	for _, name := range added.Networks {
+		if n := f.findNetwork(name); n != nil {
+			n.attach(&added, nil)
+		}
+	}
+	added.created = time.Now().UTC()
+	added.files = newFileTree(added.Files, added.Links)
+
+	f.containers = append(f.containers, &added)
+	return added.ID
+}
+
+func (f *Fake) Container(idOrName string) (Container, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c := f.findContainer(idOrName)
+	if c == nil {
+		return Container{}, false
+	}
+	return *c, true
+}
+
+func (f *Fake) Events() []Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Event(nil), f.events...)
+}
+
+func (f *Fake) Fail(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.failures, method)
+		return
+	}
+	f.failures[method] = err
+}
+
+func (f *Fake) fail(method string) error {
+	return f.failures[method]
+}
+
+func (f *Fake) emit(kind, action, id, name string) {
+	f.events = append(f.events, Event{Type: kind, Action: action, ID: id, Name: name, Time: time.Now().UTC()})
+}
+
+func (f *Fake) Info(ctx context.Context) (*models.HostStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("Info"); err != nil {
+		return nil, err
+	}
+	return &models.HostStatus{
+		Online:        true,
+		ServerVersion: "fake",
+		APIVersion:    "1.45",
+		OS:            "Fake Docker",
+		Arch:          "x86_64",
+		Containers:    len(f.containers),
+		Images:        len(f.images),
+	}, nil
+}
+
+func (f *Fake) Close() error {
+	return nil
+}
+
+func (f *Fake) ListContainers(ctx context.Context, all bool) ([]models.Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("ListContainers"); err != nil {
+		return nil, err
+	}
+
+	var result []models.Container
+	for _, c := range f.containers {
+		if !all && c.State != "running" {
+			continue
+		}
+		result = append(result, f.toModel(c))
+	}
+	return result, nil
+}
+
+func (f *Fake) toModel(c *Container) models.Container {
+	imageID := ""
+	if img := f.findImage(c.Image); img != nil {
+		imageID = img.ID
+	}
+
+	var networks []models.ContainerNetwork
+	for _, name := range c.Networks {
+		n := f.findNetwork(name)
+		if n == nil {
+			continue
+		}
+		endpoint := n.endpoint(c.ID)
+		networks = append(networks, models.ContainerNetwork{
+			Name:       n.Name,
+			NetworkID:  n.ID,
+			IPAddress:  strings.Split(endpoint.IPv4Address, "/")[0],
+			MacAddress: endpoint.MacAddress,
+			Aliases:    n.aliases[c.ID],
+		})
+	}
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+
+	return models.Container{
+		ID:       c.ID,
+		Names:    []string{"/" + c.Name},
+		Image:    c.Image,
+		ImageID:  imageID,
+		Command:  c.Command,
+		Created:  c.created.Unix(),
+		Ports:    append([]models.Port{}, c.Ports...),
+		Labels:   c.Labels,
+		State:    c.State,
+		Status:   status(c),
+		Mounts:   append([]models.Mount{}, c.Mounts...),
+		Networks: networks,
+	}
+}
+
+func status(c *Container) string {
+	switch c.State {
+	case "running":
+		return "Up"
+	case "exited":
+		return fmt.Sprintf("Exited (%d)", c.ExitCode)
+	default:
+		return strings.ToUpper(c.State[:1]) + c.State[1:]
+	}
+}
+
+func (f *Fake) findContainer(idOrName string) *Container {
+	name := strings.TrimPrefix(idOrName, "/")
+	for _, c := range f.containers {
+		if c.ID == idOrName {
+			return c
+		}
+	}
+	for _, c := range f.containers {
+		if c.Name == name {
+			return c
+		}
+	}
+	var match *Container
+	if len(idOrName) >= 3 {
+		for _, c := range f.containers {
+			if strings.HasPrefix(c.ID, idOrName) {
+				if match != nil {
+					return nil
+				}
+				match = c
+			}
+		}
+	}
+	return match
+}
+
+func (f *Fake) container(method, idOrName string) (*Container, error) {
+	if err := f.fail(method); err != nil {
+		return nil, err
+	}
+	c := f.findContainer(idOrName)
+	if c == nil {
+		return nil, errdefs.NotFound(fmt.Errorf("No such container: %s", idOrName))
+	}
+	return c, nil
+}
+
+func (f *Fake) CreateContainer(ctx context.Context, name string, config *container.Config, hostConfig *container.HostConfig, networking *network.NetworkingConfig) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("CreateContainer"); err != nil {
+		return "", err
+	}
+	if name != "" && f.findContainer(name) != nil {
+		return "", errdefs.Conflict(fmt.Errorf("Conflict. The container name \"/%s\" is already in use", name))
+	}
+	if f.findImage(config.Image) == nil {
+		return "", errdefs.NotFound(fmt.Errorf("No such image: %s", config.Image))
+	}
+
+	c := &Container{
+		ID:      newID(),
+		Name:    name,
+		Image:   config.Image,
+		Command: strings.Join(append(append([]string{}, config.Entrypoint...), config.Cmd...), " "),
+		Labels:  map[string]string{},
+		State:   "created",
+		created: time.Now().UTC(),
+		files:   newFileTree(nil, nil),
+	}
+	if c.Name == "" {
+		c.Name = c.ID[:12]
+	}
+	for k, v := range config.Labels {
+		c.Labels[k] = v
+	}
+
+	if hostConfig != nil {
+		c.Resources = models.ContainerResources{
+			CPUShares: hostConfig.CPUShares,
+			NanoCPUs:  hostConfig.NanoCPUs,
+			Memory:    hostConfig.Memory,
+			RestartPolicy: models.RestartPolicy{
+				Name:              string(hostConfig.RestartPolicy.Name),
+				MaximumRetryCount: hostConfig.RestartPolicy.MaximumRetryCount,
+			},
+		}
+		for port, bindings := range hostConfig.PortBindings {
+			for _, binding := range bindings {
+				public, _ := strconv.Atoi(binding.HostPort)
+				c.Ports = append(c.Ports, models.Port{IP: binding.HostIP, PrivatePort: uint16(port.Int()), PublicPort: uint16(public), Type: port.Proto()})
+			}
+		}
+		sort.Slice(c.Ports, func(i, j int) bool { return c.Ports[i].PublicPort < c.Ports[j].PublicPort })
+		for _, m := range hostConfig.Mounts {
+			c.Mounts = append(c.Mounts, models.Mount{
+				Type:        string(m.Type),
+				Name:        m.Source,
+				Source:      m.Source,
+				Destination: m.Target,
+				RW:          !m.ReadOnly,
+			})
+		}
+	}
+
+	var networks []string
+	if networking != nil {
+		for name := range networking.EndpointsConfig {
+			networks = append(networks, name)
+		}
+		sort.Strings(networks)
+	}
+	if len(networks) == 0 {
+		mode := "bridge"
+		if hostConfig != nil && hostConfig.NetworkMode != "" && hostConfig.NetworkMode != "default" {
+			mode = string(hostConfig.NetworkMode)
+		}
+		networks = []string{mode}
+	}
+	for _, name := range networks {
+		n := f.findNetwork(name)
+		if n == nil {
+			return "", errdefs.NotFound(fmt.Errorf("network %s not found", name))
+		}
+		var aliases []string
+		if networking != nil && networking.EndpointsConfig[name] != nil {
+			aliases = networking.EndpointsConfig[name].Aliases
+		}
+		n.attach(c, aliases)
+		c.Networks = append(c.Networks, n.Name)
+	}
+
+	f.containers = append(f.containers, c)
+	f.emit("container", "create", c.ID, c.Name)
+	return c.ID, nil
+}
+
+func (f *Fake) ContainerState(ctx context.Context, containerID string) (*types.ContainerState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("ContainerState", containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &types.ContainerState{
+		Status:   c.State,
+		Running:  c.State == "running",
+		ExitCode: c.ExitCode,
+	}
+	if c.Health != "" {
+		state.Health = &types.Health{Status: c.Health}
+	}
+	return state, nil
+}
+
+func (f *Fake) StartContainer(ctx context.Context, containerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("StartContainer", containerID)
+	if err != nil || c.State == "running" {
+		return err
+	}
+	if port, other := f.portInUse(c); other != nil {
+		return fmt.Errorf("driver failed programming external connectivity on endpoint %s: Bind for 0.0.0.0:%d failed: port is already allocated", c.Name, port)
+	}
+	c.State = "running"
+	c.ExitCode = 0
+	f.emit("container", "start", c.ID, c.Name)
+	return nil
+}
+
+func (f *Fake) portInUse(c *Container) (uint16, *Container) {
+	for _, port := range c.Ports {
+		if port.PublicPort == 0 {
+			continue
+		}
+		for _, other := range f.containers {
+			if other == c || other.State != "running" {
+				continue
+			}
+			for _, used := range other.Ports {
+				if used.PublicPort == port.PublicPort && used.Type == port.Type {
+					return port.PublicPort, other
+				}
+			}
+		}
+	}
+	return 0, nil
+}
+
+func (f *Fake) StopContainer(ctx context.Context, containerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("StopContainer", containerID)
+	if err != nil || c.State != "running" {
+		return err
+	}
+	c.State = "exited"
+	f.emit("container", "stop", c.ID, c.Name)
+	return nil
+}
+
+func (f *Fake) RestartContainer(ctx context.Context, containerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("RestartContainer", containerID)
+	if err != nil {
+		return err
+	}
+	c.State = "running"
+	c.ExitCode = 0
+	f.emit("container", "restart", c.ID, c.Name)
+	return nil
+}
+
+func (f *Fake) RemoveContainer(ctx context.Context, containerID string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("RemoveContainer", containerID)
+	if err != nil {
+		return err
+	}
+	if c.State == "running" && !force {
+		return errdefs.Conflict(fmt.Errorf("You cannot remove a running container %s. Stop the container before attempting removal or force remove", c.ID))
+	}
+
+	for _, n := range f.networks {
+		n.detach(c.ID)
+	}
+	for i, existing := range f.containers {
+		if existing == c {
+			f.containers = append(f.containers[:i], f.containers[i+1:]...)
+			break
+		}
+	}
+	f.emit("container", "destroy", c.ID, c.Name)
+	return nil
+}
+
+func (f *Fake) RenameContainer(ctx context.Context, containerID, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("RenameContainer", containerID)
+	if err != nil {
+		return err
+	}
+	name = strings.TrimPrefix(name, "/")
+	if existing := f.findContainer(name); existing != nil && existing != c {
+		return errdefs.Conflict(fmt.Errorf("Conflict. The container name \"/%s\" is already in use", name))
+	}
+	c.Name = name
+	f.emit("container", "rename", c.ID, c.Name)
+	return nil
+}
+
+func (f *Fake) GetContainerLogs(ctx context.Context, containerID string, opts docker.LogOptions) ([]models.LogEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("GetContainerLogs", containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := []models.LogEntry{}
+	for _, entry := range c.Logs {
+		switch {
+		case opts.Stream != "" && entry.Stream != opts.Stream:
+		case !opts.Since.IsZero() && entry.Timestamp.Before(opts.Since):
+		case !opts.Until.IsZero() && entry.Timestamp.After(opts.Until):
+		default:
+			logs = append(logs, entry)
+		}
+	}
+	if opts.Tail > 0 && len(logs) > opts.Tail {
+		logs = logs[len(logs)-opts.Tail:]
+	}
+	return logs, nil
+}
+
+func (f *Fake) GetContainerStats(ctx context.Context, containerID string) (*models.ContainerStats, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("GetContainerStats", containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := c.Stats
+	if c.State != "running" {
+		stats = models.ContainerStats{}
+	}
+	stats.ID = c.ID
+	stats.Name = "/" + c.Name
+	stats.Time = time.Now().UTC()
+	return &stats, nil
+}
+
+func (f *Fake) ContainerTop(ctx context.Context, containerID string, psArgs []string) (*models.ProcessList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("ContainerTop", containerID)
+	if err != nil {
+		return nil, err
+	}
+	if c.State != "running" {
+		return nil, errdefs.Conflict(fmt.Errorf("Container %s is not running", c.ID))
+	}
+
+	processes := c.Processes
+	if len(processes) == 0 {
+		processes = []map[string]string{{"UID": "root", "PID": "1", "PPID": "0", "CMD": c.Command}}
+	}
+	return &models.ProcessList{
+		Titles:    []string{"UID", "PID", "PPID", "CMD"},
+		Processes: processes,
+	}, nil
+}
+
+func (f *Fake) GetContainerResources(ctx context.Context, containerID string) (*models.ContainerResources, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("GetContainerResources", containerID)
+	if err != nil {
+		return nil, err
+	}
+	resources := c.Resources
+	return &resources, nil
+}
+
+func (f *Fake) UpdateContainerResources(ctx context.Context, containerID string, req models.UpdateResourcesRequest) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("UpdateContainerResources", containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &c.Resources
+	for _, field := range []struct {
+		value  *int64
+		target *int64
+	}{
+		{req.CPUShares, &r.CPUShares},
+		{req.CPUPeriod, &r.CPUPeriod},
+		{req.CPUQuota, &r.CPUQuota},
+		{req.NanoCPUs, &r.NanoCPUs},
+		{req.Memory, &r.Memory},
+		{req.MemoryReservation, &r.MemoryReservation},
+		{req.MemorySwap, &r.MemorySwap},
+		{req.PidsLimit, &r.PidsLimit},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	if req.RestartPolicy != nil {
+		r.RestartPolicy = *req.RestartPolicy
+	}
+
+	f.emit("container", "update", c.ID, c.Name)
+	return nil, nil
+}
+
+func newID() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,338 @@
+package dockertest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/errdefs"
+)
+
+type file struct {
+	content []byte
+	mode    os.FileMode
+	modTime time.Time
+	uid     int
+	gid     int
+	link    string
+}
+
+func newFileTree(seed, links map[string]string) map[string]*file {
+	now := time.Now().UTC()
+	tree := map[string]*file{"/": {mode: os.ModeDir | 0o755, modTime: now}}
+	for name, content := range seed {
+		name = path.Clean("/" + name)
+		mkdirAll(tree, path.Dir(name), now)
+		tree[name] = &file{content: []byte(content), mode: 0o644, modTime: now}
+	}
+	for name, target := range links {
+		name = path.Clean("/" + name)
+		mkdirAll(tree, path.Dir(name), now)
+		tree[name] = &file{mode: os.ModeSymlink | 0o777, modTime: now, link: target}
+	}
+	return tree
+}
+
+func mkdirAll(tree map[string]*file, dir string, now time.Time) {
+	for ; dir != "/"; dir = path.Dir(dir) {
+		if _, ok := tree[dir]; !ok {
+			tree[dir] = &file{mode: os.ModeDir | 0o755, modTime: now}
+		}
+	}
+}
+
+func (c *Container) children(dir string) []string {
+	var names []string
+	for name := range c.files {
+		if name != "/" && path.Dir(name) == dir {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Container) lookup(name string) (string, *file, error) {
+	name = path.Clean("/" + name)
+	entry, ok := c.files[name]
+	if !ok {
+		return "", nil, errdefs.NotFound(fmt.Errorf("Could not find the file %s in container %s", name, c.ID))
+	}
+	return name, entry, nil
+}
+
+func (f *Fake) File(containerID, name string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c := f.findContainer(containerID)
+	if c == nil {
+		return "", false
+	}
+	entry, ok := c.files[path.Clean("/"+name)]
+	if !ok || entry.mode.IsDir() {
+		return "", false
+	}
+	return string(entry.content), true
+}
+
+func (f *Fake) ListDirectory(ctx context.Context, containerID, dir string) ([]models.FileEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("ListDirectory", containerID)
+	if err != nil {
+		return nil, err
+	}
+	dir, entry, err := c.lookup(dir)
+	if err != nil {
+		return nil, err
+	}
+	if target := docker.LinkTarget(dir, container.PathStat{Mode: entry.mode, LinkTarget: entry.link}); target != "" {
+		if dir, entry, err = c.lookup(target); err != nil {
+			return nil, err
+		}
+	}
+	if !entry.mode.IsDir() {
+		return nil, docker.ErrNotDirectory
+	}
+
+	entries := []models.FileEntry{}
+	for _, name := range c.children(dir) {
+		child := c.files[name]
+		kind := "file"
+		switch {
+		case child.mode.IsDir():
+			kind = "dir"
+		case child.mode&os.ModeSymlink != 0:
+			kind = "symlink"
+		}
+		entries = append(entries, models.FileEntry{
+			Name:    path.Base(name),
+			Path:    name,
+			Type:    kind,
+			Size:    int64(len(child.content)),
+			Mode:    child.mode.String(),
+			ModTime: child.modTime,
+			UID:     child.uid,
+			GID:     child.gid,
+		})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Type == "dir" && entries[j].Type != "dir"
+	})
+	return entries, nil
+}
+
+func (f *Fake) CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, *container.PathStat, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("CopyFromContainer", containerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	name, entry, err := c.lookup(srcPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	base := path.Base(name)
+	if err := writeEntry(tw, base, entry); err != nil {
+		return nil, nil, err
+	}
+	if entry.mode.IsDir() {
+		for _, child := range c.descendants(name) {
+			rel := path.Join(base, strings.TrimPrefix(child, name))
+			if err := writeEntry(tw, rel, c.files[child]); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	stat := &container.PathStat{
+		Name:       base,
+		Size:       int64(len(entry.content)),
+		Mode:       entry.mode,
+		Mtime:      entry.modTime,
+		LinkTarget: entry.link,
+	}
+	return io.NopCloser(&buf), stat, nil
+}
+
+func (c *Container) descendants(dir string) []string {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	var names []string
+	for name := range c.files {
+		if name != dir && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func writeEntry(tw *tar.Writer, name string, entry *file) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    int64(entry.mode.Perm()),
+		Size:    int64(len(entry.content)),
+		ModTime: entry.modTime,
+		Uid:     entry.uid,
+		Gid:     entry.gid,
+	}
+	if entry.mode.IsDir() {
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+		header.Size = 0
+		return tw.WriteHeader(header)
+	}
+
+	if entry.mode&os.ModeSymlink != 0 {
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = entry.link
+		header.Size = 0
+		return tw.WriteHeader(header)
+	}
+
+	header.Typeflag = tar.TypeReg
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(entry.content)
+	return err
+}
+
+func (f *Fake) UploadFiles(ctx context.Context, containerID, destDir string, files []docker.UploadFile, opts docker.UploadOptions) error {
+	contents := make([][]byte, len(files))
+	for i, upload := range files {
+		data, err := io.ReadAll(io.LimitReader(upload.Content, upload.Size))
+		if err != nil {
+			return err
+		}
+		contents[i] = data
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("UploadFiles", containerID)
+	if err != nil {
+		return err
+	}
+	dir, entry, err := c.lookup(destDir)
+	if err != nil {
+		return err
+	}
+	if !entry.mode.IsDir() {
+		return errdefs.InvalidParameter(fmt.Errorf("extraction point is not a directory"))
+	}
+
+	mode := os.FileMode(opts.Mode).Perm()
+	if mode == 0 {
+		mode = 0o644
+	}
+	now := time.Now().UTC()
+	for i, upload := range files {
+		name := path.Join(dir, path.Base(upload.Name))
+		kind := "modified"
+		if _, exists := c.files[name]; !exists {
+			kind = "added"
+		}
+		c.files[name] = &file{content: contents[i], mode: mode, modTime: now, uid: opts.UID, gid: opts.GID}
+		c.changes = append(c.changes, models.FileChange{Path: name, Kind: kind})
+	}
+	return nil
+}
+
+func (f *Fake) ContainerChanges(ctx context.Context, containerID string) ([]models.FileChange, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container("ContainerChanges", containerID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.FileChange{}, c.changes...), nil
+}
+
+func (f *Fake) RunHelper(ctx context.Context, opts docker.HelperOptions) error {
+	var input []byte
+	if opts.Stdin != nil {
+		data, err := io.ReadAll(opts.Stdin)
+		if err != nil {
+			return err
+		}
+		input = data
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("RunHelper"); err != nil {
+		return err
+	}
+	if f.findImage(opts.Image) == nil {
+		f.images = append(f.images, newImage(opts.Image, 0))
+		f.emit("image", "pull", normalizeRef(opts.Image), normalizeRef(opts.Image))
+	}
+
+	var v *fakeVolume
+	for _, m := range opts.Mounts {
+		if v = f.findVolume(m.Source); v == nil {
+			v = newVolume(models.CreateVolumeRequest{Name: m.Source})
+			f.volumes = append(f.volumes, v)
+			f.emit("volume", "create", v.Name, v.Name)
+		}
+	}
+	if v == nil {
+		return fmt.Errorf("helper container %q needs a volume mount", opts.Purpose)
+	}
+
+	f.emit("container", "helper:"+opts.Purpose, "", v.Name)
+	switch opts.Purpose {
+	case "backup":
+		data := v.data
+		if len(data) == 0 {
+			data = emptyArchive()
+		}
+		if opts.Stdout != nil {
+			_, err := opts.Stdout.Write(data)
+			return err
+		}
+		return nil
+	case "restore":
+		if _, err := gzip.NewReader(bytes.NewReader(input)); err != nil {
+			return fmt.Errorf("helper container exited with status 1: tar: invalid gzip data: %v", err)
+		}
+		v.data = input
+		return nil
+	default:
+		return fmt.Errorf("helper container exited with status 127: unsupported helper %q", opts.Purpose)
+	}
+}
+
+func emptyArchive() []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tar.NewWriter(gz).Close()
+	gz.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,652 @@
+package dockertest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/errdefs"
+)
+
+type image struct {
+	models.Image
+}
+
+func newImage(ref string, size int64) *image {
+	return &image{models.Image{
+		ID:       "sha256:" + newID(),
+		RepoTags: []string{normalizeRef(ref)},
+		Size:     size,
+		Created:  time.Now().Unix(),
+	}}
+}
+
+func normalizeRef(ref string) string {
+	if i := strings.LastIndex(ref, ":"); i < 0 || strings.Contains(ref[i:], "/") {
+		return ref + ":latest"
+	}
+	return ref
+}
+
+func (f *Fake) AddImage(ref string, size int64) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if img := f.findImage(ref); img != nil {
+		return img.ID
+	}
+	img := newImage(ref, size)
+	f.images = append(f.images, img)
+	return img.ID
+}
+
+func (f *Fake) findImage(ref string) *image {
+	if ref == "" {
+		return nil
+	}
+	tag := normalizeRef(ref)
+	for _, img := range f.images {
+		if img.ID == ref || strings.TrimPrefix(img.ID, "sha256:") == ref {
+			return img
+		}
+		for _, t := range img.RepoTags {
+			if t == tag {
+				return img
+			}
+		}
+	}
+	for _, img := range f.images {
+		if len(ref) >= 3 && strings.HasPrefix(strings.TrimPrefix(img.ID, "sha256:"), strings.TrimPrefix(ref, "sha256:")) {
+			return img
+		}
+	}
+	return nil
+}
+
+func (f *Fake) imageInUse(img *image) *Container {
+	for _, c := range f.containers {
+		if f.findImage(c.Image) == img {
+			return c
+		}
+	}
+	return nil
+}
+
+func (f *Fake) ListImages(ctx context.Context) ([]models.Image, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("ListImages"); err != nil {
+		return nil, err
+	}
+
+	result := []models.Image{}
+	for _, img := range f.images {
+		result = append(result, models.Image{
+			ID:       img.ID,
+			RepoTags: append([]string{}, img.RepoTags...),
+			Size:     img.Size,
+			Created:  img.Created,
+		})
+	}
+	return result, nil
+}
+
+func (f *Fake) ImageExists(ctx context.Context, imageName string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("ImageExists"); err != nil {
+		return false, err
+	}
+	return f.findImage(imageName) != nil, nil
+}
+
+func (f *Fake) PullImage(ctx context.Context, imageName string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("PullImage"); err != nil {
+		return err
+	}
+	if strings.TrimSpace(imageName) == "" {
+		return errdefs.InvalidParameter(fmt.Errorf("invalid reference format"))
+	}
+	if f.findImage(imageName) == nil {
+		img := newImage(imageName, 0)
+		f.images = append(f.images, img)
+	}
+	f.emit("image", "pull", normalizeRef(imageName), normalizeRef(imageName))
+	return nil
+}
+
+func (f *Fake) RemoveImage(ctx context.Context, imageID string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("RemoveImage"); err != nil {
+		return err
+	}
+	img := f.findImage(imageID)
+	if img == nil {
+		return errdefs.NotFound(fmt.Errorf("No such image: %s", imageID))
+	}
+	if c := f.imageInUse(img); c != nil && !force {
+		return errdefs.Conflict(fmt.Errorf("conflict: unable to remove repository reference %q (must force) - container %s is using its referenced image", imageID, c.ID[:12]))
+	}
+
+	f.removeImage(img)
+	f.emit("image", "delete", img.ID, imageID)
+	return nil
+}
+
+func (f *Fake) removeImage(img *image) {
+	for i, existing := range f.images {
+		if existing == img {
+			f.images = append(f.images[:i], f.images[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *Fake) PruneImages(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("PruneImages"); err != nil {
+		return err
+	}
+	for _, img := range append([]*image{}, f.images...) {
+		if len(img.RepoTags) == 0 && f.imageInUse(img) == nil {
+			f.removeImage(img)
+			f.emit("image", "delete", img.ID, img.ID)
+		}
+	}
+	return nil
+}
+
+type fakeNetwork struct {
+	models.NetworkResource
+	builtin bool
+	aliases map[string][]string
+}
+
+func (n *fakeNetwork) attach(c *Container, aliases []string) {
+	if n.endpoint(c.ID) != nil {
+		return
+	}
+	if n.aliases == nil {
+		n.aliases = map[string][]string{}
+	}
+	n.aliases[c.ID] = append([]string{}, aliases...)
+
+	ip := ""
+	if n.Driver == "bridge" {
+		ip = fmt.Sprintf("172.17.0.%d/16", len(n.Containers)+2)
+	}
+	n.Containers = append(n.Containers, models.NetworkEndpoint{
+		ContainerID: c.ID,
+		Name:        c.Name,
+		EndpointID:  newID(),
+		MacAddress:  fmt.Sprintf("02:42:ac:11:00:%02x", len(n.Containers)+2),
+		IPv4Address: ip,
+	})
+	sort.Slice(n.Containers, func(i, j int) bool { return n.Containers[i].Name < n.Containers[j].Name })
+}
+
+func (n *fakeNetwork) detach(containerID string) bool {
+	for i, endpoint := range n.Containers {
+		if endpoint.ContainerID == containerID {
+			n.Containers = append(n.Containers[:i], n.Containers[i+1:]...)
+			delete(n.aliases, containerID)
+			return true
+		}
+	}
+	return false
+}
+
+func (n *fakeNetwork) endpoint(containerID string) *models.NetworkEndpoint {
+	for i := range n.Containers {
+		if n.Containers[i].ContainerID == containerID {
+			return &n.Containers[i]
+		}
+	}
+	return nil
+}
+
+func (n *fakeNetwork) model() models.NetworkResource {
+	result := n.NetworkResource
+	result.Containers = append([]models.NetworkEndpoint{}, n.Containers...)
+	result.IPAM.Config = append([]models.IPAMPool{}, n.IPAM.Config...)
+	return result
+}
+
+func (f *Fake) findNetwork(idOrName string) *fakeNetwork {
+	for _, n := range f.networks {
+		if n.ID == idOrName || n.Name == idOrName {
+			return n
+		}
+	}
+	if len(idOrName) >= 3 {
+		for _, n := range f.networks {
+			if strings.HasPrefix(n.ID, idOrName) {
+				return n
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Fake) network(method, idOrName string) (*fakeNetwork, error) {
+	if err := f.fail(method); err != nil {
+		return nil, err
+	}
+	n := f.findNetwork(idOrName)
+	if n == nil {
+		return nil, errdefs.NotFound(fmt.Errorf("network %s not found", idOrName))
+	}
+	return n, nil
+}
+
+func (f *Fake) ListNetworks(ctx context.Context) ([]models.NetworkResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("ListNetworks"); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.NetworkResource, 0, len(f.networks))
+	for _, n := range f.networks {
+		result = append(result, n.model())
+	}
+	return result, nil
+}
+
+func (f *Fake) InspectNetwork(ctx context.Context, networkID string) (*models.NetworkResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.network("InspectNetwork", networkID)
+	if err != nil {
+		return nil, err
+	}
+	result := n.model()
+	return &result, nil
+}
+
+func (f *Fake) NetworkExists(ctx context.Context, name string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("NetworkExists"); err != nil {
+		return false, err
+	}
+	return f.findNetwork(name) != nil, nil
+}
+
+func (f *Fake) CreateNetwork(ctx context.Context, req models.CreateNetworkRequest) (*models.NetworkResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("CreateNetwork"); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, errdefs.InvalidParameter(fmt.Errorf("network name is required"))
+	}
+	if f.findNetwork(req.Name) != nil {
+		return nil, errdefs.Conflict(fmt.Errorf("network with name %s already exists", req.Name))
+	}
+
+	driver := req.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+	n := &fakeNetwork{NetworkResource: models.NetworkResource{
+		ID:         newID(),
+		Name:       req.Name,
+		Driver:     driver,
+		Scope:      "local",
+		Created:    time.Now().UTC(),
+		Internal:   req.Internal,
+		Attachable: req.Attachable,
+		EnableIPv6: req.EnableIPv6,
+		IPAM:       models.NetworkIPAM{Driver: "default"},
+		Options:    req.Options,
+		Labels:     req.Labels,
+	}}
+	if req.IPAM != nil {
+		n.IPAM = *req.IPAM
+		if n.IPAM.Driver == "" {
+			n.IPAM.Driver = "default"
+		}
+	}
+
+	f.networks = append(f.networks, n)
+	f.emit("network", "create", n.ID, n.Name)
+	result := n.model()
+	return &result, nil
+}
+
+func (f *Fake) RemoveNetwork(ctx context.Context, networkID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.network("RemoveNetwork", networkID)
+	if err != nil {
+		return err
+	}
+	if n.builtin {
+		return errdefs.Forbidden(fmt.Errorf("%s is a pre-defined network and cannot be removed", n.Name))
+	}
+	if len(n.Containers) > 0 {
+		return errdefs.Forbidden(fmt.Errorf("error while removing network: network %s id %s has active endpoints", n.Name, n.ID))
+	}
+
+	f.removeNetwork(n)
+	return nil
+}
+
+func (f *Fake) removeNetwork(n *fakeNetwork) {
+	for i, existing := range f.networks {
+		if existing == n {
+			f.networks = append(f.networks[:i], f.networks[i+1:]...)
+			break
+		}
+	}
+	f.emit("network", "destroy", n.ID, n.Name)
+}
+
+func (f *Fake) PruneNetworks(ctx context.Context) (*models.PruneNetworksResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("PruneNetworks"); err != nil {
+		return nil, err
+	}
+
+	result := &models.PruneNetworksResult{NetworksDeleted: []string{}}
+	for _, n := range append([]*fakeNetwork{}, f.networks...) {
+		if n.builtin || len(n.Containers) > 0 {
+			continue
+		}
+		f.removeNetwork(n)
+		result.NetworksDeleted = append(result.NetworksDeleted, n.Name)
+	}
+	return result, nil
+}
+
+func (f *Fake) ConnectNetwork(ctx context.Context, networkID string, req models.NetworkConnectRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.network("ConnectNetwork", networkID)
+	if err != nil {
+		return err
+	}
+	c := f.findContainer(req.Container)
+	if c == nil {
+		return errdefs.NotFound(fmt.Errorf("No such container: %s", req.Container))
+	}
+	if n.endpoint(c.ID) != nil {
+		return errdefs.Forbidden(fmt.Errorf("endpoint with name %s already exists in network %s", c.Name, n.Name))
+	}
+
+	n.attach(c, req.Aliases)
+	if req.IPv4Address != "" {
+		n.endpoint(c.ID).IPv4Address = req.IPv4Address
+	}
+	if req.IPv6Address != "" {
+		n.endpoint(c.ID).IPv6Address = req.IPv6Address
+	}
+	c.Networks = append(c.Networks, n.Name)
+	f.emit("network", "connect", n.ID, n.Name)
+	return nil
+}
+
+func (f *Fake) DisconnectNetwork(ctx context.Context, networkID string, req models.NetworkDisconnectRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, err := f.network("DisconnectNetwork", networkID)
+	if err != nil {
+		return err
+	}
+	c := f.findContainer(req.Container)
+	if c == nil {
+		return errdefs.NotFound(fmt.Errorf("No such container: %s", req.Container))
+	}
+	if !n.detach(c.ID) {
+		return errdefs.Forbidden(fmt.Errorf("container %s is not connected to network %s", c.ID, n.Name))
+	}
+
+	for i, name := range c.Networks {
+		if name == n.Name {
+			c.Networks = append(c.Networks[:i], c.Networks[i+1:]...)
+			break
+		}
+	}
+	f.emit("network", "disconnect", n.ID, n.Name)
+	return nil
+}
+
+type fakeVolume struct {
+	models.Volume
+	data []byte
+}
+
+func (f *Fake) AddVolume(name string, labels map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.findVolume(name) == nil {
+		f.volumes = append(f.volumes, newVolume(models.CreateVolumeRequest{Name: name, Labels: labels}))
+	}
+}
+
+func (f *Fake) VolumeData(name string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v := f.findVolume(name)
+	if v == nil {
+		return nil, false
+	}
+	return append([]byte(nil), v.data...), true
+}
+
+func (f *Fake) SetVolumeData(name string, data []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if v := f.findVolume(name); v != nil {
+		v.data = append([]byte(nil), data...)
+	}
+}
+
+func newVolume(req models.CreateVolumeRequest) *fakeVolume {
+	name := req.Name
+	if name == "" {
+		name = newID()
+	}
+	driver := req.Driver
+	if driver == "" {
+		driver = "local"
+	}
+	labels := req.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	return &fakeVolume{Volume: models.Volume{
+		Name:       name,
+		Driver:     driver,
+		Mountpoint: "/var/lib/docker/volumes/" + name + "/_data",
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		Scope:      "local",
+		Labels:     labels,
+		Options:    req.DriverOpts,
+		Size:       -1,
+		RefCount:   -1,
+	}}
+}
+
+func (f *Fake) findVolume(name string) *fakeVolume {
+	for _, v := range f.volumes {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (f *Fake) volumeModel(v *fakeVolume) models.Volume {
+	result := v.Volume
+	result.Size = int64(len(v.data))
+	result.UsedBy = []models.VolumeUsage{}
+	for _, c := range f.containers {
+		for _, m := range c.Mounts {
+			if m.Type == "volume" && (m.Name == v.Name || m.Source == v.Name) {
+				result.UsedBy = append(result.UsedBy, models.VolumeUsage{
+					ContainerID:   c.ID,
+					ContainerName: c.Name,
+					State:         c.State,
+					Destination:   m.Destination,
+					RW:            m.RW,
+				})
+			}
+		}
+	}
+	result.RefCount = int64(len(result.UsedBy))
+	return result
+}
+
+func (f *Fake) ListVolumes(ctx context.Context) ([]models.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("ListVolumes"); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Volume, 0, len(f.volumes))
+	for _, v := range f.volumes {
+		result = append(result, f.volumeModel(v))
+	}
+	return result, nil
+}
+
+func (f *Fake) InspectVolume(ctx context.Context, name string) (*models.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("InspectVolume"); err != nil {
+		return nil, err
+	}
+	v := f.findVolume(name)
+	if v == nil {
+		return nil, errdefs.NotFound(fmt.Errorf("get %s: no such volume", name))
+	}
+	result := f.volumeModel(v)
+	return &result, nil
+}
+
+func (f *Fake) VolumeExists(ctx context.Context, name string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("VolumeExists"); err != nil {
+		return false, err
+	}
+	return f.findVolume(name) != nil, nil
+}
+
+func (f *Fake) CreateVolume(ctx context.Context, req models.CreateVolumeRequest) (*models.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("CreateVolume"); err != nil {
+		return nil, err
+	}
+
+	v := f.findVolume(req.Name)
+	if v == nil {
+		v = newVolume(req)
+		f.volumes = append(f.volumes, v)
+		f.emit("volume", "create", v.Name, v.Name)
+	}
+	result := f.volumeModel(v)
+	return &result, nil
+}
+
+func (f *Fake) RemoveVolume(ctx context.Context, name string, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("RemoveVolume"); err != nil {
+		return err
+	}
+	v := f.findVolume(name)
+	if v == nil {
+		if force {
+			return nil
+		}
+		return errdefs.NotFound(fmt.Errorf("get %s: no such volume", name))
+	}
+	if used := f.volumeModel(v).UsedBy; len(used) > 0 {
+		return errdefs.Conflict(fmt.Errorf("remove %s: volume is in use - [%s]", name, used[0].ContainerID))
+	}
+
+	f.removeVolume(v)
+	return nil
+}
+
+func (f *Fake) removeVolume(v *fakeVolume) {
+	for i, existing := range f.volumes {
+		if existing == v {
+			f.volumes = append(f.volumes[:i], f.volumes[i+1:]...)
+			break
+		}
+	}
+	f.emit("volume", "destroy", v.Name, v.Name)
+}
+
+func (f *Fake) PruneVolumes(ctx context.Context, all bool) (*models.PruneVolumesResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.fail("PruneVolumes"); err != nil {
+		return nil, err
+	}
+
+	result := &models.PruneVolumesResult{VolumesDeleted: []string{}}
+	for _, v := range append([]*fakeVolume{}, f.volumes...) {
+		model := f.volumeModel(v)
+		if len(model.UsedBy) > 0 {
+			continue
+		}
+		if !all && !anonymous(v.Name) {
+			continue
+		}
+		f.removeVolume(v)
+		result.VolumesDeleted = append(result.VolumesDeleted, v.Name)
+		result.SpaceReclaimed += uint64(len(v.data))
+	}
+	return result, nil
+}
+
+func anonymous(name string) bool {
+	if len(name) != 64 {
+		return false
+	}
+	for _, r := range name {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+var ErrNotDirectory = errors.New("path is not a directory")
+
+type UploadFile struct {
+	Name    string
+	Size    int64
+	Content io.Reader
+}
+
+type UploadOptions struct {
+	UID        int
+	GID        int
+	Mode       int64
+	CopyUIDGID bool
+}
+
+type ExecResult struct {
+	Stdout   []byte
+	Stderr   []byte
+	ExitCode int
+}
+
+func (c *Client) Exec(ctx context.Context, containerID string, cmd []string) (*ExecResult, error) {
+	created, err := c.cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hijacked, err := c.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer hijacked.Close()
+
+	var stdout, stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(&stdout, &stderr, hijacked.Reader); err != nil {
+		return nil, err
+	}
+
+	inspect, err := c.cli.ContainerExecInspect(ctx, created.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExecResult{
+		Stdout:   stdout.Bytes(),
+		Stderr:   stderr.Bytes(),
+		ExitCode: inspect.ExitCode,
+	}, nil
+}
+
+func (c *Client) StatPath(ctx context.Context, containerID, filePath string) (*container.PathStat, error) {
+	stat, err := c.cli.ContainerStatPath(ctx, containerID, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &stat, nil
+}
+
+func LinkTarget(linkPath string, stat container.PathStat) string {
+	if stat.Mode&os.ModeSymlink == 0 || stat.LinkTarget == "" {
+		return ""
+	}
+	if path.IsAbs(stat.LinkTarget) {
+		return stat.LinkTarget
+	}
+	return path.Join(path.Dir(linkPath), stat.LinkTarget)
+}
+
+func (c *Client) ListDirectory(ctx context.Context, containerID, dir string) ([]models.FileEntry, error) {
+	stat, err := c.cli.ContainerStatPath(ctx, containerID, dir)
+	if err != nil {
+		return nil, err
+	}
+	if target := LinkTarget(dir, stat); target != "" {
+		dir = target
+		if stat, err = c.cli.ContainerStatPath(ctx, containerID, dir); err != nil {
+			return nil, err
+		}
+	}
+	if !stat.Mode.IsDir() {
+		return nil, ErrNotDirectory
+	}
+
+	inspect, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.FileEntry
+	if inspect.State != nil && inspect.State.Running {
+		entries, err = c.listDirectoryExec(ctx, containerID, dir)
+	}
+	if entries == nil || err != nil {
+		entries, err = c.listDirectoryArchive(ctx, containerID, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if (entries[i].Type == "dir") != (entries[j].Type == "dir") {
+			return entries[i].Type == "dir"
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
+func (c *Client) listDirectoryExec(ctx context.Context, containerID, dir string) ([]models.FileEntry, error) {
+	result, err := c.Exec(ctx, containerID, []string{
+		"find", dir, "-mindepth", "1", "-maxdepth", "1",
+		"-exec", "stat", "-c", "%n\t%s\t%f\t%Y\t%u\t%g\t%N", "{}", "+",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("listing %s failed: %s", dir, strings.TrimSpace(string(result.Stderr)))
+	}
+
+	entries := []models.FileEntry{}
+	for _, line := range strings.Split(string(result.Stdout), "\n") {
+		fields := strings.SplitN(line, "\t", 7)
+		if len(fields) != 7 {
+			continue
+		}
+
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		rawMode, _ := strconv.ParseUint(fields[2], 16, 32)
+		mtime, _ := strconv.ParseInt(fields[3], 10, 64)
+		uid, _ := strconv.Atoi(fields[4])
+		gid, _ := strconv.Atoi(fields[5])
+		mode := unixModeToFileMode(uint32(rawMode))
+
+		entry := models.FileEntry{
+			Name:    path.Base(fields[0]),
+			Path:    fields[0],
+			Type:    fileType(mode),
+			Size:    size,
+			Mode:    mode.String(),
+			ModTime: time.Unix(mtime, 0).UTC(),
+			UID:     uid,
+			GID:     gid,
+		}
+		if mode&os.ModeSymlink != 0 {
+			if _, target, ok := strings.Cut(fields[6], " -> "); ok {
+				entry.LinkTarget = strings.Trim(target, "'\"`’‘")
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (c *Client) listDirectoryArchive(ctx context.Context, containerID, dir string) ([]models.FileEntry, error) {
+	reader, _, err := c.cli.CopyFromContainer(ctx, containerID, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	entries := []models.FileEntry{}
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parts := strings.Split(strings.Trim(header.Name, "/"), "/")
+		if len(parts) != 2 {
+			continue
+		}
+
+		mode := header.FileInfo().Mode()
+		entries = append(entries, models.FileEntry{
+			Name:       parts[1],
+			Path:       path.Join(dir, parts[1]),
+			Type:       fileType(mode),
+			Size:       header.Size,
+			Mode:       mode.String(),
+			ModTime:    header.ModTime.UTC(),
+			UID:        header.Uid,
+			GID:        header.Gid,
+			LinkTarget: header.Linkname,
+		})
+	}
+
+	return entries, nil
+}
+
+func (c *Client) CopyFromContainer(ctx context.Context, containerID, srcPath string) (io.ReadCloser, *container.PathStat, error) {
+	reader, stat, err := c.cli.CopyFromContainer(ctx, containerID, srcPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, &stat, nil
+}
+
+func (c *Client) UploadFiles(ctx context.Context, containerID, destDir string, files []UploadFile, opts UploadOptions) error {
+	pr, pw := io.Pipe()
+
+	go func() {
+		tw := tar.NewWriter(pw)
+		now := time.Now()
+		for _, file := range files {
+			header := &tar.Header{
+				Name:     path.Base(file.Name),
+				Mode:     opts.Mode,
+				Size:     file.Size,
+				Uid:      opts.UID,
+				Gid:      opts.GID,
+				ModTime:  now,
+				Typeflag: tar.TypeReg,
+			}
+			if err := tw.WriteHeader(header); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			if _, err := io.CopyN(tw, file.Content, file.Size); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(tw.Close())
+	}()
+
+	err := c.cli.CopyToContainer(ctx, containerID, destDir, pr, container.CopyToContainerOptions{
+		CopyUIDGID: opts.CopyUIDGID,
+	})
+	pr.CloseWithError(err)
+	return err
+}
+
+func (c *Client) ContainerChanges(ctx context.Context, containerID string) ([]models.FileChange, error) {
+	changes, err := c.cli.ContainerDiff(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.FileChange, 0, len(changes))
+	for _, change := range changes {
+		kind := "modified"
+		switch change.Kind {
+		case container.ChangeAdd:
+			kind = "added"
+		case container.ChangeDelete:
+			kind = "deleted"
+		}
+		result = append(result, models.FileChange{Path: change.Path, Kind: kind})
+	}
+
+	return result, nil
+}
+
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsDir():
+		return "dir"
+	case mode&os.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsRegular():
+		return "file"
+	default:
+		return "other"
+	}
+}
+
+func unixModeToFileMode(raw uint32) fs.FileMode {
+	mode := fs.FileMode(raw & 0o777)
+	switch raw & 0o170000 {
+	case 0o040000:
+		mode |= fs.ModeDir
+	case 0o120000:
+		mode |= fs.ModeSymlink
+	case 0o020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0o060000:
+		mode |= fs.ModeDevice
+	case 0o010000:
+		mode |= fs.ModeNamedPipe
+	case 0o140000:
+		mode |= fs.ModeSocket
+	}
+	if raw&0o4000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if raw&0o2000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if raw&0o1000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestLinkTarget(t *testing.T) {
+	cases := []struct {
+		path string
+		stat container.PathStat
+		want string
+	}{
+		{"/etc/nginx/conf.d/default.conf", container.PathStat{Mode: os.ModeSymlink, LinkTarget: "../nginx.conf"}, "/etc/nginx/nginx.conf"},
+		{"/srv/current", container.PathStat{Mode: os.ModeSymlink, LinkTarget: "releases/42/"}, "/srv/releases/42"},
+		{"/srv/current", container.PathStat{Mode: os.ModeSymlink, LinkTarget: "/opt/app"}, "/opt/app"},
+		{"/srv/current", container.PathStat{Mode: os.ModeSymlink}, ""},
+		{"/srv/app", container.PathStat{Mode: os.ModeDir, LinkTarget: "/opt/app"}, ""},
+	}
+
+	for _, tc := range cases {
+		if got := LinkTarget(tc.path, tc.stat); got != tc.want {
+			t.Errorf("LinkTarget(%s, %q) = %q, want %q", tc.path, tc.stat.LinkTarget, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+const helperLabel = "docker-gui.helper"
+
+type HelperOptions struct {
+	Image   string
+	Cmd     []string
+	Purpose string
+	Mounts  []mount.Mount
+	Stdin   io.Reader
+	Stdout  io.Writer
+}
+
+func (c *Client) EnsureImage(ctx context.Context, imageName string) error {
+	exists, err := c.ImageExists(ctx, imageName)
+	if err != nil || exists {
+		return err
+	}
+
+	reader, err := c.cli.ImagePull(ctx, imageName, image.PullOptions{})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, err = io.Copy(io.Discard, reader)
+	return err
+}
+
+func (c *Client) RunHelper(ctx context.Context, opts HelperOptions) error {
+	if err := c.EnsureImage(ctx, opts.Image); err != nil {
+		return fmt.Errorf("failed to prepare helper image %s: %w", opts.Image, err)
+	}
+
+	config := &container.Config{
+		Image:        opts.Image,
+		Cmd:          opts.Cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+		Labels:       map[string]string{helperLabel: opts.Purpose},
+	}
+	if opts.Stdin != nil {
+		config.AttachStdin = true
+		config.OpenStdin = true
+		config.StdinOnce = true
+	}
+
+	hostConfig := &container.HostConfig{
+		Mounts:      opts.Mounts,
+		NetworkMode: "none",
+	}
+
+	created, err := c.cli.ContainerCreate(ctx, config, hostConfig, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer c.cli.ContainerRemove(context.Background(), created.ID, container.RemoveOptions{Force: true})
+
+	hijacked, err := c.cli.ContainerAttach(ctx, created.ID, container.AttachOptions{
+		Stream: true,
+		Stdin:  opts.Stdin != nil,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer hijacked.Close()
+
+	waitCh, waitErrCh := c.cli.ContainerWait(ctx, created.ID, container.WaitConditionNextExit)
+
+	if err := c.cli.ContainerStart(ctx, created.ID, container.StartOptions{}); err != nil {
+		return err
+	}
+
+	stdinErr := make(chan error, 1)
+	if opts.Stdin != nil {
+		go func() {
+			_, err := io.Copy(hijacked.Conn, opts.Stdin)
+			hijacked.CloseWrite()
+			stdinErr <- err
+		}()
+	} else {
+		stdinErr <- nil
+	}
+
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	var stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(stdout, &stderr, hijacked.Reader); err != nil {
+		return err
+	}
+
+	if err := <-stdinErr; err != nil {
+		return err
+	}
+
+	select {
+	case result := <-waitCh:
+		if result.Error != nil {
+			return fmt.Errorf("helper container failed: %s", result.Error.Message)
+		}
+		if result.StatusCode != 0 {
+			return fmt.Errorf("helper container exited with status %d: %s", result.StatusCode, strings.TrimSpace(stderr.String()))
+		}
+	case err := <-waitErrCh:
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) ImageExists(ctx context.Context, imageName string) (bool, error) {
+	_, _, err := c.cli.ImageInspectWithRaw(ctx, imageName)
+	if err == nil {
+		return true, nil
+	}
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
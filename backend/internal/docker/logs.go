@@ -0,0 +1,117 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+type LogOptions struct {
+	Tail   int
+	Since  time.Time
+	Until  time.Time
+	Stream string
+}
+
+func (c *Client) GetContainerLogs(ctx context.Context, containerID string, opts LogOptions) ([]models.LogEntry, error) {
+	info, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	options := container.LogsOptions{
+		ShowStdout: opts.Stream != StreamStderr,
+		ShowStderr: opts.Stream != StreamStdout,
+		Timestamps: true,
+		Tail:       "all",
+	}
+	if opts.Tail > 0 {
+		options.Tail = strconv.Itoa(opts.Tail)
+	}
+	if !opts.Since.IsZero() {
+		options.Since = unixTimestamp(opts.Since)
+	}
+	if !opts.Until.IsZero() {
+		options.Until = unixTimestamp(opts.Until)
+	}
+
+	logs, err := c.cli.ContainerLogs(ctx, containerID, options)
+	if err != nil {
+		return nil, err
+	}
+	defer logs.Close()
+
+	if info.Config != nil && info.Config.Tty {
+		return readLogLines(logs, StreamStdout, nil)
+	}
+	return readMultiplexedLogs(logs)
+}
+
+func unixTimestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+func readMultiplexedLogs(r io.Reader) ([]models.LogEntry, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, 8)
+	var entries []models.LogEntry
+
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return entries, err
+		}
+
+		stream := StreamStdout
+		if header[0] == 2 {
+			stream = StreamStderr
+		}
+
+		payload := io.LimitReader(reader, int64(binary.BigEndian.Uint32(header[4:])))
+		var err error
+		if entries, err = readLogLines(payload, stream, entries); err != nil {
+			return entries, err
+		}
+	}
+}
+
+func readLogLines(r io.Reader, stream string, entries []models.LogEntry) ([]models.LogEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if entry, ok := parseLogLine(scanner.Text(), stream); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func parseLogLine(line, stream string) (models.LogEntry, bool) {
+	entry := models.LogEntry{Timestamp: time.Now(), Message: line, Stream: stream}
+	if stamp, message, ok := strings.Cut(line, " "); ok {
+		if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+			entry.Timestamp = t
+			entry.Message = message
+		}
+	}
+
+	entry.Message = strings.TrimSpace(entry.Message)
+	return entry, entry.Message != ""
+}
@@ -0,0 +1,163 @@
+package docker
+
+import (
+	"context"
+	"sort"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+func (c *Client) ListNetworks(ctx context.Context) ([]models.NetworkResource, error) {
+	networks, err := c.cli.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.NetworkResource, 0, len(networks))
+	for _, net := range networks {
+		result = append(result, buildNetwork(net))
+	}
+
+	return result, nil
+}
+
+func (c *Client) InspectNetwork(ctx context.Context, networkID string) (*models.NetworkResource, error) {
+	net, err := c.cli.NetworkInspect(ctx, networkID, network.InspectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildNetwork(net)
+	return &result, nil
+}
+
+func (c *Client) CreateNetwork(ctx context.Context, req models.CreateNetworkRequest) (*models.NetworkResource, error) {
+	options := network.CreateOptions{
+		Driver:     req.Driver,
+		Internal:   req.Internal,
+		Attachable: req.Attachable,
+		EnableIPv6: &req.EnableIPv6,
+		Options:    req.Options,
+		Labels:     req.Labels,
+	}
+
+	if req.IPAM != nil {
+		ipam := &network.IPAM{
+			Driver:  req.IPAM.Driver,
+			Options: req.IPAM.Options,
+		}
+		for _, pool := range req.IPAM.Config {
+			ipam.Config = append(ipam.Config, network.IPAMConfig{
+				Subnet:     pool.Subnet,
+				IPRange:    pool.IPRange,
+				Gateway:    pool.Gateway,
+				AuxAddress: pool.AuxAddresses,
+			})
+		}
+		options.IPAM = ipam
+	}
+
+	created, err := c.cli.NetworkCreate(ctx, req.Name, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.InspectNetwork(ctx, created.ID)
+}
+
+func (c *Client) RemoveNetwork(ctx context.Context, networkID string) error {
+	return c.cli.NetworkRemove(ctx, networkID)
+}
+
+func (c *Client) PruneNetworks(ctx context.Context) (*models.PruneNetworksResult, error) {
+	report, err := c.cli.NetworksPrune(ctx, filters.NewArgs())
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := report.NetworksDeleted
+	if deleted == nil {
+		deleted = []string{}
+	}
+
+	return &models.PruneNetworksResult{NetworksDeleted: deleted}, nil
+}
+
+func (c *Client) ConnectNetwork(ctx context.Context, networkID string, req models.NetworkConnectRequest) error {
+	settings := &network.EndpointSettings{
+		Aliases: req.Aliases,
+	}
+	if req.IPv4Address != "" || req.IPv6Address != "" {
+		settings.IPAMConfig = &network.EndpointIPAMConfig{
+			IPv4Address: req.IPv4Address,
+			IPv6Address: req.IPv6Address,
+		}
+	}
+
+	return c.cli.NetworkConnect(ctx, networkID, req.Container, settings)
+}
+
+func (c *Client) DisconnectNetwork(ctx context.Context, networkID string, req models.NetworkDisconnectRequest) error {
+	return c.cli.NetworkDisconnect(ctx, networkID, req.Container, req.Force)
+}
+
+func buildNetwork(net network.Inspect) models.NetworkResource {
+	result := models.NetworkResource{
+		ID:         net.ID,
+		Name:       net.Name,
+		Driver:     net.Driver,
+		Scope:      net.Scope,
+		Created:    net.Created,
+		Internal:   net.Internal,
+		Attachable: net.Attachable,
+		EnableIPv6: net.EnableIPv6,
+		IPAM: models.NetworkIPAM{
+			Driver:  net.IPAM.Driver,
+			Options: net.IPAM.Options,
+			Config:  make([]models.IPAMPool, 0, len(net.IPAM.Config)),
+		},
+		Options:    net.Options,
+		Labels:     net.Labels,
+		Containers: make([]models.NetworkEndpoint, 0, len(net.Containers)),
+	}
+
+	for _, pool := range net.IPAM.Config {
+		result.IPAM.Config = append(result.IPAM.Config, models.IPAMPool{
+			Subnet:       pool.Subnet,
+			IPRange:      pool.IPRange,
+			Gateway:      pool.Gateway,
+			AuxAddresses: pool.AuxAddress,
+		})
+	}
+
+	for id, endpoint := range net.Containers {
+		result.Containers = append(result.Containers, models.NetworkEndpoint{
+			ContainerID: id,
+			Name:        endpoint.Name,
+			EndpointID:  endpoint.EndpointID,
+			MacAddress:  endpoint.MacAddress,
+			IPv4Address: endpoint.IPv4Address,
+			IPv6Address: endpoint.IPv6Address,
+		})
+	}
+	sort.Slice(result.Containers, func(i, j int) bool {
+		return result.Containers[i].Name < result.Containers[j].Name
+	})
+
+	return result
+}
+
+func (c *Client) NetworkExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.cli.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err == nil {
+		return true, nil
+	}
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
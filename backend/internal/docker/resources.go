@@ -0,0 +1,106 @@
+package docker
+
+import (
+	"context"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+func (c *Client) ContainerTop(ctx context.Context, containerID string, psArgs []string) (*models.ProcessList, error) {
+	top, err := c.cli.ContainerTop(ctx, containerID, psArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]map[string]string, 0, len(top.Processes))
+	for _, row := range top.Processes {
+		process := make(map[string]string, len(top.Titles))
+		for i, title := range top.Titles {
+			if i < len(row) {
+				process[title] = row[i]
+			}
+		}
+		processes = append(processes, process)
+	}
+
+	return &models.ProcessList{
+		Titles:    top.Titles,
+		Processes: processes,
+	}, nil
+}
+
+func (c *Client) GetContainerResources(ctx context.Context, containerID string) (*models.ContainerResources, error) {
+	inspect, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	hostConfig := inspect.HostConfig
+	if hostConfig == nil {
+		return &models.ContainerResources{}, nil
+	}
+
+	var pidsLimit int64
+	if hostConfig.PidsLimit != nil {
+		pidsLimit = *hostConfig.PidsLimit
+	}
+
+	return &models.ContainerResources{
+		CPUShares:         hostConfig.CPUShares,
+		CPUPeriod:         hostConfig.CPUPeriod,
+		CPUQuota:          hostConfig.CPUQuota,
+		NanoCPUs:          hostConfig.NanoCPUs,
+		Memory:            hostConfig.Memory,
+		MemoryReservation: hostConfig.MemoryReservation,
+		MemorySwap:        hostConfig.MemorySwap,
+		PidsLimit:         pidsLimit,
+		RestartPolicy: models.RestartPolicy{
+			Name:              string(hostConfig.RestartPolicy.Name),
+			MaximumRetryCount: hostConfig.RestartPolicy.MaximumRetryCount,
+		},
+	}, nil
+}
+
+func (c *Client) UpdateContainerResources(ctx context.Context, containerID string, req models.UpdateResourcesRequest) ([]string, error) {
+	var update container.UpdateConfig
+
+	if req.CPUShares != nil {
+		update.CPUShares = *req.CPUShares
+	}
+	if req.CPUPeriod != nil {
+		update.CPUPeriod = *req.CPUPeriod
+	}
+	if req.CPUQuota != nil {
+		update.CPUQuota = *req.CPUQuota
+	}
+	if req.NanoCPUs != nil {
+		update.NanoCPUs = *req.NanoCPUs
+	}
+	if req.Memory != nil {
+		update.Memory = *req.Memory
+	}
+	if req.MemoryReservation != nil {
+		update.MemoryReservation = *req.MemoryReservation
+	}
+	if req.MemorySwap != nil {
+		update.MemorySwap = *req.MemorySwap
+	}
+	if req.PidsLimit != nil {
+		update.PidsLimit = req.PidsLimit
+	}
+	if req.RestartPolicy != nil {
+		update.RestartPolicy = container.RestartPolicy{
+			Name:              container.RestartPolicyMode(req.RestartPolicy.Name),
+			MaximumRetryCount: req.RestartPolicy.MaximumRetryCount,
+		}
+	}
+
+	result, err := c.cli.ContainerUpdate(ctx, containerID, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Warnings, nil
+}
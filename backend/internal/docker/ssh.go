@@ -0,0 +1,278 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/client"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultSSHPort      = "22"
+	defaultDockerSocket = "/var/run/docker.sock"
+
+	sshDialTimeout       = 10 * time.Second
+	sshKeepaliveInterval = 30 * time.Second
+	sshKeepaliveTimeout  = 15 * time.Second
+)
+
+type SSHMaterial struct {
+	PrivateKey string
+	KnownHosts string
+	Keepalive  time.Duration
+}
+
+type SSHEndpoint struct {
+	User    string
+	Address string
+	Socket  string
+}
+
+func ParseSSHEndpoint(endpoint string) (*SSHEndpoint, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ssh" {
+		return nil, fmt.Errorf("not an ssh endpoint: %s", endpoint)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("ssh endpoint must include a user, as in ssh://user@host")
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		return nil, errors.New("ssh endpoint must not include a password; use a key from the key store")
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("ssh endpoint must include a host")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return nil, errors.New("ssh endpoint must not include a query or fragment")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultSSHPort
+	}
+	socket := u.Path
+	if socket == "" || socket == "/" {
+		socket = defaultDockerSocket
+	}
+
+	return &SSHEndpoint{
+		User:    u.User.Username(),
+		Address: net.JoinHostPort(u.Hostname(), port),
+		Socket:  socket,
+	}, nil
+}
+
+func NewSSHClient(endpoint string, material SSHMaterial) (*Client, error) {
+	target, err := ParseSSHEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(material.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh private key: %w", err)
+	}
+	hostKeys, err := knownHostsCallback(material.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	keepalive := material.Keepalive
+	if keepalive <= 0 {
+		keepalive = sshKeepaliveInterval
+	}
+
+	transport := &sshTransport{
+		target:    *target,
+		keepalive: keepalive,
+		config: &ssh.ClientConfig{
+			User:            target.User,
+			Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+			HostKeyCallback: hostKeys,
+			Timeout:         sshDialTimeout,
+		},
+	}
+
+	cli, err := client.NewClientWithOpts(
+		client.WithAPIVersionNegotiation(),
+		client.WithHost("ssh://"+target.User+"@"+target.Address),
+		client.WithHTTPClient(&http.Client{Transport: &http.Transport{
+			DialContext:     transport.DialContext,
+			IdleConnTimeout: 90 * time.Second,
+		}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cli: cli, ssh: transport}, nil
+}
+
+func knownHostsCallback(records string) (ssh.HostKeyCallback, error) {
+	if strings.TrimSpace(records) == "" {
+		return nil, errors.New("no known host key recorded for ssh endpoint")
+	}
+
+	file, err := os.CreateTemp("", "docker-gui-known-hosts-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(records + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := knownhosts.New(file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+type sshTransport struct {
+	target    SSHEndpoint
+	config    *ssh.ClientConfig
+	keepalive time.Duration
+
+	mu     sync.Mutex
+	conn   *ssh.Client
+	closed bool
+}
+
+func (t *sshTransport) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := t.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := conn.Dial("unix", t.target.Socket)
+	if err == nil {
+		return stream, nil
+	}
+	if _, rejected := err.(*ssh.OpenChannelError); rejected {
+		return nil, fmt.Errorf("ssh server refused forwarding to %s: %w", t.target.Socket, err)
+	}
+
+	t.drop(conn, err)
+	conn, err = t.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.Dial("unix", t.target.Socket)
+}
+
+func (t *sshTransport) client(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, errors.New("ssh transport is closed")
+	}
+	if t.conn != nil {
+		return t.conn, nil
+	}
+
+	raw, err := (&net.Dialer{Timeout: sshDialTimeout, KeepAlive: t.keepalive}).DialContext(ctx, "tcp", t.target.Address)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		raw.SetDeadline(deadline)
+	} else {
+		raw.SetDeadline(time.Now().Add(sshDialTimeout))
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(raw, t.target.Address, t.config)
+	if err != nil {
+		raw.Close()
+		return nil, fmt.Errorf("ssh handshake with %s failed: %w", t.target.Address, err)
+	}
+	raw.SetDeadline(time.Time{})
+
+	t.conn = ssh.NewClient(c, chans, reqs)
+	go t.watch(t.conn)
+
+	return t.conn, nil
+}
+
+func (t *sshTransport) watch(conn *ssh.Client) {
+	done := make(chan error, 1)
+	go func() { done <- conn.Wait() }()
+
+	ticker := time.NewTicker(t.keepalive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-done:
+			t.drop(conn, err)
+			return
+		case <-ticker.C:
+			reply := make(chan error, 1)
+			go func() {
+				_, _, err := conn.SendRequest("keepalive@openssh.com", true, nil)
+				reply <- err
+			}()
+
+			select {
+			case err := <-reply:
+				if err != nil {
+					t.drop(conn, err)
+					return
+				}
+			case <-time.After(sshKeepaliveTimeout):
+				t.drop(conn, errors.New("keepalive timed out"))
+				return
+			case err := <-done:
+				t.drop(conn, err)
+				return
+			}
+		}
+	}
+}
+
+func (t *sshTransport) drop(conn *ssh.Client, reason error) {
+	t.mu.Lock()
+	current := t.conn == conn
+	if current {
+		t.conn = nil
+	}
+	closed := t.closed
+	t.mu.Unlock()
+
+	conn.Close()
+	if current && !closed {
+		log.Printf("SSH connection to %s lost, reconnecting on next request: %v", t.target.Address, reason)
+	}
+}
+
+func (t *sshTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
@@ -0,0 +1,272 @@
+package docker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type sshServer struct {
+	addr    string
+	hostKey ssh.Signer
+	socket  string
+
+	mu        sync.Mutex
+	conns     []*ssh.ServerConn
+	handshake int
+	forwarded []string
+}
+
+func newTestSigner(t *testing.T) (ssh.Signer, string) {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(private, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, string(pem.EncodeToMemory(block))
+}
+
+func startSSHServer(t *testing.T, user string, authorized ssh.PublicKey) *sshServer {
+	t.Helper()
+
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	daemon, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Api-Version", "1.45")
+		if strings.HasSuffix(r.URL.Path, "/info") {
+			json.NewEncoder(w).Encode(map[string]any{"ServerVersion": "27.1.0", "OperatingSystem": "Remote Linux", "Containers": 3})
+		}
+	})}
+	go api.Serve(daemon)
+	t.Cleanup(func() { api.Close() })
+
+	hostKey, _ := newTestSigner(t)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() == user && string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &sshServer{addr: listener.Addr().String(), hostKey: hostKey, socket: socket}
+	go func() {
+		for {
+			raw, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(raw, config)
+		}
+	}()
+	t.Cleanup(s.dropAll)
+	return s
+}
+
+func (s *sshServer) serve(raw net.Conn, config *ssh.ServerConfig) {
+	conn, chans, reqs, err := ssh.NewServerConn(raw, config)
+	if err != nil {
+		raw.Close()
+		return
+	}
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	s.handshake++
+	s.mu.Unlock()
+
+	go func() {
+		for req := range reqs {
+			req.Reply(req.Type == "keepalive@openssh.com", nil)
+		}
+	}()
+
+	for newChannel := range chans {
+		var target struct {
+			SocketPath string
+			Reserved0  string
+			Reserved1  uint32
+		}
+		if newChannel.ChannelType() != "direct-streamlocal@openssh.com" || ssh.Unmarshal(newChannel.ExtraData(), &target) != nil {
+			newChannel.Reject(ssh.UnknownChannelType, "unsupported channel")
+			continue
+		}
+		s.mu.Lock()
+		s.forwarded = append(s.forwarded, target.SocketPath)
+		s.mu.Unlock()
+		if target.SocketPath != s.socket {
+			newChannel.Reject(ssh.Prohibited, "forwarding to "+target.SocketPath+" is not allowed")
+			continue
+		}
+
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go ssh.DiscardRequests(requests)
+		go func() {
+			defer channel.Close()
+			daemon, err := net.Dial("unix", s.socket)
+			if err != nil {
+				return
+			}
+			defer daemon.Close()
+			go io.Copy(daemon, channel)
+			io.Copy(channel, daemon)
+		}()
+	}
+}
+
+func (s *sshServer) dropAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func (s *sshServer) stats() (int, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.handshake, append([]string(nil), s.forwarded...)
+}
+
+func (s *sshServer) knownHosts(key ssh.PublicKey) string {
+	return knownhosts.Line([]string{knownhosts.Normalize(s.addr)}, key)
+}
+
+func TestSSHClient(t *testing.T) {
+	clientKey, privateKey := newTestSigner(t)
+	server := startSSHServer(t, "deploy", clientKey.PublicKey())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cli, err := NewSSHClient("ssh://deploy@"+server.addr+server.socket, SSHMaterial{
+		PrivateKey: privateKey,
+		KnownHosts: server.knownHosts(server.hostKey.PublicKey()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	status, err := cli.Info(ctx)
+	if err != nil {
+		t.Fatalf("info over ssh: %v", err)
+	}
+	if !status.Online || status.ServerVersion != "27.1.0" || status.OS != "Remote Linux" {
+		t.Errorf("status = %+v", status)
+	}
+	if handshakes, forwarded := server.stats(); handshakes != 1 || len(forwarded) == 0 || forwarded[0] != server.socket {
+		t.Errorf("%d handshakes, forwarded to %v; want 1 handshake forwarding to %s", handshakes, forwarded, server.socket)
+	}
+
+	server.dropAll()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cli.ssh.mu.Lock()
+		dropped := cli.ssh.conn == nil
+		cli.ssh.mu.Unlock()
+		if dropped || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := cli.Info(ctx); err != nil {
+		t.Fatalf("info after the server dropped the connection: %v", err)
+	}
+	if handshakes, _ := server.stats(); handshakes != 2 {
+		t.Errorf("%d handshakes after reconnecting, want 2", handshakes)
+	}
+}
+
+func TestSSHClientRejectsServer(t *testing.T) {
+	clientKey, privateKey := newTestSigner(t)
+	server := startSSHServer(t, "deploy", clientKey.PublicKey())
+	otherKey, _ := newTestSigner(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cases := []struct {
+		name       string
+		endpoint   string
+		knownHosts string
+		want       string
+	}{
+		{
+			name:       "host key mismatch",
+			endpoint:   "ssh://deploy@" + server.addr + server.socket,
+			knownHosts: server.knownHosts(otherKey.PublicKey()),
+			want:       "key mismatch",
+		},
+		{
+			name:       "unknown host",
+			endpoint:   "ssh://deploy@" + server.addr + server.socket,
+			knownHosts: knownhosts.Line([]string{"[127.0.0.2]:22"}, server.hostKey.PublicKey()),
+			want:       "key is unknown",
+		},
+		{
+			name:       "key not authorized",
+			endpoint:   "ssh://root@" + server.addr + server.socket,
+			knownHosts: server.knownHosts(server.hostKey.PublicKey()),
+			want:       "unable to authenticate",
+		},
+		{
+			name:       "forwarding refused",
+			endpoint:   "ssh://deploy@" + server.addr + "/var/run/other.sock",
+			knownHosts: server.knownHosts(server.hostKey.PublicKey()),
+			want:       "refused forwarding to /var/run/other.sock",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cli, err := NewSSHClient(tc.endpoint, SSHMaterial{PrivateKey: privateKey, KnownHosts: tc.knownHosts})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cli.Close()
+
+			if _, err := cli.Info(ctx); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tc.want)
+			}
+		})
+	}
+
+	if handshakes, _ := server.stats(); handshakes != 1 {
+		t.Errorf("%d handshakes; only the forwarding case should get that far", handshakes)
+	}
+}
@@ -0,0 +1,183 @@
+package docker
+
+import (
+	"context"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/docker/docker/client"
+)
+
+func (c *Client) ListVolumes(ctx context.Context) ([]models.Volume, error) {
+	list, err := c.cli.VolumeList(ctx, volume.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	sizes, err := c.volumeSizes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := c.volumeUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.Volume, 0, len(list.Volumes))
+	for _, vol := range list.Volumes {
+		result = append(result, buildVolume(vol, sizes, usage))
+	}
+
+	return result, nil
+}
+
+func (c *Client) InspectVolume(ctx context.Context, name string) (*models.Volume, error) {
+	vol, err := c.cli.VolumeInspect(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes, err := c.volumeSizes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := c.volumeUsage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildVolume(&vol, sizes, usage)
+	return &result, nil
+}
+
+func (c *Client) VolumeExists(ctx context.Context, name string) (bool, error) {
+	_, err := c.cli.VolumeInspect(ctx, name)
+	if err == nil {
+		return true, nil
+	}
+	if client.IsErrNotFound(err) {
+		return false, nil
+	}
+	return false, err
+}
+
+func (c *Client) CreateVolume(ctx context.Context, req models.CreateVolumeRequest) (*models.Volume, error) {
+	vol, err := c.cli.VolumeCreate(ctx, volume.CreateOptions{
+		Name:       req.Name,
+		Driver:     req.Driver,
+		DriverOpts: req.DriverOpts,
+		Labels:     req.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildVolume(&vol, nil, nil)
+	return &result, nil
+}
+
+func (c *Client) RemoveVolume(ctx context.Context, name string, force bool) error {
+	return c.cli.VolumeRemove(ctx, name, force)
+}
+
+func (c *Client) PruneVolumes(ctx context.Context, all bool) (*models.PruneVolumesResult, error) {
+	args := filters.NewArgs()
+	if all {
+		args.Add("all", "true")
+	}
+
+	report, err := c.cli.VolumesPrune(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := report.VolumesDeleted
+	if deleted == nil {
+		deleted = []string{}
+	}
+
+	return &models.PruneVolumesResult{
+		VolumesDeleted: deleted,
+		SpaceReclaimed: report.SpaceReclaimed,
+	}, nil
+}
+
+func (c *Client) volumeSizes(ctx context.Context) (map[string]*volume.UsageData, error) {
+	du, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{Types: []types.DiskUsageObject{types.VolumeObject}})
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[string]*volume.UsageData, len(du.Volumes))
+	for _, vol := range du.Volumes {
+		if vol.UsageData != nil {
+			sizes[vol.Name] = vol.UsageData
+		}
+	}
+
+	return sizes, nil
+}
+
+func (c *Client) volumeUsage(ctx context.Context) (map[string][]models.VolumeUsage, error) {
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make(map[string][]models.VolumeUsage)
+	for _, cont := range containers {
+		name := ""
+		if len(cont.Names) > 0 {
+			name = cont.Names[0]
+		}
+		for _, mount := range cont.Mounts {
+			if mount.Type != "volume" || mount.Name == "" {
+				continue
+			}
+			usage[mount.Name] = append(usage[mount.Name], models.VolumeUsage{
+				ContainerID:   cont.ID,
+				ContainerName: name,
+				State:         cont.State,
+				Destination:   mount.Destination,
+				RW:            mount.RW,
+			})
+		}
+	}
+
+	return usage, nil
+}
+
+func buildVolume(vol *volume.Volume, sizes map[string]*volume.UsageData, usage map[string][]models.VolumeUsage) models.Volume {
+	result := models.Volume{
+		Name:       vol.Name,
+		Driver:     vol.Driver,
+		Mountpoint: vol.Mountpoint,
+		CreatedAt:  vol.CreatedAt,
+		Scope:      vol.Scope,
+		Labels:     vol.Labels,
+		Options:    vol.Options,
+		Size:       -1,
+		RefCount:   -1,
+		UsedBy:     usage[vol.Name],
+	}
+
+	if data, ok := sizes[vol.Name]; ok {
+		result.Size = data.Size
+		result.RefCount = data.RefCount
+	} else if vol.UsageData != nil {
+		result.Size = vol.UsageData.Size
+		result.RefCount = vol.UsageData.RefCount
+	}
+
+	if result.UsedBy == nil {
+		result.UsedBy = []models.VolumeUsage{}
+	}
+
+	return result
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	writer    *audit.Writer
+	forwarder *audit.Forwarder
+	chain     *audit.Chain
+	db        database.Store
+}
+
+func NewAuditHandler(writer *audit.Writer, forwarder *audit.Forwarder, chain *audit.Chain, db database.Store) *AuditHandler {
+	return &AuditHandler{
+		writer:    writer,
+		forwarder: forwarder,
+		chain:     chain,
+		db:        db,
+	}
+}
+
+func (h *AuditHandler) Health(c *gin.Context) {
+	stats := h.writer.Stats()
+	if h.forwarder != nil {
+		forwarding := h.forwarder.Stats()
+		stats.Forwarding = &forwarding
+	}
+
+	status := http.StatusOK
+	if !stats.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, stats)
+}
+
+func (h *AuditHandler) Export(c *gin.Context) {
+	format := c.DefaultQuery("format", audit.FormatCSV)
+	contentType, ok := audit.ContentType(format)
+	if !ok {
+		badRequest(c, fmt.Sprintf("unsupported export format %q", format))
+		return
+	}
+
+	filter, err := activityFilter(c)
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+	if id := c.Query("containerId"); id != "" {
+		filter.ContainerID = id
+	}
+
+	if _, err := h.db.ScanLogs(filter, 0, 1); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	extension := format
+	if format == audit.FormatSyslog {
+		extension = "log"
+	}
+	fileName := fmt.Sprintf("activity-%s.%s", time.Now().UTC().Format("20060102-150405"), extension)
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Status(http.StatusOK)
+
+	enc, err := audit.NewEncoder(format, c.Writer)
+	if err != nil {
+		log.Printf("Activity log export failed: %v", err)
+		c.Abort()
+		return
+	}
+
+	exported, err := audit.Export(h.db, filter, enc, c.Writer.Flush)
+	if err != nil {
+		log.Printf("Activity log export failed after %d records: %v", exported, err)
+		c.Abort()
+		return
+	}
+
+	audit.Record(c, "system", "activity_log", "export_logs", fmt.Sprintf("format=%s records=%d", format, exported))
+}
+
+func (h *AuditHandler) Verify(c *gin.Context) {
+	result, err := h.chain.Verify()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !result.Valid {
+		log.Printf("Activity log verification failed at entry %d: %s", result.FirstBroken.LogID, result.FirstBroken.Reason)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *AuditHandler) ListCheckpoints(c *gin.Context) {
+	checkpoints, err := h.chain.Checkpoints()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checkpoints)
+}
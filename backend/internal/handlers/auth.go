@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/auth"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	service      *auth.Service
+	db           database.Store
+	secureCookie bool
+}
+
+func NewAuthHandler(service *auth.Service, db database.Store) *AuthHandler {
+	return &AuthHandler{
+		service:      service,
+		db:           db,
+		secureCookie: os.Getenv("COOKIE_SECURE") == "true",
+	}
+}
+
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	sessionID, identity, err := h.service.Login(req.Username, req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			audit.Record(c, "system", req.Username, "login_failed", "Invalid credentials")
+		}
+		respondError(c, err)
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(auth.SessionCookie, sessionID, int(h.service.SessionTTL().Seconds()), "/", "", h.secureCookie, true)
+	audit.RecordEntry(c, audit.Entry{
+		ContainerID:   "system",
+		ContainerName: identity.Username,
+		Action:        "login",
+		Actor:         audit.ActorFor(c, identity),
+		Details:       "Logged in via " + identity.Method,
+	})
+	c.JSON(http.StatusOK, identity)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	identity := auth.IdentityFrom(c)
+	if err := h.service.Logout(identity); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(auth.SessionCookie, "", -1, "/", "", h.secureCookie, true)
+	audit.Record(c, "system", identity.Username, "logout", "Logged out")
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (h *AuthHandler) Me(c *gin.Context) {
+	c.JSON(http.StatusOK, auth.IdentityFrom(c))
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	identity := auth.IdentityFrom(c)
+	if err := h.service.ChangePassword(identity, req.CurrentPassword, req.NewPassword); err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			err = apierror.Unauthenticated("current password is incorrect")
+		}
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", identity.Username, "change_password", "Password changed")
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+func (h *AuthHandler) ListUsers(c *gin.Context) {
+	users, err := h.db.ListUsers()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+func (h *AuthHandler) CreateUser(c *gin.Context) {
+	var req models.CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	user, err := h.service.CreateUser(req.Username, req.Password)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", user.Username, "create_user", "User created")
+	c.JSON(http.StatusCreated, user)
+}
+
+func (h *AuthHandler) DeleteUser(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, "Invalid user id")
+		return
+	}
+
+	if identity := auth.IdentityFrom(c); identity.UserID == id {
+		badRequest(c, "You cannot delete your own user")
+		return
+	}
+
+	user, err := h.db.GetUser(id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			respondError(c, apierror.NotFound("User not found"))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	if err := h.db.DeleteUser(id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", user.Username, "delete_user", "User deleted with sessions and tokens")
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
+}
+
+func (h *AuthHandler) ListTokens(c *gin.Context) {
+	tokens, err := h.db.ListAPITokens(auth.IdentityFrom(c).UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) CreateToken(c *gin.Context) {
+	var req models.CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	if req.ExpiresInDays < 0 {
+		badRequest(c, "expiresInDays must not be negative")
+		return
+	}
+
+	identity := auth.IdentityFrom(c)
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	raw, token, err := h.service.CreateToken(identity.UserID, req.Name, ttl)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("API token %q created (expires in %d days)", token.Name, req.ExpiresInDays)
+	audit.Record(c, "system", identity.Username, "create_token", details)
+	c.JSON(http.StatusCreated, gin.H{"token": raw, "details": token})
+}
+
+func (h *AuthHandler) RevokeToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, "Invalid token id")
+		return
+	}
+
+	identity := auth.IdentityFrom(c)
+	if err := h.db.RevokeAPIToken(id, identity.UserID, time.Now()); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			respondError(c, apierror.NotFound("Token not found"))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", identity.Username, "revoke_token", fmt.Sprintf("API token %d revoked", id))
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/backup"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/hosts"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BackupHandler struct {
+	service *backup.Service
+	hosts   *hosts.Pool
+	db      database.Store
+}
+
+func NewBackupHandler(service *backup.Service, pool *hosts.Pool, db database.Store) *BackupHandler {
+	return &BackupHandler{
+		service: service,
+		hosts:   pool,
+		db:      db,
+	}
+}
+
+func (h *BackupHandler) backups(c *gin.Context) *backup.Service {
+	return h.service.On(h.hosts.Name(c), h.hosts.From(c))
+}
+
+func (h *BackupHandler) ListBackups(c *gin.Context) {
+	backups, err := h.db.ListVolumeBackups(h.hosts.Name(c), c.Query("volume"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+func (h *BackupHandler) ListVolumeBackups(c *gin.Context) {
+	backups, err := h.db.ListVolumeBackups(h.hosts.Name(c), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+func (h *BackupHandler) CreateBackup(c *gin.Context) {
+	volumeName := c.Param("name")
+
+	var opts backup.Options
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&opts); err != nil {
+			badRequest(c, err.Error())
+			return
+		}
+	}
+
+	record, err := h.backups(c).Backup(c.Request.Context(), volumeName, opts)
+	if err != nil {
+		audit.Record(c, "system", volumeName, "backup_volume_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Volume backed up to %s (%d bytes, sha256 %s)", record.FileName, record.SizeBytes, record.Checksum)
+	audit.Record(c, "system", volumeName, "backup_volume", details)
+	c.JSON(http.StatusCreated, record)
+}
+
+func (h *BackupHandler) ExportVolume(c *gin.Context) {
+	volumeName := c.Param("name")
+	fileName := fmt.Sprintf("%s-%s.tar.gz", volumeName, time.Now().UTC().Format("20060102T150405Z"))
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	err := h.backups(c).Export(c.Request.Context(), volumeName, c.Writer)
+	if err != nil {
+		audit.Record(c, "system", volumeName, "export_volume_failed", err.Error())
+		if !c.Writer.Written() {
+			c.Header("Content-Disposition", "")
+			respondError(c, err)
+		} else {
+			c.Error(err)
+		}
+		return
+	}
+
+	audit.Record(c, "system", volumeName, "export_volume", "Volume exported to client")
+}
+
+func (h *BackupHandler) GetBackup(c *gin.Context) {
+	id, ok := backupID(c)
+	if !ok {
+		return
+	}
+
+	record, err := h.backups(c).Get(id)
+	if err != nil {
+		respondBackupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+func (h *BackupHandler) DownloadBackup(c *gin.Context) {
+	id, ok := backupID(c)
+	if !ok {
+		return
+	}
+
+	record, file, err := h.backups(c).Open(id)
+	if err != nil {
+		respondBackupError(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", "application/gzip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", record.FileName))
+	c.Header("Content-Length", strconv.FormatInt(record.SizeBytes, 10))
+	c.Header("X-Checksum-Sha256", record.Checksum)
+	c.Status(http.StatusOK)
+	written, err := io.Copy(c.Writer, file)
+	if err != nil {
+		details := fmt.Sprintf("Download of %s stopped after %d of %d bytes: %v", record.FileName, written, record.SizeBytes, err)
+		audit.Record(c, "system", record.VolumeName, "download_backup_failed", details)
+		c.Error(err)
+		return
+	}
+
+	audit.Record(c, "system", record.VolumeName, "download_backup", fmt.Sprintf("Backup %s downloaded (%d bytes)", record.FileName, written))
+}
+
+func (h *BackupHandler) RestoreBackup(c *gin.Context) {
+	id, ok := backupID(c)
+	if !ok {
+		return
+	}
+
+	var opts backup.RestoreOptions
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&opts); err != nil {
+			badRequest(c, err.Error())
+			return
+		}
+	}
+
+	record, err := h.backups(c).Restore(c.Request.Context(), id, opts)
+	if err != nil {
+		audit.Record(c, "system", fmt.Sprintf("backup-%d", id), "restore_volume_failed", err.Error())
+		respondBackupError(c, err)
+		return
+	}
+
+	target := opts.TargetVolume
+	if target == "" {
+		target = record.VolumeName
+	}
+
+	details := fmt.Sprintf("Restored backup %s into volume %s (replace: %v)", record.FileName, target, opts.Replace)
+	audit.Record(c, "system", target, "restore_volume", details)
+	c.JSON(http.StatusOK, gin.H{"message": "Backup restored successfully", "volume": target})
+}
+
+func (h *BackupHandler) DeleteBackup(c *gin.Context) {
+	id, ok := backupID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.backups(c).Delete(id); err != nil {
+		respondBackupError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", fmt.Sprintf("backup-%d", id), "delete_backup", "Backup deleted successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Backup deleted successfully"})
+}
+
+func backupID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, "Invalid backup id")
+		return 0, false
+	}
+	return id, true
+}
+
+func respondBackupError(c *gin.Context, err error) {
+	if errors.Is(err, database.ErrNotFound) {
+		respondError(c, apierror.NotFound("Backup not found"))
+		return
+	}
+	respondError(c, err)
+}
@@ -1,147 +1,194 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/containerlogs"
 	"docker-gui-backend/internal/database"
 	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/internal/rbac"
 	"docker-gui-backend/pkg/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 func (h *ContainerHandler) GetActivityLogs(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "100")
-	limit, err := strconv.Atoi(limitStr)
+	filter, err := activityFilter(c)
 	if err != nil {
-		limit = 100
+		badRequest(c, err.Error())
+		return
 	}
-	
-	logs, err := h.db.GetAllLogs(limit)
+
+	page, err := h.db.SearchLogs(filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	c.JSON(http.StatusOK, page)
 }
 
 func (h *ContainerHandler) GetContainerActivityLogs(c *gin.Context) {
-	containerID := c.Param("id")
-	limitStr := c.DefaultQuery("limit", "100")
-	limit, err := strconv.Atoi(limitStr)
+	filter, err := activityFilter(c)
 	if err != nil {
-		limit = 100
+		badRequest(c, err.Error())
+		return
 	}
-	
-	logs, err := h.db.GetContainerLogs(containerID, limit)
+	filter.ContainerID = c.Param("id")
+
+	page, err := h.db.SearchLogs(filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	c.JSON(http.StatusOK, page)
+}
+
+func activityFilter(c *gin.Context) (database.LogFilter, error) {
+	filter := database.LogFilter{
+		ContainerName: c.Query("container"),
+		Actor:         c.Query("actor"),
+		Search:        c.Query("q"),
+		Limit:         database.DefaultLogLimit,
+	}
+
+	for _, value := range c.QueryArray("action") {
+		for _, action := range strings.Split(value, ",") {
+			if action = strings.TrimSpace(action); action != "" {
+				filter.Actions = append(filter.Actions, action)
+			}
+		}
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", v)
+		}
+		if limit > database.MaxLogLimit {
+			limit = database.MaxLogLimit
+		}
+		filter.Limit = limit
+	}
+
+	if v := c.Query("cursor"); v != "" {
+		before, err := strconv.Atoi(v)
+		if err != nil || before <= 0 {
+			return filter, fmt.Errorf("invalid cursor %q", v)
+		}
+		filter.Before = before
+	}
+
+	var err error
+	if filter.Since, err = parseLogTime(c.Query("since"), false); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = parseLogTime(c.Query("until"), true); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		return filter, fmt.Errorf("until must not be before since")
+	}
+
+	return filter, nil
+}
+
+func parseLogTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not an RFC 3339 timestamp or YYYY-MM-DD date", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t, nil
 }
 
 type ContainerHandler struct {
-	dockerClient *docker.Client
-	db           *database.DB
+	hosts *hosts.Pool
+	db    database.Store
 }
 
-func NewContainerHandler(dockerClient *docker.Client, db *database.DB) *ContainerHandler {
+func NewContainerHandler(pool *hosts.Pool, db database.Store) *ContainerHandler {
 	return &ContainerHandler{
-		dockerClient: dockerClient,
-		db:           db,
+		hosts: pool,
+		db:    db,
 	}
 }
 
 func (h *ContainerHandler) ListContainers(c *gin.Context) {
 	all := c.DefaultQuery("all", "true") == "true"
 	
-	containers, err := h.dockerClient.ListContainers(c.Request.Context(), all)
+	containers, err := h.hosts.From(c).ListContainers(c.Request.Context(), all)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, containers)
+	c.JSON(http.StatusOK, rbac.FilterContainers(c, containers))
 }
 
 func (h *ContainerHandler) StartContainer(c *gin.Context) {
 	containerID := c.Param("id")
 	
-	containers, _ := h.dockerClient.ListContainers(c.Request.Context(), true)
-	containerName := "unknown"
-	for _, container := range containers {
-		if container.ID == containerID {
-			if len(container.Names) > 0 {
-				containerName = container.Names[0]
-			}
-			break
-		}
-	}
+	containerName := lookupContainerName(c.Request.Context(), h.hosts.From(c), containerID)
 	
-	err := h.dockerClient.StartContainer(c.Request.Context(), containerID)
+	err := h.hosts.From(c).StartContainer(c.Request.Context(), containerID)
 	if err != nil {
-		h.db.LogContainerAction(containerID, containerName, "start_failed", "docker-gui", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		audit.Record(c, containerID, containerName, "start_failed", err.Error())
+		respondError(c, err)
 		return
 	}
 
-	h.db.LogContainerAction(containerID, containerName, "start", "docker-gui", "Container started successfully")
+	audit.Record(c, containerID, containerName, "start", "Container started successfully")
 	c.JSON(http.StatusOK, gin.H{"message": "Container started successfully"})
 }
 
 func (h *ContainerHandler) StopContainer(c *gin.Context) {
 	containerID := c.Param("id")
 	
-	containers, _ := h.dockerClient.ListContainers(c.Request.Context(), true)
-	containerName := "unknown"
-	for _, container := range containers {
-		if container.ID == containerID {
-			if len(container.Names) > 0 {
-				containerName = container.Names[0]
-			}
-			break
-		}
-	}
+	containerName := lookupContainerName(c.Request.Context(), h.hosts.From(c), containerID)
 	
-	err := h.dockerClient.StopContainer(c.Request.Context(), containerID)
+	err := h.hosts.From(c).StopContainer(c.Request.Context(), containerID)
 	if err != nil {
-		h.db.LogContainerAction(containerID, containerName, "stop_failed", "docker-gui", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		audit.Record(c, containerID, containerName, "stop_failed", err.Error())
+		respondError(c, err)
 		return
 	}
 
-	h.db.LogContainerAction(containerID, containerName, "stop", "docker-gui", "Container stopped successfully")
+	audit.Record(c, containerID, containerName, "stop", "Container stopped successfully")
 	c.JSON(http.StatusOK, gin.H{"message": "Container stopped successfully"})
 }
 
 func (h *ContainerHandler) RestartContainer(c *gin.Context) {
 	containerID := c.Param("id")
 	
-	containers, _ := h.dockerClient.ListContainers(c.Request.Context(), true)
-	containerName := "unknown"
-	for _, container := range containers {
-		if container.ID == containerID {
-			if len(container.Names) > 0 {
-				containerName = container.Names[0]
-			}
-			break
-		}
-	}
+	containerName := lookupContainerName(c.Request.Context(), h.hosts.From(c), containerID)
 	
-	err := h.dockerClient.RestartContainer(c.Request.Context(), containerID)
+	err := h.hosts.From(c).RestartContainer(c.Request.Context(), containerID)
 	if err != nil {
-		h.db.LogContainerAction(containerID, containerName, "restart_failed", "docker-gui", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		audit.Record(c, containerID, containerName, "restart_failed", err.Error())
+		respondError(c, err)
 		return
 	}
 
-	h.db.LogContainerAction(containerID, containerName, "restart", "docker-gui", "Container restarted successfully")
+	audit.Record(c, containerID, containerName, "restart", "Container restarted successfully")
 	c.JSON(http.StatusOK, gin.H{"message": "Container restarted successfully"})
 }
 
@@ -149,47 +196,67 @@ func (h *ContainerHandler) RemoveContainer(c *gin.Context) {
 	containerID := c.Param("id")
 	force := c.DefaultQuery("force", "false") == "true"
 	
-	containers, _ := h.dockerClient.ListContainers(c.Request.Context(), true)
-	containerName := "unknown"
-	for _, container := range containers {
-		if container.ID == containerID {
-			if len(container.Names) > 0 {
-				containerName = container.Names[0]
-			}
-			break
-		}
-	}
+	containerName := lookupContainerName(c.Request.Context(), h.hosts.From(c), containerID)
 	
-	err := h.dockerClient.RemoveContainer(c.Request.Context(), containerID, force)
+	err := h.hosts.From(c).RemoveContainer(c.Request.Context(), containerID, force)
 	if err != nil {
-		h.db.LogContainerAction(containerID, containerName, "remove_failed", "docker-gui", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		audit.Record(c, containerID, containerName, "remove_failed", err.Error())
+		respondError(c, err)
 		return
 	}
 
 	details := fmt.Sprintf("Container removed successfully (force: %v)", force)
-	h.db.LogContainerAction(containerID, containerName, "remove", "docker-gui", details)
+	audit.Record(c, containerID, containerName, "remove", details)
 	c.JSON(http.StatusOK, gin.H{"message": "Container removed successfully"})
 }
 
+const (
+	defaultContainerLogLines = 100
+	maxContainerLogLines     = 10000
+)
+
 func (h *ContainerHandler) GetContainerLogs(c *gin.Context) {
 	containerID := c.Param("id")
 	linesStr := c.DefaultQuery("lines", "100")
 	
 	lines, err := strconv.Atoi(linesStr)
+	if err != nil || lines <= 0 {
+		lines = defaultContainerLogLines
+	}
+	if lines > maxContainerLogLines {
+		lines = maxContainerLogLines
+	}
+
+	format := c.DefaultQuery("format", "text")
+	if format != "text" && format != "structured" {
+		badRequest(c, fmt.Sprintf("unsupported log format %q", format))
+		return
+	}
+
+	filter, err := containerLogFilter(c)
 	if err != nil {
-		lines = 100
+		badRequest(c, err.Error())
+		return
 	}
 	
-	logEntries, err := h.dockerClient.GetContainerLogs(c.Request.Context(), containerID, lines)
+	logEntries, err := h.hosts.From(c).GetContainerLogs(c.Request.Context(), containerID, filter.Options(lines))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
+	matched := containerlogs.Apply(logEntries, filter)
 
-	// Convert LogEntry array to the format expected by frontend
-	logs := make([]string, len(logEntries))
-	for i, entry := range logEntries {
+	if format == "structured" {
+		c.JSON(http.StatusOK, models.ContainerLogs{
+			Entries:   matched,
+			Scanned:   len(logEntries),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	logs := make([]string, len(matched))
+	for i, entry := range matched {
 		logs[i] = fmt.Sprintf("[%s] %s", entry.Timestamp.Format("2006-01-02T15:04:05Z"), entry.Message)
 	}
 
@@ -201,12 +268,42 @@ func (h *ContainerHandler) GetContainerLogs(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func containerLogFilter(c *gin.Context) (containerlogs.Filter, error) {
+	filter := containerlogs.Filter{Contains: c.Query("q")}
+	now := time.Now()
+
+	var err error
+	if filter.Since, err = containerlogs.ParseTime(c.Query("since"), now); err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	if filter.Until, err = containerlogs.ParseTime(c.Query("until"), now); err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && filter.Until.Before(filter.Since) {
+		return filter, fmt.Errorf("until must not be before since")
+	}
+	if filter.Stream, err = containerlogs.ParseStream(c.Query("stream")); err != nil {
+		return filter, err
+	}
+	if filter.Pattern, err = containerlogs.ParsePattern(c.Query("regex")); err != nil {
+		return filter, err
+	}
+	if filter.Levels, err = containerlogs.ParseLevels(c.Query("level")); err != nil {
+		return filter, err
+	}
+	if filter.Query, err = containerlogs.ParseQuery(c.Query("query")); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
+
 func (h *ContainerHandler) GetContainerStats(c *gin.Context) {
 	containerID := c.Param("id")
 	
-	stats, err := h.dockerClient.GetContainerStats(c.Request.Context(), containerID)
+	stats, err := h.hosts.From(c).GetContainerStats(c.Request.Context(), containerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -215,8 +312,8 @@ func (h *ContainerHandler) GetContainerStats(c *gin.Context) {
 
 func (h *ContainerHandler) PerformAction(c *gin.Context) {
 	var action models.ContainerAction
-	if err := c.ShouldBindJSON(&action); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.ShouldBindBodyWith(&action, binding.JSON); err != nil {
+		badRequest(c, err.Error())
 		return
 	}
 	
@@ -230,6 +327,134 @@ func (h *ContainerHandler) PerformAction(c *gin.Context) {
 	case "remove":
 		h.RemoveContainer(c)
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid action"})
+		badRequest(c, "Invalid action")
 	}
-}
\ No newline at end of file
+}
+func lookupContainerName(ctx context.Context, dockerClient docker.API, containerID string) string {
+	containers, _ := dockerClient.ListContainers(ctx, true)
+	name := strings.TrimPrefix(containerID, "/")
+	var byName, byPrefix []string
+	for _, container := range containers {
+		if len(container.Names) == 0 {
+			continue
+		}
+		if container.ID == containerID {
+			return container.Names[0]
+		}
+		for _, n := range container.Names {
+			if strings.TrimPrefix(n, "/") == name {
+				byName = append(byName, n)
+			}
+		}
+		if containerID != "" && strings.HasPrefix(container.ID, containerID) {
+			byPrefix = append(byPrefix, container.Names[0])
+		}
+	}
+	switch {
+	case len(byName) > 0:
+		return byName[0]
+	case len(byPrefix) == 1:
+		return byPrefix[0]
+	}
+	return "unknown"
+}
+
+var psArgsPattern = regexp.MustCompile(`^[A-Za-z0-9 ,=_-]*$`)
+
+func (h *ContainerHandler) GetContainerTop(c *gin.Context) {
+	containerID := c.Param("id")
+	psArgs := c.DefaultQuery("ps_args", "-ef")
+
+	if !psArgsPattern.MatchString(psArgs) {
+		badRequest(c, "Invalid ps_args")
+		return
+	}
+
+	processes, err := h.hosts.From(c).ContainerTop(c.Request.Context(), containerID, strings.Fields(psArgs))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, processes)
+}
+
+func (h *ContainerHandler) UpdateContainerResources(c *gin.Context) {
+	containerID := c.Param("id")
+
+	var req models.UpdateResourcesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	containerName := lookupContainerName(ctx, h.hosts.From(c), containerID)
+
+	before, err := h.hosts.From(c).GetContainerResources(ctx, containerID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	warnings, err := h.hosts.From(c).UpdateContainerResources(ctx, containerID, req)
+	if err != nil {
+		audit.Record(c, containerID, containerName, "update_resources_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	after, err := h.hosts.From(c).GetContainerResources(ctx, containerID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	details := "Resources updated: " + describeResourceChanges(before, after)
+	audit.Record(c, containerID, containerName, "update_resources", details)
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Container resources updated successfully",
+		"previous":  before,
+		"resources": after,
+		"warnings":  warnings,
+	})
+}
+
+func describeResourceChanges(before, after *models.ContainerResources) string {
+	fields := []struct {
+		name     string
+		old, new interface{}
+	}{
+		{"cpuShares", before.CPUShares, after.CPUShares},
+		{"cpuPeriod", before.CPUPeriod, after.CPUPeriod},
+		{"cpuQuota", before.CPUQuota, after.CPUQuota},
+		{"nanoCpus", before.NanoCPUs, after.NanoCPUs},
+		{"memory", before.Memory, after.Memory},
+		{"memoryReservation", before.MemoryReservation, after.MemoryReservation},
+		{"memorySwap", before.MemorySwap, after.MemorySwap},
+		{"pidsLimit", before.PidsLimit, after.PidsLimit},
+		{"restartPolicy", formatRestartPolicy(before.RestartPolicy), formatRestartPolicy(after.RestartPolicy)},
+	}
+
+	var changes []string
+	for _, field := range fields {
+		if field.old != field.new {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", field.name, field.old, field.new))
+		}
+	}
+
+	if len(changes) == 0 {
+		return "no changes"
+	}
+	return strings.Join(changes, ", ")
+}
+
+func formatRestartPolicy(policy models.RestartPolicy) string {
+	if policy.Name == "" {
+		return "no"
+	}
+	if policy.Name == "on-failure" && policy.MaximumRetryCount > 0 {
+		return fmt.Sprintf("%s:%d", policy.Name, policy.MaximumRetryCount)
+	}
+	return policy.Name
+}
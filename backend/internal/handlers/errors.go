@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/auth"
+	"docker-gui-backend/internal/backup"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/internal/rbac"
+	"docker-gui-backend/internal/stacks"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errorStatus = []struct {
+	status int
+	code   string
+	errs   []error
+}{
+	{http.StatusNotFound, apierror.CodeNotFound, []error{
+		hosts.ErrHostNotFound, hosts.ErrKeyNotFound,
+		stacks.ErrStackNotFound, stacks.ErrServiceNotFound,
+		rbac.ErrRoleNotFound,
+	}},
+	{http.StatusBadRequest, apierror.CodeInvalidArgument, []error{
+		hosts.ErrInvalidHost, hosts.ErrBuiltinHost, hosts.ErrInvalidKey,
+		stacks.ErrInvalidAction,
+		rbac.ErrBuiltinRole, rbac.ErrInvalidRole, rbac.ErrInvalidPermission, rbac.ErrInvalidScope,
+		auth.ErrWeakPassword, auth.ErrUsernameRequired, auth.ErrTokenNameRequired,
+		docker.ErrNotDirectory,
+	}},
+	{http.StatusConflict, apierror.CodeConflict, []error{
+		hosts.ErrHostExists, hosts.ErrDefaultHost, hosts.ErrKeyExists, hosts.ErrKeyInUse,
+		rbac.ErrRoleExists, rbac.ErrRoleInUse, rbac.ErrLastAdmin,
+		auth.ErrUserExists,
+		backup.ErrChecksumMismatch,
+	}},
+	{http.StatusUnauthorized, apierror.CodeUnauthenticated, []error{
+		auth.ErrInvalidCredentials,
+	}},
+}
+
+func classify(err error) *apierror.Error {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	for _, group := range errorStatus {
+		for _, target := range group.errs {
+			if errors.Is(err, target) {
+				return apierror.Wrap(err, group.status, group.code)
+			}
+		}
+	}
+	return apierror.From(err)
+}
+
+func respondError(c *gin.Context, err error) {
+	apierror.Respond(c, classify(err))
+}
+
+func badRequest(c *gin.Context, message string) {
+	apierror.Respond(c, apierror.BadRequest(message))
+}
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/hosts"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultMaxUploadSize = 512 << 20
+
+type FilesystemHandler struct {
+	hosts         *hosts.Pool
+	maxUploadSize int64
+}
+
+func NewFilesystemHandler(pool *hosts.Pool) *FilesystemHandler {
+	maxUploadSize := int64(defaultMaxUploadSize)
+	if v, err := strconv.ParseInt(os.Getenv("MAX_UPLOAD_SIZE"), 10, 64); err == nil && v > 0 {
+		maxUploadSize = v
+	}
+
+	return &FilesystemHandler{
+		hosts:         pool,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func (h *FilesystemHandler) ListFiles(c *gin.Context) {
+	containerID := c.Param("id")
+	dir, ok := containerPath(c, c.DefaultQuery("path", "/"))
+	if !ok {
+		return
+	}
+
+	containerName := lookupContainerName(c.Request.Context(), h.hosts.From(c), containerID)
+
+	entries, err := h.hosts.From(c).ListDirectory(c.Request.Context(), containerID, dir)
+	if err != nil {
+		audit.Record(c, containerID, containerName, "list_files_failed", fmt.Sprintf("path=%s: %v", dir, err))
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, containerID, containerName, "list_files", fmt.Sprintf("path=%s (%d entries)", dir, len(entries)))
+	c.JSON(http.StatusOK, gin.H{"path": dir, "entries": entries})
+}
+
+func (h *FilesystemHandler) DownloadFile(c *gin.Context) {
+	containerID := c.Param("id")
+	srcPath, ok := containerPath(c, c.Query("path"))
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	containerName := lookupContainerName(ctx, h.hosts.From(c), containerID)
+
+	reader, stat, err := h.hosts.From(c).CopyFromContainer(ctx, containerID, srcPath)
+	if err == nil {
+		if target := docker.LinkTarget(srcPath, *stat); target != "" {
+			reader.Close()
+			reader, stat, err = h.hosts.From(c).CopyFromContainer(ctx, containerID, target)
+		}
+	}
+	if err != nil {
+		audit.Record(c, containerID, containerName, "download_file_failed", fmt.Sprintf("path=%s: %v", srcPath, err))
+		respondError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	if stat.Mode.IsRegular() {
+		tr := tar.NewReader(reader)
+		if _, err := tr.Next(); err != nil {
+			audit.Record(c, containerID, containerName, "download_file_failed", fmt.Sprintf("path=%s: %v", srcPath, err))
+			respondError(c, err)
+			return
+		}
+
+		c.Header("Content-Type", "application/octet-stream")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", stat.Name))
+		c.Header("Content-Length", strconv.FormatInt(stat.Size, 10))
+		c.Status(http.StatusOK)
+		written, err := io.Copy(c.Writer, tr)
+		recordDownload(c, containerID, containerName, fmt.Sprintf("path=%s (file, %d of %d bytes)", srcPath, written, stat.Size), err)
+		return
+	}
+
+	c.Header("Content-Type", "application/x-tar")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", stat.Name+".tar"))
+	c.Status(http.StatusOK)
+	written, err := io.Copy(c.Writer, reader)
+	recordDownload(c, containerID, containerName, fmt.Sprintf("path=%s (directory archive, %d bytes)", srcPath, written), err)
+}
+
+func recordDownload(c *gin.Context, containerID, containerName, details string, err error) {
+	if err != nil {
+		audit.Record(c, containerID, containerName, "download_file_failed", details+": "+err.Error())
+		c.Error(err)
+		return
+	}
+	audit.Record(c, containerID, containerName, "download_file", details)
+}
+
+func (h *FilesystemHandler) UploadFiles(c *gin.Context) {
+	containerID := c.Param("id")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize)
+	form, err := c.MultipartForm()
+	if err != nil {
+		respondBodyError(c, err)
+		return
+	}
+
+	destDir, ok := containerPath(c, c.PostForm("path"))
+	if !ok {
+		return
+	}
+
+	opts, err := uploadOptions(c)
+	if err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	headers := form.File["files"]
+	if len(headers) == 0 {
+		badRequest(c, "at least one file is required")
+		return
+	}
+
+	var files []docker.UploadFile
+	var paths []string
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			badRequest(c, err.Error())
+			return
+		}
+		defer file.Close()
+
+		files = append(files, docker.UploadFile{
+			Name:    header.Filename,
+			Size:    header.Size,
+			Content: file,
+		})
+		paths = append(paths, path.Join(destDir, path.Base(header.Filename)))
+	}
+
+	ctx := c.Request.Context()
+	containerName := lookupContainerName(ctx, h.hosts.From(c), containerID)
+	details := fmt.Sprintf("paths=%s (uid: %d, gid: %d, mode: %04o)", strings.Join(paths, ","), opts.UID, opts.GID, opts.Mode)
+
+	if err := h.hosts.From(c).UploadFiles(ctx, containerID, destDir, files, opts); err != nil {
+		audit.Record(c, containerID, containerName, "upload_files_failed", details+": "+err.Error())
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, containerID, containerName, "upload_files", details)
+	c.JSON(http.StatusOK, gin.H{"message": "Files uploaded successfully", "paths": paths})
+}
+
+func (h *FilesystemHandler) GetChanges(c *gin.Context) {
+	containerID := c.Param("id")
+	ctx := c.Request.Context()
+	containerName := lookupContainerName(ctx, h.hosts.From(c), containerID)
+
+	changes, err := h.hosts.From(c).ContainerChanges(ctx, containerID)
+	if err != nil {
+		audit.Record(c, containerID, containerName, "view_changes_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, containerID, containerName, "view_changes", fmt.Sprintf("%d changed paths", len(changes)))
+	c.JSON(http.StatusOK, changes)
+}
+
+func respondBodyError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondError(c, err)
+		return
+	}
+	badRequest(c, err.Error())
+}
+
+func containerPath(c *gin.Context, p string) (string, bool) {
+	if !path.IsAbs(p) {
+		badRequest(c, "path must be absolute")
+		return "", false
+	}
+	return path.Clean(p), true
+}
+
+func uploadOptions(c *gin.Context) (docker.UploadOptions, error) {
+	opts := docker.UploadOptions{
+		Mode:       0o644,
+		CopyUIDGID: c.PostForm("copyUidGid") == "true",
+	}
+
+	if v := c.PostForm("uid"); v != "" {
+		uid, err := strconv.Atoi(v)
+		if err != nil || uid < 0 {
+			return opts, fmt.Errorf("invalid uid %q", v)
+		}
+		opts.UID = uid
+	}
+
+	if v := c.PostForm("gid"); v != "" {
+		gid, err := strconv.Atoi(v)
+		if err != nil || gid < 0 {
+			return opts, fmt.Errorf("invalid gid %q", v)
+		}
+		opts.GID = gid
+	}
+
+	if v := c.PostForm("mode"); v != "" {
+		mode, err := strconv.ParseInt(v, 8, 64)
+		if err != nil || mode < 0 || mode > 0o7777 {
+			return opts, fmt.Errorf("invalid mode %q", v)
+		}
+		opts.Mode = mode
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/internal/rbac"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HostHandler struct {
+	hosts *hosts.Pool
+}
+
+func NewHostHandler(pool *hosts.Pool) *HostHandler {
+	return &HostHandler{hosts: pool}
+}
+
+func (h *HostHandler) ListHosts(c *gin.Context) {
+	list, err := h.hosts.Hosts(c.Request.Context(), c.DefaultQuery("status", "true") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *HostHandler) GetHost(c *gin.Context) {
+	host, err := h.hosts.Host(c.Request.Context(), c.Param("host"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, host)
+}
+
+func (h *HostHandler) CreateHost(c *gin.Context) {
+	var req models.HostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	host, err := h.hosts.Create(c.Request.Context(), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", host.Name, "create_host", fmt.Sprintf("Docker host registered at %s", host.Endpoint))
+	c.JSON(http.StatusCreated, host)
+}
+
+func (h *HostHandler) UpdateHost(c *gin.Context) {
+	var req models.HostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	host, err := h.hosts.Update(c.Request.Context(), c.Param("host"), req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", host.Name, "update_host", fmt.Sprintf("Docker host updated to %s", host.Endpoint))
+	c.JSON(http.StatusOK, host)
+}
+
+func (h *HostHandler) DeleteHost(c *gin.Context) {
+	name := c.Param("host")
+	if err := h.hosts.Delete(name); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", name, "delete_host", "Docker host removed")
+	c.JSON(http.StatusOK, gin.H{"message": "Host removed successfully"})
+}
+
+func (h *HostHandler) ListSSHKeys(c *gin.Context) {
+	keys, err := h.hosts.Keys()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+func (h *HostHandler) CreateSSHKey(c *gin.Context) {
+	var req models.SSHKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	key, err := h.hosts.CreateKey(req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", key.Name, "create_ssh_key", fmt.Sprintf("SSH key added (%s)", key.Fingerprint))
+	c.JSON(http.StatusCreated, key)
+}
+
+func (h *HostHandler) DeleteSSHKey(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.hosts.DeleteKey(name); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", name, "delete_ssh_key", "SSH key removed")
+	c.JSON(http.StatusOK, gin.H{"message": "SSH key removed successfully"})
+}
+
+func (h *HostHandler) ListContainers(c *gin.Context) {
+	all := c.DefaultQuery("all", "true") == "true"
+
+	items, failures, err := hosts.Collect(c.Request.Context(), h.hosts,
+		func(ctx context.Context, client docker.API) ([]models.Container, error) {
+			return client.ListContainers(ctx, all)
+		},
+		func(container *models.Container, host string) { container.Host = host },
+	)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": rbac.FilterContainers(c, items), "errors": failures})
+}
+
+func (h *HostHandler) ListImages(c *gin.Context) {
+	items, failures, err := hosts.Collect(c.Request.Context(), h.hosts,
+		func(ctx context.Context, client docker.API) ([]models.Image, error) {
+			return client.ListImages(ctx)
+		},
+		func(image *models.Image, host string) { image.Host = host },
+	)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "errors": failures})
+}
+
+func (h *HostHandler) ListVolumes(c *gin.Context) {
+	items, failures, err := hosts.Collect(c.Request.Context(), h.hosts,
+		func(ctx context.Context, client docker.API) ([]models.Volume, error) {
+			return client.ListVolumes(ctx)
+		},
+		func(volume *models.Volume, host string) { volume.Host = host },
+	)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "errors": failures})
+}
+
+func (h *HostHandler) ListNetworks(c *gin.Context) {
+	items, failures, err := hosts.Collect(c.Request.Context(), h.hosts,
+		func(ctx context.Context, client docker.API) ([]models.NetworkResource, error) {
+			return client.ListNetworks(ctx)
+		},
+		func(network *models.NetworkResource, host string) { network.Host = host },
+	)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "errors": failures})
+}
@@ -3,29 +3,27 @@ package handlers
 import (
 	"net/http"
 
-	"docker-gui-backend/internal/database"
-	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/hosts"
 	"docker-gui-backend/pkg/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ImageHandler struct {
-	dockerClient *docker.Client
-	db           *database.DB
+	hosts *hosts.Pool
 }
 
-func NewImageHandler(dockerClient *docker.Client, db *database.DB) *ImageHandler {
+func NewImageHandler(pool *hosts.Pool) *ImageHandler {
 	return &ImageHandler{
-		dockerClient: dockerClient,
-		db:           db,
+		hosts: pool,
 	}
 }
 
 func (h *ImageHandler) ListImages(c *gin.Context) {
-	images, err := h.dockerClient.ListImages(c.Request.Context())
+	images, err := h.hosts.From(c).ListImages(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, err)
 		return
 	}
 
@@ -35,23 +33,23 @@ func (h *ImageHandler) ListImages(c *gin.Context) {
 func (h *ImageHandler) PullImage(c *gin.Context) {
 	var req models.PullImageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		badRequest(c, err.Error())
 		return
 	}
 
 	if req.ImageName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "imageName is required"})
+		badRequest(c, "imageName is required")
 		return
 	}
 
-	err := h.dockerClient.PullImage(c.Request.Context(), req.ImageName)
+	err := h.hosts.From(c).PullImage(c.Request.Context(), req.ImageName)
 	if err != nil {
-		h.db.LogContainerAction("system", req.ImageName, "pull_image_failed", "docker-gui", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		audit.Record(c, "system", req.ImageName, "pull_image_failed", err.Error())
+		respondError(c, err)
 		return
 	}
 
-	h.db.LogContainerAction("system", req.ImageName, "pull_image", "docker-gui", "Image pulled successfully")
+	audit.Record(c, "system", req.ImageName, "pull_image", "Image pulled successfully")
 	c.JSON(http.StatusOK, gin.H{"message": "Image pulled successfully"})
 }
 
@@ -59,25 +57,25 @@ func (h *ImageHandler) RemoveImage(c *gin.Context) {
 	imageID := c.Param("id")
 	force := c.DefaultQuery("force", "false") == "true"
 
-	err := h.dockerClient.RemoveImage(c.Request.Context(), imageID, force)
+	err := h.hosts.From(c).RemoveImage(c.Request.Context(), imageID, force)
 	if err != nil {
-		h.db.LogContainerAction("system", imageID, "remove_image_failed", "docker-gui", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		audit.Record(c, "system", imageID, "remove_image_failed", err.Error())
+		respondError(c, err)
 		return
 	}
 
-	h.db.LogContainerAction("system", imageID, "remove_image", "docker-gui", "Image removed successfully")
+	audit.Record(c, "system", imageID, "remove_image", "Image removed successfully")
 	c.JSON(http.StatusOK, gin.H{"message": "Image removed successfully"})
 }
 
 func (h *ImageHandler) PruneImages(c *gin.Context) {
-	err := h.dockerClient.PruneImages(c.Request.Context())
+	err := h.hosts.From(c).PruneImages(c.Request.Context())
 	if err != nil {
-		h.db.LogContainerAction("system", "images", "prune_images_failed", "docker-gui", err.Error())
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		audit.Record(c, "system", "images", "prune_images_failed", err.Error())
+		respondError(c, err)
 		return
 	}
 
-	h.db.LogContainerAction("system", "images", "prune_images", "docker-gui", "Images pruned successfully")
+	audit.Record(c, "system", "images", "prune_images", "Images pruned successfully")
 	c.JSON(http.StatusOK, gin.H{"message": "Images pruned successfully"})
 }
\ No newline at end of file
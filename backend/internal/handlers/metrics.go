@@ -7,14 +7,16 @@ import (
 	"strconv"
 	"time"
 
+	"docker-gui-backend/internal/apierror"
 	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/hosts"
 	"docker-gui-backend/pkg/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 type MetricsHandler struct {
-	dockerClient *docker.Client
+	hosts *hosts.Pool
 }
 
 type SystemMetrics struct {
@@ -54,15 +56,15 @@ type OverallMetrics struct {
 	Containers        []ContainerMetrics `json:"containers"`
 }
 
-func NewMetricsHandler(dockerClient *docker.Client) *MetricsHandler {
-	return &MetricsHandler{dockerClient: dockerClient}
+func NewMetricsHandler(pool *hosts.Pool) *MetricsHandler {
+	return &MetricsHandler{hosts: pool}
 }
 
 func (h *MetricsHandler) GetOverallMetrics(c *gin.Context) {
 	ctx := context.Background()
-	containers, err := h.dockerClient.ListContainers(ctx, true)
+	containers, err := h.hosts.From(c).ListContainers(ctx, true)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch containers"})
+		respondError(c, err)
 		return
 	}
 
@@ -71,7 +73,7 @@ func (h *MetricsHandler) GetOverallMetrics(c *gin.Context) {
 
 	for _, container := range containers {
 		counters[container.State]++
-		containerMetrics = append(containerMetrics, h.buildContainerMetrics(ctx, container))
+		containerMetrics = append(containerMetrics, h.buildContainerMetrics(ctx, h.hosts.From(c), container))
 	}
 
 	var systemStats runtime.MemStats
@@ -95,21 +97,21 @@ func (h *MetricsHandler) GetContainerMetrics(c *gin.Context) {
 	containerID := c.Param("id")
 	ctx := context.Background()
 
-	stats, err := h.dockerClient.GetContainerStats(ctx, containerID)
+	stats, err := h.hosts.From(c).GetContainerStats(ctx, containerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch container stats"})
+		respondError(c, err)
 		return
 	}
 
-	containers, err := h.dockerClient.ListContainers(ctx, true)
+	containers, err := h.hosts.From(c).ListContainers(ctx, true)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch container info"})
+		respondError(c, err)
 		return
 	}
 
 	container := findContainer(containers, containerID)
 	if container == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Container not found"})
+		respondError(c, apierror.NotFound("Container not found"))
 		return
 	}
 
@@ -154,7 +156,7 @@ func (h *MetricsHandler) GetHistoricalMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *MetricsHandler) buildContainerMetrics(ctx context.Context, container models.Container) ContainerMetrics {
+func (h *MetricsHandler) buildContainerMetrics(ctx context.Context, dockerClient docker.API, container models.Container) ContainerMetrics {
 	containerName := getContainerName(container.Names)
 	
 	if container.State != "running" {
@@ -173,7 +175,7 @@ func (h *MetricsHandler) buildContainerMetrics(ctx context.Context, container mo
 		}
 	}
 
-	stats, err := h.dockerClient.GetContainerStats(ctx, container.ID)
+	stats, err := dockerClient.GetContainerStats(ctx, container.ID)
 	if err != nil {
 		return ContainerMetrics{
 			ContainerID:   container.ID,
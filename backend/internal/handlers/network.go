@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NetworkHandler struct {
+	hosts *hosts.Pool
+}
+
+func NewNetworkHandler(pool *hosts.Pool) *NetworkHandler {
+	return &NetworkHandler{
+		hosts: pool,
+	}
+}
+
+func (h *NetworkHandler) ListNetworks(c *gin.Context) {
+	networks, err := h.hosts.From(c).ListNetworks(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, networks)
+}
+
+func (h *NetworkHandler) InspectNetwork(c *gin.Context) {
+	net, err := h.hosts.From(c).InspectNetwork(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, net)
+}
+
+func (h *NetworkHandler) CreateNetwork(c *gin.Context) {
+	var req models.CreateNetworkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	if req.Name == "" {
+		badRequest(c, "name is required")
+		return
+	}
+
+	net, err := h.hosts.From(c).CreateNetwork(c.Request.Context(), req)
+	if err != nil {
+		audit.Record(c, "system", req.Name, "create_network_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	var subnets []string
+	for _, pool := range net.IPAM.Config {
+		subnets = append(subnets, pool.Subnet)
+	}
+	details := fmt.Sprintf("Network created successfully (driver: %s, internal: %v, subnets: %s)", net.Driver, net.Internal, strings.Join(subnets, ","))
+	audit.Record(c, "system", net.Name, "create_network", details)
+	c.JSON(http.StatusCreated, net)
+}
+
+func (h *NetworkHandler) RemoveNetwork(c *gin.Context) {
+	networkID := c.Param("id")
+
+	err := h.hosts.From(c).RemoveNetwork(c.Request.Context(), networkID)
+	if err != nil {
+		audit.Record(c, "system", networkID, "remove_network_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", networkID, "remove_network", "Network removed successfully")
+	c.JSON(http.StatusOK, gin.H{"message": "Network removed successfully"})
+}
+
+func (h *NetworkHandler) PruneNetworks(c *gin.Context) {
+	report, err := h.hosts.From(c).PruneNetworks(c.Request.Context())
+	if err != nil {
+		audit.Record(c, "system", "networks", "prune_networks_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Pruned networks: %s", strings.Join(report.NetworksDeleted, ", "))
+	audit.Record(c, "system", "networks", "prune_networks", details)
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *NetworkHandler) ConnectContainer(c *gin.Context) {
+	networkID := c.Param("id")
+
+	var req models.NetworkConnectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	if req.Container == "" {
+		badRequest(c, "container is required")
+		return
+	}
+
+	containerName := lookupContainerName(c.Request.Context(), h.hosts.From(c), req.Container)
+
+	err := h.hosts.From(c).ConnectNetwork(c.Request.Context(), networkID, req)
+	if err != nil {
+		audit.Record(c, req.Container, containerName, "network_connect_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Connected to network %s (aliases: %s, ipv4: %s, ipv6: %s)", networkID, strings.Join(req.Aliases, ","), req.IPv4Address, req.IPv6Address)
+	audit.Record(c, req.Container, containerName, "network_connect", details)
+	c.JSON(http.StatusOK, gin.H{"message": "Container connected successfully"})
+}
+
+func (h *NetworkHandler) DisconnectContainer(c *gin.Context) {
+	networkID := c.Param("id")
+
+	var req models.NetworkDisconnectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	if req.Container == "" {
+		badRequest(c, "container is required")
+		return
+	}
+
+	containerName := lookupContainerName(c.Request.Context(), h.hosts.From(c), req.Container)
+
+	err := h.hosts.From(c).DisconnectNetwork(c.Request.Context(), networkID, req)
+	if err != nil {
+		audit.Record(c, req.Container, containerName, "network_disconnect_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Disconnected from network %s (force: %v)", networkID, req.Force)
+	audit.Record(c, req.Container, containerName, "network_disconnect", details)
+	c.JSON(http.StatusOK, gin.H{"message": "Container disconnected successfully"})
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/auth"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/rbac"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	authorizer *rbac.Authorizer
+	db         database.Store
+}
+
+func NewRoleHandler(authorizer *rbac.Authorizer, db database.Store) *RoleHandler {
+	return &RoleHandler{
+		authorizer: authorizer,
+		db:         db,
+	}
+}
+
+func (h *RoleHandler) MyPermissions(c *gin.Context) {
+	permissions, err := h.authorizer.EffectivePermissions(auth.IdentityFrom(c).UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, permissions)
+}
+
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": rbac.SortedPermissions(),
+		"actions":     rbac.ActionPermissions,
+	})
+}
+
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.authorizer.Roles()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	role, err := h.authorizer.CreateRole(req.Name, req.Description, req.Permissions)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", role.Name, "create_role", "Role created with permissions "+strings.Join(role.Permissions, ", "))
+	c.JSON(http.StatusCreated, role)
+}
+
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	role, err := h.authorizer.UpdateRole(c.Param("name"), req.Description, req.Permissions)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", role.Name, "update_role", "Role permissions set to "+strings.Join(role.Permissions, ", "))
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	name := c.Param("name")
+	if err := h.authorizer.DeleteRole(name); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", name, "delete_role", "Role deleted")
+	c.JSON(http.StatusOK, gin.H{"message": "Role deleted successfully"})
+}
+
+func (h *RoleHandler) ListUserRoles(c *gin.Context) {
+	user, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	bindings, err := h.authorizer.Bindings(user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bindings)
+}
+
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	user, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	var req models.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	binding, err := h.authorizer.Assign(user.ID, req)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", user.Username, "assign_role", "Role "+describeBinding(binding)+" assigned")
+	c.JSON(http.StatusCreated, binding)
+}
+
+func (h *RoleHandler) UnassignRole(c *gin.Context) {
+	user, ok := h.user(c)
+	if !ok {
+		return
+	}
+
+	bindingID, err := strconv.Atoi(c.Param("binding"))
+	if err != nil {
+		badRequest(c, "Invalid role binding id")
+		return
+	}
+
+	if err := h.authorizer.Unassign(bindingID, user.ID); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			respondError(c, apierror.NotFound("Role binding not found"))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	audit.Record(c, "system", user.Username, "unassign_role", fmt.Sprintf("Role binding %d removed", bindingID))
+	c.JSON(http.StatusOK, gin.H{"message": "Role removed successfully"})
+}
+
+func (h *RoleHandler) user(c *gin.Context) (*database.User, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		badRequest(c, "Invalid user id")
+		return nil, false
+	}
+
+	user, err := h.db.GetUser(id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			respondError(c, apierror.NotFound("User not found"))
+			return nil, false
+		}
+		respondError(c, err)
+		return nil, false
+	}
+
+	return user, true
+}
+
+func describeBinding(binding *database.RoleBinding) string {
+	var scopes []string
+	if binding.ScopeLabel != "" {
+		scopes = append(scopes, "label "+binding.ScopeLabel)
+	}
+	if binding.ScopeName != "" {
+		scopes = append(scopes, "name "+binding.ScopeName)
+	}
+	if len(scopes) == 0 {
+		return binding.Role
+	}
+	return binding.Role + " (" + strings.Join(scopes, ", ") + ")"
+}
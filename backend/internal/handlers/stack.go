@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/internal/rbac"
+	"docker-gui-backend/internal/stacks"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxComposeSize = 1 << 20
+
+type StackHandler struct {
+	hosts *hosts.Pool
+	db    database.Store
+}
+
+func NewStackHandler(pool *hosts.Pool, db database.Store) *StackHandler {
+	return &StackHandler{
+		hosts: pool,
+		db:    db,
+	}
+}
+
+func (h *StackHandler) manager(c *gin.Context) *stacks.Manager {
+	return stacks.NewManager(h.hosts.From(c))
+}
+
+func (h *StackHandler) ListStacks(c *gin.Context) {
+	containers, err := h.hosts.From(c).ListContainers(c.Request.Context(), true)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	visible := make(map[string]int)
+	for _, stack := range stacks.Group(rbac.FilterContainers(c, containers)) {
+		visible[stack.Name] = stack.Containers
+	}
+
+	list := []models.Stack{}
+	for _, stack := range stacks.Group(containers) {
+		if visible[stack.Name] == stack.Containers {
+			list = append(list, stack)
+		}
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *StackHandler) GetStack(c *gin.Context) {
+	stack, err := h.manager(c).Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stack)
+}
+
+func (h *StackHandler) StartStack(c *gin.Context) {
+	h.apply(c, stacks.ActionStart)
+}
+
+func (h *StackHandler) StopStack(c *gin.Context) {
+	h.apply(c, stacks.ActionStop)
+}
+
+func (h *StackHandler) RestartStack(c *gin.Context) {
+	h.apply(c, stacks.ActionRestart)
+}
+
+func (h *StackHandler) RemoveStack(c *gin.Context) {
+	h.apply(c, stacks.ActionRemove)
+}
+
+func (h *StackHandler) apply(c *gin.Context, action string) {
+	project := c.Param("name")
+	service := c.Param("service")
+	force := c.DefaultQuery("force", "false") == "true"
+
+	results, err := h.manager(c).Apply(c.Request.Context(), project, service, action, force)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		scope := fmt.Sprintf("stack %s, service %s", project, result.Service)
+		if result.Error != "" {
+			failed++
+			audit.Record(c, result.ContainerID, result.ContainerName, result.Action+"_failed", scope+": "+result.Error)
+			continue
+		}
+		audit.Record(c, result.ContainerID, result.ContainerName, result.Action, fmt.Sprintf("Container %s as part of %s", pastTense(result.Action), scope))
+	}
+
+	status := http.StatusOK
+	message := fmt.Sprintf("Stack %s completed successfully", action)
+	if failed > 0 {
+		status = http.StatusMultiStatus
+		message = fmt.Sprintf("Stack %s completed with %d failures", action, failed)
+	}
+
+	if results == nil {
+		results = []models.StackActionResult{}
+	}
+	c.JSON(status, gin.H{"message": message, "results": results})
+}
+
+func pastTense(action string) string {
+	switch action {
+	case stacks.ActionStop:
+		return "stopped"
+	case stacks.ActionRemove:
+		return "removed"
+	default:
+		return action + "ed"
+	}
+}
+
+func (h *StackHandler) PlanStack(c *gin.Context) {
+	project := c.Param("name")
+
+	_, file, ok := readCompose(c)
+	if !ok {
+		return
+	}
+
+	var previous *stacks.ComposeFile
+	if rev, err := h.db.LatestStackRevision(h.hosts.Name(c), project); err == nil {
+		previous, _ = stacks.ParseCompose([]byte(rev.Compose))
+	}
+
+	plan, err := h.manager(c).Plan(c.Request.Context(), project, file, previous, deployOptions(c))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+func (h *StackHandler) DeployStack(c *gin.Context) {
+	compose, file, ok := readCompose(c)
+	if !ok {
+		return
+	}
+
+	h.deploy(c, c.Param("name"), compose, file, "deploy")
+}
+
+func (h *StackHandler) ListRevisions(c *gin.Context) {
+	revisions, err := h.db.ListStackRevisions(h.hosts.Name(c), c.Param("name"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *StackHandler) GetRevision(c *gin.Context) {
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		badRequest(c, "Invalid revision")
+		return
+	}
+
+	rev, err := h.db.GetStackRevision(h.hosts.Name(c), c.Param("name"), revision)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rev)
+}
+
+func (h *StackHandler) RollbackStack(c *gin.Context) {
+	project := c.Param("name")
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		badRequest(c, "Invalid revision")
+		return
+	}
+
+	rev, err := h.db.GetStackRevision(h.hosts.Name(c), project, revision)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	file, err := stacks.ParseCompose([]byte(rev.Compose))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.deploy(c, project, rev.Compose, file, fmt.Sprintf("rollback:%d", revision))
+}
+
+func (h *StackHandler) deploy(c *gin.Context, project, compose string, file *stacks.ComposeFile, action string) {
+	changes, err := h.manager(c).Deploy(c.Request.Context(), project, file, deployOptions(c))
+	if err != nil {
+		audit.Record(c, "system", project, "deploy_stack_failed", err.Error())
+		respondError(c, classify(err).WithDetails(gin.H{"changes": changes}))
+		return
+	}
+
+	rev, err := h.db.CreateStackRevision(h.hosts.Name(c), project, compose, action)
+	if err != nil {
+		respondError(c, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "Stack deployed but revision could not be stored: "+err.Error()).WithDetails(gin.H{"changes": changes}))
+		return
+	}
+
+	applied := 0
+	for _, change := range changes {
+		if change.Action != stacks.ChangeUnchanged && change.Action != stacks.ChangeOrphan {
+			applied++
+		}
+	}
+	details := fmt.Sprintf("Stack deployed as revision %d (%s, %d changes applied)", rev.Revision, action, applied)
+	audit.Record(c, "system", project, "deploy_stack", details)
+
+	c.JSON(http.StatusOK, models.StackDeployment{
+		Project:  project,
+		Revision: rev.Revision,
+		Changes:  changes,
+	})
+}
+
+func readCompose(c *gin.Context) (string, *stacks.ComposeFile, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxComposeSize)
+
+	var compose string
+	if c.ContentType() == "application/json" {
+		var req models.DeployStackRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBodyError(c, err)
+			return "", nil, false
+		}
+		compose = req.Compose
+	} else {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			respondBodyError(c, err)
+			return "", nil, false
+		}
+		compose = string(body)
+	}
+
+	file, err := stacks.ParseCompose([]byte(compose))
+	if err != nil {
+		badRequest(c, err.Error())
+		return "", nil, false
+	}
+
+	return compose, file, true
+}
+
+func deployOptions(c *gin.Context) stacks.DeployOptions {
+	return stacks.DeployOptions{
+		RemoveOrphans: c.DefaultQuery("removeOrphans", "false") == "true",
+	}
+}
+
+func respondRevisionError(c *gin.Context, err error) {
+	if errors.Is(err, database.ErrNotFound) {
+		respondError(c, apierror.NotFound("Revision not found"))
+		return
+	}
+	respondError(c, err)
+}
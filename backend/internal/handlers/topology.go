@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/internal/rbac"
+	"docker-gui-backend/internal/topology"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TopologyHandler struct {
+	hosts *hosts.Pool
+}
+
+func NewTopologyHandler(pool *hosts.Pool) *TopologyHandler {
+	return &TopologyHandler{hosts: pool}
+}
+
+func (h *TopologyHandler) GetTopology(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	networks, err := h.hosts.From(c).ListNetworks(ctx)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	containers, err := h.hosts.From(c).ListContainers(ctx, c.DefaultQuery("all", "true") == "true")
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	graph := topology.Build(networks, rbac.FilterContainers(c, containers))
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, graph)
+	case "dot":
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(topology.DOT(graph)))
+	default:
+		badRequest(c, "format must be json or dot")
+	}
+}
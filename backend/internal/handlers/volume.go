@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VolumeHandler struct {
+	hosts *hosts.Pool
+}
+
+func NewVolumeHandler(pool *hosts.Pool) *VolumeHandler {
+	return &VolumeHandler{
+		hosts: pool,
+	}
+}
+
+func (h *VolumeHandler) ListVolumes(c *gin.Context) {
+	volumes, err := h.hosts.From(c).ListVolumes(c.Request.Context())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, volumes)
+}
+
+func (h *VolumeHandler) InspectVolume(c *gin.Context) {
+	name := c.Param("name")
+
+	vol, err := h.hosts.From(c).InspectVolume(c.Request.Context(), name)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vol)
+}
+
+func (h *VolumeHandler) CreateVolume(c *gin.Context) {
+	var req models.CreateVolumeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badRequest(c, err.Error())
+		return
+	}
+
+	vol, err := h.hosts.From(c).CreateVolume(c.Request.Context(), req)
+	if err != nil {
+		audit.Record(c, "system", req.Name, "create_volume_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Volume created successfully (driver: %s)", vol.Driver)
+	audit.Record(c, "system", vol.Name, "create_volume", details)
+	c.JSON(http.StatusCreated, vol)
+}
+
+func (h *VolumeHandler) RemoveVolume(c *gin.Context) {
+	name := c.Param("name")
+	force := c.DefaultQuery("force", "false") == "true"
+
+	err := h.hosts.From(c).RemoveVolume(c.Request.Context(), name, force)
+	if err != nil {
+		audit.Record(c, "system", name, "remove_volume_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Volume removed successfully (force: %v)", force)
+	audit.Record(c, "system", name, "remove_volume", details)
+	c.JSON(http.StatusOK, gin.H{"message": "Volume removed successfully"})
+}
+
+func (h *VolumeHandler) PruneVolumes(c *gin.Context) {
+	all := c.DefaultQuery("all", "false") == "true"
+
+	report, err := h.hosts.From(c).PruneVolumes(c.Request.Context(), all)
+	if err != nil {
+		audit.Record(c, "system", "volumes", "prune_volumes_failed", err.Error())
+		respondError(c, err)
+		return
+	}
+
+	details := fmt.Sprintf("Pruned %d volumes, reclaimed %d bytes", len(report.VolumesDeleted), report.SpaceReclaimed)
+	audit.Record(c, "system", "volumes", "prune_volumes", details)
+	c.JSON(http.StatusOK, report)
+}
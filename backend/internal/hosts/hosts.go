@@ -0,0 +1,528 @@
+package hosts
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/pkg/models"
+)
+
+const (
+	Local = "local"
+	All   = "all"
+
+	statusTimeout = 5 * time.Second
+)
+
+var (
+	ErrHostNotFound = errors.New("docker host not found")
+	ErrHostExists   = errors.New("docker host already exists")
+	ErrInvalidHost  = errors.New("invalid docker host")
+	ErrBuiltinHost  = errors.New("the local docker host is configured from the environment and cannot be modified")
+	ErrDefaultHost  = errors.New("the default docker host cannot be removed")
+)
+
+var (
+	namePattern     = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]{0,62}$`)
+	endpointSchemes = []string{"tcp", "unix", "npipe", "ssh"}
+)
+
+type Pool struct {
+	db           database.Store
+	local        docker.API
+	defaultHost  string
+	sshKeepalive time.Duration
+	keys         cipher.AEAD
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+}
+
+type pooledClient struct {
+	docker.API
+	refs    int
+	retired bool
+}
+
+func NewPool(db database.Store, local docker.API) (*Pool, error) {
+	defaultHost := os.Getenv("DOCKER_DEFAULT_HOST")
+	if defaultHost == "" {
+		defaultHost = Local
+	}
+	if defaultHost == All || !namePattern.MatchString(defaultHost) {
+		return nil, fmt.Errorf("invalid DOCKER_DEFAULT_HOST %q", defaultHost)
+	}
+
+	var keepalive time.Duration
+	if v := os.Getenv("DOCKER_SSH_KEEPALIVE"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid DOCKER_SSH_KEEPALIVE %q", v)
+		}
+		keepalive = d
+	}
+
+	keys, err := keyCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &Pool{
+		db:           db,
+		local:        local,
+		defaultHost:  defaultHost,
+		sshKeepalive: keepalive,
+		keys:         keys,
+		clients:      make(map[string]*pooledClient),
+	}
+	if err := pool.sealStoredKeys(); err != nil {
+		if !errors.Is(err, database.ErrUnavailable) {
+			return nil, err
+		}
+		log.Println("Warning: database unavailable, stored ssh keys will be encrypted on the next start")
+	}
+
+	return pool, nil
+}
+
+func (p *Pool) Default() string {
+	return p.defaultHost
+}
+
+func (p *Pool) Client(name string) (docker.API, error) {
+	if name == Local {
+		return p.local, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pooled, err := p.pooled(name)
+	if err != nil {
+		return nil, err
+	}
+	return pooled.API, nil
+}
+
+func (p *Pool) acquire(name string) (docker.API, func(), error) {
+	if name == Local {
+		return p.local, func() {}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pooled, err := p.pooled(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	pooled.refs++
+
+	var once sync.Once
+	return pooled.API, func() { once.Do(func() { p.release(pooled) }) }, nil
+}
+
+func (p *Pool) release(pooled *pooledClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pooled.refs--
+	if pooled.retired && pooled.refs == 0 {
+		pooled.Close()
+	}
+}
+
+func (p *Pool) pooled(name string) (*pooledClient, error) {
+	if pooled, ok := p.clients[name]; ok {
+		return pooled, nil
+	}
+
+	host, err := p.db.GetDockerHost(name)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := p.newClient(*host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for docker host %s: %w", name, err)
+	}
+	pooled := &pooledClient{API: client}
+	p.clients[name] = pooled
+
+	return pooled, nil
+}
+
+func (p *Pool) newClient(host database.DockerHost) (docker.API, error) {
+	if strings.HasPrefix(host.Endpoint, "ssh://") {
+		key, err := p.db.GetSSHKey(host.SSHKey)
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, host.SSHKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+		privateKey, err := p.openKey(key.Name, key.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return docker.NewSSHClient(host.Endpoint, docker.SSHMaterial{
+			PrivateKey: privateKey,
+			KnownHosts: host.SSHKnownHosts,
+			Keepalive:  p.sshKeepalive,
+		})
+	}
+
+	var material *docker.TLSMaterial
+	if host.TLSCA != "" || host.TLSCert != "" || host.TLSKey != "" || host.TLSSkipVerify {
+		material = &docker.TLSMaterial{
+			CA:         host.TLSCA,
+			Cert:       host.TLSCert,
+			Key:        host.TLSKey,
+			SkipVerify: host.TLSSkipVerify,
+		}
+	}
+	return docker.NewRemoteClient(host.Endpoint, material)
+}
+
+func (p *Pool) invalidate(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pooled, ok := p.clients[name]; ok {
+		delete(p.clients, name)
+		pooled.retired = true
+		if pooled.refs == 0 {
+			pooled.Close()
+		}
+	}
+}
+
+func (p *Pool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name, client := range p.clients {
+		client.Close()
+		delete(p.clients, name)
+	}
+}
+
+func (p *Pool) Names() ([]string, error) {
+	hosts, err := p.db.ListDockerHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{Local}
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+	return names, nil
+}
+
+func (p *Pool) Hosts(ctx context.Context, withStatus bool) ([]models.DockerHost, error) {
+	stored, err := p.db.ListDockerHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := []models.DockerHost{p.localHost()}
+	for _, host := range stored {
+		hosts = append(hosts, p.toModel(host))
+	}
+
+	if withStatus {
+		var wg sync.WaitGroup
+		for i := range hosts {
+			wg.Add(1)
+			go func(host *models.DockerHost) {
+				defer wg.Done()
+				host.Status = p.status(ctx, host.Name)
+			}(&hosts[i])
+		}
+		wg.Wait()
+	}
+
+	return hosts, nil
+}
+
+func (p *Pool) Host(ctx context.Context, name string) (*models.DockerHost, error) {
+	var host models.DockerHost
+	if name == Local {
+		host = p.localHost()
+	} else {
+		stored, err := p.db.GetDockerHost(name)
+		if errors.Is(err, database.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrHostNotFound, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		host = p.toModel(*stored)
+	}
+
+	host.Status = p.status(ctx, name)
+	return &host, nil
+}
+
+func (p *Pool) status(ctx context.Context, name string) *models.HostStatus {
+	client, release, err := p.acquire(name)
+	if err != nil {
+		return &models.HostStatus{Error: err.Error()}
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+
+	status, err := client.Info(ctx)
+	if err != nil {
+		return &models.HostStatus{Error: err.Error()}
+	}
+	return status
+}
+
+func (p *Pool) Create(ctx context.Context, req models.HostRequest) (*models.DockerHost, error) {
+	host, err := p.validate(ctx, req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.db.GetDockerHost(host.Name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrHostExists, host.Name)
+	} else if !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+
+	created, err := p.db.CreateDockerHost(host)
+	if err != nil {
+		return nil, err
+	}
+	p.invalidate(created.Name)
+
+	result := p.toModel(*created)
+	return &result, nil
+}
+
+func (p *Pool) Update(ctx context.Context, name string, req models.HostRequest) (*models.DockerHost, error) {
+	if name == Local {
+		return nil, ErrBuiltinHost
+	}
+	req.Name = name
+
+	existing, err := p.db.GetDockerHost(name)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := p.validate(ctx, req, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := p.db.UpdateDockerHost(host)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrHostNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.invalidate(name)
+
+	result := p.toModel(*updated)
+	return &result, nil
+}
+
+func (p *Pool) Delete(name string) error {
+	if name == Local {
+		return ErrBuiltinHost
+	}
+	if name == p.defaultHost {
+		return ErrDefaultHost
+	}
+
+	err := p.db.DeleteDockerHost(name)
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrHostNotFound, name)
+	}
+	if err != nil {
+		return err
+	}
+	p.invalidate(name)
+
+	return nil
+}
+
+func (p *Pool) validate(ctx context.Context, req models.HostRequest, existing *database.DockerHost) (database.DockerHost, error) {
+	host := database.DockerHost{
+		Name:          strings.TrimSpace(req.Name),
+		Endpoint:      strings.TrimSpace(req.Endpoint),
+		TLSCA:         strings.TrimSpace(req.TLSCA),
+		TLSCert:       strings.TrimSpace(req.TLSCert),
+		TLSKey:        strings.TrimSpace(req.TLSKey),
+		TLSSkipVerify: req.TLSSkipVerify,
+		SSHKey:        strings.TrimSpace(req.SSHKey),
+		SSHKnownHosts: strings.TrimSpace(req.SSHKnownHosts),
+		Labels:        req.Labels,
+	}
+	if host.Labels == nil {
+		host.Labels = map[string]string{}
+	}
+
+	if !namePattern.MatchString(host.Name) {
+		return host, fmt.Errorf("%w: name must be 1-63 letters, digits, '.', '_' or '-'", ErrInvalidHost)
+	}
+	if host.Name == Local || host.Name == All {
+		return host, fmt.Errorf("%w: the name %q is reserved", ErrInvalidHost, host.Name)
+	}
+
+	scheme, address, ok := strings.Cut(host.Endpoint, "://")
+	if !ok || address == "" || !contains(endpointSchemes, scheme) {
+		return host, fmt.Errorf("%w: endpoint must start with %s://", ErrInvalidHost, strings.Join(endpointSchemes, ":// or "))
+	}
+	if (host.TLSCert == "") != (host.TLSKey == "") {
+		return host, fmt.Errorf("%w: tlsCert and tlsKey must be provided together", ErrInvalidHost)
+	}
+	if scheme == "ssh" {
+		if err := p.validateSSH(&host, existing); err != nil {
+			return host, err
+		}
+	} else if host.SSHKey != "" || host.SSHKnownHosts != "" {
+		return host, fmt.Errorf("%w: sshKey and sshKnownHosts only apply to ssh:// endpoints", ErrInvalidHost)
+	}
+
+	client, err := p.newClient(host)
+	if err != nil {
+		return host, fmt.Errorf("%w: %v", ErrInvalidHost, err)
+	}
+	client.Close()
+
+	return host, nil
+}
+
+func (p *Pool) validateSSH(host *database.DockerHost, existing *database.DockerHost) error {
+	if host.TLSCA != "" || host.TLSCert != "" || host.TLSSkipVerify {
+		return fmt.Errorf("%w: TLS settings do not apply to ssh:// endpoints", ErrInvalidHost)
+	}
+	if _, err := docker.ParseSSHEndpoint(host.Endpoint); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHost, err)
+	}
+
+	if host.SSHKey == "" {
+		return fmt.Errorf("%w: sshKey is required for ssh:// endpoints", ErrInvalidHost)
+	}
+	if _, err := p.db.GetSSHKey(host.SSHKey); errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, host.SSHKey)
+	} else if err != nil {
+		return err
+	}
+
+	if host.SSHKnownHosts != "" {
+		return nil
+	}
+	if existing != nil && existing.Endpoint == host.Endpoint && existing.SSHKnownHosts != "" {
+		host.SSHKnownHosts = existing.SSHKnownHosts
+		return nil
+	}
+
+	target, _ := docker.ParseSSHEndpoint(host.Endpoint)
+	hostname, port, _ := net.SplitHostPort(target.Address)
+	return fmt.Errorf("%w: sshKnownHosts is required for ssh:// endpoints; run ssh-keyscan -p %s %s on a trusted network and check the fingerprint against the server's own", ErrInvalidHost, port, hostname)
+}
+
+func (p *Pool) localHost() models.DockerHost {
+	endpoint := os.Getenv("DOCKER_HOST")
+	if endpoint == "" {
+		endpoint = "unix:///var/run/docker.sock"
+	}
+
+	return models.DockerHost{
+		Name:     Local,
+		Endpoint: endpoint,
+		TLS:      os.Getenv("DOCKER_TLS_VERIFY") != "" || os.Getenv("DOCKER_CERT_PATH") != "",
+		Labels:   map[string]string{},
+		Default:  p.defaultHost == Local,
+		Builtin:  true,
+	}
+}
+
+func (p *Pool) toModel(host database.DockerHost) models.DockerHost {
+	return models.DockerHost{
+		Name:          host.Name,
+		Endpoint:      host.Endpoint,
+		TLS:           host.TLSCA != "" || host.TLSCert != "",
+		TLSSkipVerify: host.TLSSkipVerify,
+		SSHKey:        host.SSHKey,
+		SSHKnownHosts: host.SSHKnownHosts,
+		Labels:        host.Labels,
+		Default:       p.defaultHost == host.Name,
+		CreatedAt:     host.CreatedAt,
+		UpdatedAt:     host.UpdatedAt,
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func Collect[T any](ctx context.Context, p *Pool, list func(context.Context, docker.API) ([]T, error), tag func(*T, string)) ([]T, []models.HostError, error) {
+	names, err := p.Names()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([][]T, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			client, release, err := p.acquire(name)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			defer release()
+			results[i], errs[i] = list(ctx, client)
+		}(i, name)
+	}
+	wg.Wait()
+
+	items := []T{}
+	failures := []models.HostError{}
+	for i, name := range names {
+		if errs[i] != nil {
+			failures = append(failures, models.HostError{Host: name, Error: errs[i].Error()})
+			continue
+		}
+		for j := range results[i] {
+			tag(&results[i][j], name)
+			items = append(items, results[i][j])
+		}
+	}
+
+	return items, failures, nil
+}
@@ -0,0 +1,151 @@
+package hosts
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/internal/docker/dockertest"
+	"docker-gui-backend/pkg/models"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const testHostKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIKDtt4tYhvNmvr1jP3ZjU3+TC8NE4qSeEITX01R6k7Kf"
+
+type closeCounter struct {
+	docker.API
+	closed *atomic.Int32
+}
+
+func (c closeCounter) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func TestInvalidateWaitsForActiveRequests(t *testing.T) {
+	t.Setenv("DOCKER_DEFAULT_HOST", "")
+	t.Setenv("SSH_KEY_SECRET", "hosts-test-secret")
+	db, err := database.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.CreateDockerHost(database.DockerHost{Name: "edge", Endpoint: "tcp://127.0.0.1:1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := NewPool(db, dockertest.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	var closed atomic.Int32
+	pool.clients["edge"] = &pooledClient{API: closeCounter{API: dockertest.New(), closed: &closed}}
+
+	first, releaseFirst, err := pool.acquire("edge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, releaseSecond, err := pool.acquire("edge")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.invalidate("edge")
+	if closed.Load() != 0 {
+		t.Fatal("client closed while requests were still using it")
+	}
+
+	replacement, releaseReplacement, err := pool.acquire("edge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replacement == first {
+		t.Error("acquire after invalidate returned the retired client")
+	}
+
+	releaseFirst()
+	releaseFirst()
+	if closed.Load() != 0 {
+		t.Fatal("client closed before its last request finished")
+	}
+	releaseSecond()
+	if closed.Load() != 1 {
+		t.Errorf("retired client closed %d times after its last request, want 1", closed.Load())
+	}
+
+	releaseReplacement()
+	pool.invalidate("edge")
+	if _, ok := pool.clients["edge"]; ok {
+		t.Error("invalidated client still pooled")
+	}
+}
+
+func TestSSHKeysEncryptedAtRest(t *testing.T) {
+	t.Setenv("DOCKER_DEFAULT_HOST", "")
+	t.Setenv("SSH_KEY_SECRET", "hosts-test-secret")
+	db, err := database.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	legacy, err := generateKey("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateSSHKey(database.SSHKey{Name: "legacy", PrivateKey: legacy, PublicKey: "-", Fingerprint: "-"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := NewPool(db, dockertest.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.CreateKey(models.SSHKeyRequest{Name: "deploy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"legacy", "deploy"} {
+		stored, err := db.GetSSHKey(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(stored.PrivateKey, sealedKeyPrefix) || strings.Contains(stored.PrivateKey, "PRIVATE KEY") {
+			t.Errorf("%s stored in the clear: %.40q", name, stored.PrivateKey)
+		}
+		opened, err := pool.openKey(name, stored.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ssh.ParsePrivateKey([]byte(opened)); err != nil {
+			t.Errorf("%s does not decrypt to a usable key: %v", name, err)
+		}
+		if name == "legacy" && opened != legacy {
+			t.Errorf("legacy key changed by encryption")
+		}
+		if _, err := pool.openKey("other", stored.PrivateKey); err == nil {
+			t.Errorf("%s decrypted under another key name", name)
+		}
+	}
+
+	host := database.DockerHost{Name: "bastion", Endpoint: "ssh://deploy@127.0.0.1:1", SSHKey: "deploy", SSHKnownHosts: "[127.0.0.1]:1 " + testHostKey}
+	client, err := pool.newClient(host)
+	if err != nil {
+		t.Fatalf("client from encrypted key: %v", err)
+	}
+	client.Close()
+
+	t.Setenv("SSH_KEY_SECRET", "a-different-secret")
+	rotated, err := NewPool(db, dockertest.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rotated.newClient(host); err == nil || !strings.Contains(err.Error(), "SSH_KEY_SECRET") {
+		t.Errorf("client with the wrong secret: err = %v", err)
+	}
+}
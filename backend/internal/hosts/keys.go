@@ -0,0 +1,125 @@
+package hosts
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/pkg/models"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrKeyNotFound = errors.New("ssh key not found")
+	ErrKeyExists   = errors.New("ssh key already exists")
+	ErrInvalidKey  = errors.New("invalid ssh key")
+	ErrKeyInUse    = errors.New("ssh key is still used by a docker host")
+)
+
+func (p *Pool) Keys() ([]models.SSHKey, error) {
+	stored, err := p.db.ListSSHKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []models.SSHKey{}
+	for _, key := range stored {
+		keys = append(keys, keyModel(key))
+	}
+	return keys, nil
+}
+
+func (p *Pool) CreateKey(req models.SSHKeyRequest) (*models.SSHKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be 1-63 letters, digits, '.', '_' or '-'", ErrInvalidKey)
+	}
+
+	privateKey := strings.TrimSpace(req.PrivateKey)
+	if privateKey == "" {
+		generated, err := generateKey(name)
+		if err != nil {
+			return nil, err
+		}
+		privateKey = generated
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("%w: passphrase-protected keys are not supported", ErrInvalidKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	if _, err := p.db.GetSSHKey(name); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrKeyExists, name)
+	} else if !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+
+	sealed, err := p.sealKey(name, privateKey+"\n")
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))) + " docker-gui-" + name
+	created, err := p.db.CreateSSHKey(database.SSHKey{
+		Name:        name,
+		PrivateKey:  sealed,
+		PublicKey:   publicKey,
+		Fingerprint: ssh.FingerprintSHA256(signer.PublicKey()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := keyModel(*created)
+	return &result, nil
+}
+
+func (p *Pool) DeleteKey(name string) error {
+	hosts, err := p.db.ListDockerHosts()
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if host.SSHKey == name {
+			return fmt.Errorf("%w: %s", ErrKeyInUse, host.Name)
+		}
+	}
+
+	err = p.db.DeleteSSHKey(name)
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+	return err
+}
+
+func generateKey(name string) (string, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := ssh.MarshalPrivateKey(private, "docker-gui-"+name)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+func keyModel(key database.SSHKey) models.SSHKey {
+	return models.SSHKey{
+		Name:        key.Name,
+		PublicKey:   key.PublicKey,
+		Fingerprint: key.Fingerprint,
+		CreatedAt:   key.CreatedAt,
+	}
+}
@@ -0,0 +1,62 @@
+package hosts
+
+import (
+	"errors"
+	"net/http"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	clientKey = "hosts.client"
+	nameKey   = "hosts.name"
+)
+
+func (p *Pool) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("host")
+		if name == "" {
+			name = p.defaultHost
+		}
+
+		client, release, err := p.acquire(name)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrHostNotFound):
+				apierror.Abort(c, apierror.Wrap(err, http.StatusNotFound, apierror.CodeNotFound))
+			case errors.Is(err, database.ErrUnavailable):
+				apierror.Abort(c, err)
+			default:
+				apierror.Abort(c, apierror.Wrap(err, http.StatusBadGateway, apierror.CodeUpstream))
+			}
+			return
+		}
+
+		defer release()
+
+		c.Set(clientKey, client)
+		c.Set(nameKey, name)
+		c.Next()
+	}
+}
+
+func (p *Pool) From(c *gin.Context) docker.API {
+	if v, ok := c.Get(clientKey); ok {
+		return v.(docker.API)
+	}
+	if client, err := p.Client(p.defaultHost); err == nil {
+		return client
+	}
+	return p.local
+}
+
+func (p *Pool) Name(c *gin.Context) string {
+	if name := c.GetString(nameKey); name != "" {
+		return name
+	}
+	return p.defaultHost
+}
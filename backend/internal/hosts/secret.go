@@ -0,0 +1,102 @@
+package hosts
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/keyfile"
+)
+
+const (
+	defaultKeySecretFile = "docker-gui-ssh.key"
+	sealedKeyPrefix      = "enc:v1:"
+)
+
+func keyCipher() (cipher.AEAD, error) {
+	secret, _, err := keyfile.Load(keyfile.Source{
+		Name:        "ssh key secret",
+		Env:         "SSH_KEY_SECRET",
+		FileEnv:     "SSH_KEY_SECRET_FILE",
+		DefaultFile: defaultKeySecretFile,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (p *Pool) sealKey(name, privateKey string) (string, error) {
+	nonce := make([]byte, p.keys.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := p.keys.Seal(nonce, nonce, []byte(privateKey), []byte(name))
+	return sealedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (p *Pool) openKey(name, stored string) (string, error) {
+	encoded, sealed := strings.CutPrefix(stored, sealedKeyPrefix)
+	if !sealed {
+		return stored, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < p.keys.NonceSize() {
+		return "", fmt.Errorf("ssh key %s is corrupt", name)
+	}
+	nonce, ciphertext := data[:p.keys.NonceSize()], data[p.keys.NonceSize():]
+	plain, err := p.keys.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("ssh key %s cannot be decrypted with the configured SSH_KEY_SECRET", name)
+	}
+	return string(plain), nil
+}
+
+func (p *Pool) sealStoredKeys() error {
+	keys, err := p.db.ListSSHKeys()
+	if err != nil {
+		return err
+	}
+
+	sealed := 0
+	for _, listed := range keys {
+		key, err := p.db.GetSSHKey(listed.Name)
+		if errors.Is(err, database.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(key.PrivateKey, sealedKeyPrefix) {
+			continue
+		}
+
+		privateKey, err := p.sealKey(key.Name, key.PrivateKey)
+		if err != nil {
+			return err
+		}
+		if err := p.db.UpdateSSHPrivateKey(key.Name, privateKey); err != nil {
+			return err
+		}
+		sealed++
+	}
+
+	if sealed > 0 {
+		log.Printf("Encrypted %d stored ssh private keys", sealed)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package keyfile
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+type Source struct {
+	Name        string
+	Env         string
+	FileEnv     string
+	DefaultFile string
+}
+
+func Load(s Source) (string, bool, error) {
+	if value := os.Getenv(s.Env); value != "" {
+		return value, false, nil
+	}
+
+	path := os.Getenv(s.FileEnv)
+	if path == "" {
+		path = s.DefaultFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			return "", false, fmt.Errorf("%s file %s is empty", s.Name, path)
+		}
+		return value, false, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", false, fmt.Errorf("failed to read %s: %w", s.Name, err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	value := hex.EncodeToString(buf)
+	if err := os.WriteFile(path, []byte(value+"\n"), 0o600); err != nil {
+		return "", false, fmt.Errorf("failed to write %s: %w", s.Name, err)
+	}
+	log.Printf("Generated %s at %s", s.Name, path)
+
+	return value, true, nil
+}
@@ -0,0 +1,51 @@
+package keyfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	source := Source{Name: "test key", Env: "KEYFILE_TEST_KEY", FileEnv: "KEYFILE_TEST_KEY_FILE", DefaultFile: filepath.Join(dir, "default.key")}
+
+	t.Setenv("KEYFILE_TEST_KEY", "from-env")
+	if value, generated, err := Load(source); value != "from-env" || generated || err != nil {
+		t.Errorf("env: %q generated=%v err=%v", value, generated, err)
+	}
+
+	t.Setenv("KEYFILE_TEST_KEY", "")
+	value, generated, err := Load(source)
+	if err != nil || !generated || len(value) != 64 {
+		t.Fatalf("generate: %q generated=%v err=%v", value, generated, err)
+	}
+	info, err := os.Stat(source.DefaultFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("generated file mode %o, want 600", info.Mode().Perm())
+	}
+	if again, generated, err := Load(source); again != value || generated || err != nil {
+		t.Errorf("reload: %q generated=%v err=%v, want %q from the file", again, generated, err, value)
+	}
+
+	custom := filepath.Join(dir, "custom.key")
+	os.WriteFile(custom, []byte("  from-file\n"), 0o600)
+	t.Setenv("KEYFILE_TEST_KEY_FILE", custom)
+	if value, _, err := Load(source); value != "from-file" || err != nil {
+		t.Errorf("file: %q err=%v", value, err)
+	}
+
+	os.WriteFile(custom, []byte("\n"), 0o600)
+	if _, _, err := Load(source); err == nil || !strings.Contains(err.Error(), "test key file") {
+		t.Errorf("empty file: err = %v", err)
+	}
+
+	t.Setenv("KEYFILE_TEST_KEY_FILE", filepath.Join(dir, "missing", "test.key"))
+	if _, _, err := Load(source); err == nil {
+		t.Error("unwritable key file was accepted")
+	}
+}
@@ -0,0 +1,255 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+
+	schemas         *registry
+	defaultResponse *Response
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type SecurityRequirement map[string][]string
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+func New(info Info) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{},
+		},
+	}
+	d.schemas = newRegistry(d.Components.Schemas)
+	return d
+}
+
+func (d *Document) SetDefaultResponse(description string, value any) {
+	d.defaultResponse = &Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: d.Schema(value)}},
+	}
+}
+
+func (d *Document) Schema(v any) *Schema {
+	return d.schemas.schema(v, true)
+}
+
+func (d *Document) RequestSchema(v any) *Schema {
+	return d.schemas.schema(v, false)
+}
+
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tag         string
+	Public      bool
+	Params      []Parameter
+	Body        *Body
+	Responses   []Reply
+}
+
+type Body struct {
+	Content  map[string]any
+	Optional bool
+}
+
+type Reply struct {
+	Status      int
+	Description string
+	ContentType string
+	Value       any
+	Headers     map[string]Header
+}
+
+func JSON(value any) *Body {
+	return &Body{Content: map[string]any{"application/json": value}}
+}
+
+func OptionalJSON(value any) *Body {
+	return &Body{Content: map[string]any{"application/json": value}, Optional: true}
+}
+
+func OK(value any) Reply {
+	return Reply{Status: http.StatusOK, ContentType: "application/json", Value: value}
+}
+
+func Created(value any) Reply {
+	return Reply{Status: http.StatusCreated, ContentType: "application/json", Value: value}
+}
+
+func Status(status int, value any) Reply {
+	return Reply{Status: status, ContentType: "application/json", Value: value}
+}
+
+func File(contentType string) Reply {
+	return Reply{Status: http.StatusOK, ContentType: contentType, Value: Binary()}
+}
+
+func Query(name, description string, value any) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schemaOf(value)}
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+func PathTemplate(ginPath string) string {
+	return pathParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+func (d *Document) Add(route Route) {
+	path := PathTemplate(route.Path)
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Responses:   map[string]Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if route.Public {
+		op.Security = []SecurityRequirement{{}}
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	op.Parameters = append(op.Parameters, route.Params...)
+
+	if body := route.Body; body != nil {
+		op.RequestBody = &RequestBody{Required: !body.Optional, Content: map[string]MediaType{}}
+		for contentType, value := range body.Content {
+			op.RequestBody.Content[contentType] = MediaType{Schema: d.RequestSchema(value)}
+		}
+	}
+
+	for _, reply := range route.Responses {
+		response := Response{Description: reply.Description, Headers: reply.Headers}
+		if response.Description == "" {
+			response.Description = http.StatusText(reply.Status)
+		}
+		if reply.Value != nil {
+			response.Content = map[string]MediaType{reply.ContentType: {Schema: d.Schema(reply.Value)}}
+		}
+		key := strconv.Itoa(reply.Status)
+		if existing, ok := op.Responses[key]; ok {
+			for contentType, media := range response.Content {
+				if other, dup := existing.Content[contentType]; dup {
+					media.Schema = &Schema{OneOf: append(alternatives(other.Schema), media.Schema)}
+				}
+				existing.Content[contentType] = media
+			}
+			continue
+		}
+		op.Responses[key] = response
+	}
+	if d.defaultResponse != nil {
+		op.Responses["default"] = *d.defaultResponse
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = PathItem{}
+		d.Paths[path] = item
+	}
+	method := strings.ToLower(route.Method)
+	if _, exists := item[method]; exists {
+		panic(fmt.Sprintf("openapi: %s %s documented twice", route.Method, path))
+	}
+	item[method] = op
+}
+
+func alternatives(s *Schema) []*Schema {
+	if len(s.OneOf) > 0 {
+		return s.OneOf
+	}
+	return []*Schema{s}
+}
+
+func (d *Document) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, d)
+	}
+}
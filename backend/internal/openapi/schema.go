@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+type Object map[string]any
+
+func Binary() *Schema {
+	return &Schema{Type: "string", Format: "binary"}
+}
+
+func Text() *Schema {
+	return &Schema{Type: "string"}
+}
+
+func Enum(values ...string) *Schema {
+	s := &Schema{Type: "string"}
+	for _, v := range values {
+		s.Enum = append(s.Enum, v)
+	}
+	return s
+}
+
+const componentPrefix = "#/components/schemas/"
+
+func RefName(ref string) string {
+	return strings.TrimPrefix(ref, componentPrefix)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type registry struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+	owners     map[string]reflect.Type
+	responses  map[reflect.Type]bool
+}
+
+func newRegistry(components map[string]*Schema) *registry {
+	return &registry{
+		components: components,
+		names:      map[reflect.Type]string{},
+		owners:     map[string]reflect.Type{},
+		responses:  map[reflect.Type]bool{},
+	}
+}
+
+func schemaOf(v any) *Schema {
+	return newRegistry(nil).schema(v, false)
+}
+
+func (r *registry) schema(v any, response bool) *Schema {
+	switch v := v.(type) {
+	case *Schema:
+		return v
+	case Object:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for name, value := range v {
+			s.Properties[name] = r.schema(value, response)
+			if response {
+				s.Required = append(s.Required, name)
+			}
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+	return r.typeSchema(reflect.TypeOf(v), response)
+}
+
+func (r *registry) typeSchema(t reflect.Type, response bool) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(r.typeSchema(t.Elem(), response))
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.typeSchema(t.Elem(), response), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.typeSchema(t.Elem(), response), Nullable: true}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t, response)
+		}
+		return r.component(t, response)
+	}
+	panic(fmt.Sprintf("openapi: cannot describe %s", t))
+}
+
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	copied := *s
+	copied.Nullable = true
+	return &copied
+}
+
+func (r *registry) component(t reflect.Type, response bool) *Schema {
+	name, known := r.names[t]
+	if !known {
+		name = t.Name()
+		if owner, taken := r.owners[name]; taken && owner != t {
+			name = exportedName(path.Base(t.PkgPath())) + name
+		}
+		r.names[t] = name
+		r.owners[name] = t
+		r.components[name] = &Schema{Type: "object"}
+	}
+
+	ref := &Schema{Ref: componentPrefix + name}
+	if known && (r.responses[t] || !response) {
+		return ref
+	}
+	if response {
+		r.responses[t] = true
+	}
+	*r.components[name] = *r.structSchema(t, response)
+	return ref
+}
+
+func (r *registry) structSchema(t reflect.Type, response bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	r.addFields(s, t, response)
+	return s
+}
+
+func (r *registry) addFields(s *Schema, t reflect.Type, response bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(s, embedded, response)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		omitempty := strings.Contains(options, "omitempty")
+		s.Properties[name] = r.typeSchema(field.Type, response)
+		if response && !omitempty || strings.Contains(field.Tag.Get("binding"), "required") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func exportedName(pkg string) string {
+	runes := []rune(pkg)
+	if len(runes) == 0 {
+		return ""
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
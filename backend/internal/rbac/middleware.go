@@ -0,0 +1,248 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"docker-gui-backend/internal/apierror"
+	"docker-gui-backend/internal/audit"
+	"docker-gui-backend/internal/auth"
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/stacks"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const scopesKey = "rbac.scopes"
+
+func (a *Authorizer) Require(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a.authorize(c, permission, nil)
+	}
+}
+
+func (a *Authorizer) RequireContainer(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a.authorizeContainer(c, permission)
+	}
+}
+
+func (a *Authorizer) RequireAction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var action models.ContainerAction
+		if err := c.ShouldBindBodyWith(&action, binding.JSON); err != nil {
+			apierror.Abort(c, apierror.BadRequest(err.Error()))
+			return
+		}
+
+		permission, ok := ActionPermissions[action.Action]
+		if !ok {
+			apierror.Abort(c, apierror.BadRequest("Invalid action"))
+			return
+		}
+
+		a.authorizeContainer(c, permission)
+	}
+}
+
+func (a *Authorizer) RequireContainerList(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := auth.IdentityFrom(c)
+		if identity == nil {
+			apierror.Abort(c, apierror.Unauthenticated(auth.ErrUnauthenticated.Error()))
+			return
+		}
+
+		unscoped, scopes, err := a.Scopes(identity.UserID, permission)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if unscoped {
+			c.Next()
+			return
+		}
+		if len(scopes) == 0 {
+			a.deny(c, identity, permission, nil)
+			return
+		}
+
+		c.Set(scopesKey, scopes)
+		c.Next()
+	}
+}
+
+func FilterContainers(c *gin.Context, containers []models.Container) []models.Container {
+	v, ok := c.Get(scopesKey)
+	if !ok {
+		return containers
+	}
+	scopes := v.([]models.EffectivePermission)
+
+	visible := []models.Container{}
+	for _, container := range containers {
+		if inAnyScope(scopes, containerTarget(container)) {
+			visible = append(visible, container)
+		}
+	}
+
+	return visible
+}
+
+func (a *Authorizer) RequireStack(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		identity := auth.IdentityFrom(c)
+		if identity == nil {
+			apierror.Abort(c, apierror.Unauthenticated(auth.ErrUnauthenticated.Error()))
+			return
+		}
+
+		unscoped, scopes, err := a.Scopes(identity.UserID, permission)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		if unscoped {
+			c.Next()
+			return
+		}
+
+		target := &Target{ID: "stack", Name: c.Param("name")}
+		if len(scopes) > 0 {
+			containers, err := a.hosts.From(c).ListContainers(c.Request.Context(), true)
+			if err != nil {
+				abortWithError(c, err)
+				return
+			}
+			outside, ok := stackOutsideScopes(containers, c.Param("name"), c.Param("service"), scopes)
+			if ok {
+				c.Next()
+				return
+			}
+			if outside != nil {
+				target = outside
+			}
+		}
+
+		a.deny(c, identity, permission, target)
+	}
+}
+
+func stackOutsideScopes(containers []models.Container, project, service string, scopes []models.EffectivePermission) (*Target, bool) {
+	matched := 0
+	for _, container := range containers {
+		if container.Labels[stacks.LabelProject] != project || (service != "" && container.Labels[stacks.LabelService] != service) {
+			continue
+		}
+		matched++
+
+		target := containerTarget(container)
+		if !inAnyScope(scopes, target) {
+			return target, false
+		}
+	}
+	return nil, matched > 0
+}
+
+func inAnyScope(scopes []models.EffectivePermission, target *Target) bool {
+	for _, scope := range scopes {
+		if inScope(scope, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Authorizer) authorizeContainer(c *gin.Context, permission string) {
+	identity := auth.IdentityFrom(c)
+	if identity == nil {
+		apierror.Abort(c, apierror.Unauthenticated(auth.ErrUnauthenticated.Error()))
+		return
+	}
+
+	unscoped, scopes, err := a.Scopes(identity.UserID, permission)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if unscoped {
+		c.Next()
+		return
+	}
+
+	target := &Target{ID: c.Param("id"), Name: c.Param("id")}
+	if len(scopes) > 0 {
+		target, err = a.resolveContainer(c, c.Param("id"))
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+		for _, scope := range scopes {
+			if inScope(scope, target) {
+				setParam(c, "id", target.ID)
+				c.Next()
+				return
+			}
+		}
+	}
+
+	a.deny(c, identity, permission, target)
+}
+
+func setParam(c *gin.Context, key, value string) {
+	for i := range c.Params {
+		if c.Params[i].Key == key {
+			c.Params[i].Value = value
+		}
+	}
+}
+
+func (a *Authorizer) authorize(c *gin.Context, permission string, target *Target) {
+	identity := auth.IdentityFrom(c)
+	if identity == nil {
+		apierror.Abort(c, apierror.Unauthenticated(auth.ErrUnauthenticated.Error()))
+		return
+	}
+
+	allowed, err := a.Allowed(identity.UserID, permission, target)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if !allowed {
+		a.deny(c, identity, permission, target)
+		return
+	}
+
+	c.Next()
+}
+
+func (a *Authorizer) deny(c *gin.Context, identity *auth.Identity, permission string, target *Target) {
+	resource := c.Request.Method + " " + c.Request.URL.Path
+	containerID := "system"
+	containerName := resource
+	if target != nil {
+		containerID = target.ID
+		containerName = target.Name
+	}
+
+	details := fmt.Sprintf("%s denied %s on %s", identity.Username, permission, resource)
+	audit.Record(c, containerID, strings.TrimPrefix(containerName, "/"), "access_denied", details)
+
+	apierror.Abort(c, apierror.Forbidden(fmt.Sprintf("permission %s is required", permission)).WithDetails(models.AccessDenied{
+		Permission: permission,
+		Resource:   resource,
+		User:       identity.Username,
+	}))
+}
+
+func abortWithError(c *gin.Context, err error) {
+	if errors.Is(err, database.ErrUnavailable) {
+		apierror.Abort(c, apierror.Unavailable("permissions cannot be checked while the database is unavailable"))
+		return
+	}
+	apierror.Abort(c, err)
+}
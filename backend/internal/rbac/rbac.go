@@ -0,0 +1,497 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ContainersRead    = "containers:read"
+	ContainersStart   = "containers:start"
+	ContainersStop    = "containers:stop"
+	ContainersRestart = "containers:restart"
+	ContainersRemove  = "containers:remove"
+	ContainersUpdate  = "containers:update"
+	ContainersUpload  = "containers:upload"
+	ContainersFiles   = "containers:files:read"
+
+	ImagesRead   = "images:read"
+	ImagesPull   = "images:pull"
+	ImagesRemove = "images:remove"
+	ImagesPrune  = "images:prune"
+
+	VolumesRead   = "volumes:read"
+	VolumesCreate = "volumes:create"
+	VolumesRemove = "volumes:remove"
+	VolumesExport = "volumes:export"
+
+	BackupsRead     = "backups:read"
+	BackupsCreate   = "backups:create"
+	BackupsRestore  = "backups:restore"
+	BackupsDelete   = "backups:delete"
+	BackupsDownload = "backups:download"
+
+	NetworksRead   = "networks:read"
+	NetworksWrite  = "networks:write"
+	NetworksRemove = "networks:remove"
+
+	StacksRead    = "stacks:read"
+	StacksOperate = "stacks:operate"
+	StacksDeploy  = "stacks:deploy"
+	StacksRemove  = "stacks:remove"
+
+	HostsRead   = "hosts:read"
+	HostsManage = "hosts:manage"
+
+	LogsRead    = "logs:read"
+	MetricsRead = "metrics:read"
+
+	UsersManage = "users:manage"
+	RolesManage = "roles:manage"
+
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
+var Permissions = []string{
+	ContainersRead, ContainersStart, ContainersStop, ContainersRestart, ContainersRemove, ContainersUpdate, ContainersUpload, ContainersFiles,
+	ImagesRead, ImagesPull, ImagesRemove, ImagesPrune,
+	VolumesRead, VolumesCreate, VolumesRemove, VolumesExport,
+	BackupsRead, BackupsCreate, BackupsRestore, BackupsDelete, BackupsDownload,
+	NetworksRead, NetworksWrite, NetworksRemove,
+	StacksRead, StacksOperate, StacksDeploy, StacksRemove,
+	HostsRead, HostsManage,
+	LogsRead, MetricsRead,
+	UsersManage, RolesManage,
+}
+
+var ActionPermissions = map[string]string{
+	"start":   ContainersStart,
+	"stop":    ContainersStop,
+	"restart": ContainersRestart,
+	"remove":  ContainersRemove,
+}
+
+var viewerPermissions = []string{
+	ContainersRead, ImagesRead, VolumesRead, BackupsRead, NetworksRead, StacksRead, HostsRead, LogsRead, MetricsRead,
+}
+
+var builtinRoles = []database.Role{
+	{
+		Name:        RoleViewer,
+		Description: "Read-only access to containers, images, volumes, networks, stacks, hosts, logs and metrics",
+		Permissions: viewerPermissions,
+	},
+	{
+		Name:        RoleOperator,
+		Description: "Viewer access plus starting, stopping and restarting containers and stacks",
+		Permissions: append(append([]string{}, viewerPermissions...), ContainersStart, ContainersStop, ContainersRestart, StacksOperate, BackupsCreate),
+	},
+	{
+		Name:        RoleAdmin,
+		Description: "Full access, including user and role management",
+		Permissions: []string{"*"},
+	},
+}
+
+var (
+	ErrBuiltinRole       = errors.New("built-in roles cannot be modified")
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrInvalidRole       = errors.New("role name is required")
+	ErrRoleInUse         = errors.New("role is still assigned to users")
+	ErrInvalidPermission = errors.New("unknown permission")
+	ErrInvalidScope      = errors.New("invalid scope")
+	ErrLastAdmin         = errors.New("cannot remove the last unscoped admin binding")
+)
+
+type Target struct {
+	ID     string
+	Name   string
+	Labels map[string]string
+}
+
+type Authorizer struct {
+	db    database.Store
+	hosts *hosts.Pool
+
+	cacheMu sync.Mutex
+	cache   map[int][]models.EffectivePermission
+}
+
+func NewAuthorizer(db database.Store, pool *hosts.Pool) (*Authorizer, error) {
+	authorizer := &Authorizer{db: db, hosts: pool, cache: make(map[int][]models.EffectivePermission)}
+	if err := authorizer.Bootstrap(); err != nil {
+		if !errors.Is(err, database.ErrUnavailable) {
+			return nil, err
+		}
+		log.Println("Warning: database unavailable, role bootstrap deferred until it returns")
+	}
+	return authorizer, nil
+}
+
+func (a *Authorizer) Bootstrap() error {
+	count, err := a.db.CountRoleBindings()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	users, err := a.db.ListUsers()
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if _, err := a.db.CreateRoleBinding(user.ID, RoleAdmin, "", ""); err != nil {
+			return fmt.Errorf("failed to grant admin role to %q: %w", user.Username, err)
+		}
+		log.Printf("Granted admin role to existing user %q", user.Username)
+	}
+
+	return nil
+}
+
+func (a *Authorizer) Roles() ([]database.Role, error) {
+	custom, err := a.db.ListRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	roles := append([]database.Role{}, builtinRoles...)
+	return append(roles, custom...), nil
+}
+
+func (a *Authorizer) Role(name string) (*database.Role, error) {
+	for _, role := range builtinRoles {
+		if role.Name == name {
+			role := role
+			return &role, nil
+		}
+	}
+
+	role, err := a.db.GetRole(name)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return role, err
+}
+
+func (a *Authorizer) CreateRole(name, description string, permissions []string) (*database.Role, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, ErrInvalidRole
+	}
+	if isBuiltin(name) {
+		return nil, ErrBuiltinRole
+	}
+	if err := validatePermissions(permissions); err != nil {
+		return nil, err
+	}
+	if _, err := a.db.GetRole(name); err == nil {
+		return nil, ErrRoleExists
+	} else if !errors.Is(err, database.ErrNotFound) {
+		return nil, err
+	}
+
+	return a.db.CreateRole(name, description, permissions)
+}
+
+func (a *Authorizer) UpdateRole(name, description string, permissions []string) (*database.Role, error) {
+	if isBuiltin(name) {
+		return nil, ErrBuiltinRole
+	}
+	if err := validatePermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	role, err := a.db.UpdateRole(name, description, permissions)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, ErrRoleNotFound
+	}
+	return role, err
+}
+
+func (a *Authorizer) DeleteRole(name string) error {
+	if isBuiltin(name) {
+		return ErrBuiltinRole
+	}
+
+	bindings, err := a.db.ListRoleBindingsForRole(name)
+	if err != nil {
+		return err
+	}
+	if len(bindings) > 0 {
+		return ErrRoleInUse
+	}
+
+	if err := a.db.DeleteRole(name); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (a *Authorizer) Bindings(userID int) ([]database.RoleBinding, error) {
+	return a.db.ListRoleBindings(userID)
+}
+
+func (a *Authorizer) Assign(userID int, req models.AssignRoleRequest) (*database.RoleBinding, error) {
+	if _, err := a.Role(req.Role); err != nil {
+		return nil, err
+	}
+	if req.ScopeLabel != "" && strings.HasPrefix(req.ScopeLabel, "=") {
+		return nil, fmt.Errorf("%w: label scope must be key or key=value", ErrInvalidScope)
+	}
+	if req.ScopeName != "" {
+		if _, err := path.Match(req.ScopeName, ""); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidScope, err)
+		}
+	}
+
+	return a.db.CreateRoleBinding(userID, req.Role, req.ScopeLabel, req.ScopeName)
+}
+
+func (a *Authorizer) Unassign(bindingID, userID int) error {
+	bindings, err := a.db.ListRoleBindingsForRole(RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	remaining := 0
+	removingAdmin := false
+	for _, binding := range bindings {
+		if binding.ScopeLabel != "" || binding.ScopeName != "" {
+			continue
+		}
+		if binding.ID == bindingID && binding.UserID == userID {
+			removingAdmin = true
+			continue
+		}
+		remaining++
+	}
+	if removingAdmin && remaining == 0 {
+		return ErrLastAdmin
+	}
+
+	return a.db.DeleteRoleBinding(bindingID, userID)
+}
+
+func (a *Authorizer) EffectivePermissions(userID int) ([]models.EffectivePermission, error) {
+	effective, err := a.effectivePermissions(userID)
+
+	a.cacheMu.Lock()
+	defer a.cacheMu.Unlock()
+	if err == nil {
+		a.cache[userID] = effective
+		return effective, nil
+	}
+	if cached, ok := a.cache[userID]; ok && errors.Is(err, database.ErrUnavailable) {
+		return cached, nil
+	}
+	return nil, err
+}
+
+func (a *Authorizer) effectivePermissions(userID int) ([]models.EffectivePermission, error) {
+	bindings, err := a.db.ListRoleBindings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := []models.EffectivePermission{}
+	for _, binding := range bindings {
+		role, err := a.Role(binding.Role)
+		if err != nil {
+			if errors.Is(err, ErrRoleNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		effective = append(effective, models.EffectivePermission{
+			Role:        role.Name,
+			Permissions: role.Permissions,
+			ScopeLabel:  binding.ScopeLabel,
+			ScopeName:   binding.ScopeName,
+		})
+	}
+
+	return effective, nil
+}
+
+func (a *Authorizer) Allowed(userID int, permission string, target *Target) (bool, error) {
+	grants, err := a.EffectivePermissions(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, grant := range grants {
+		if !hasPermission(grant.Permissions, permission) {
+			continue
+		}
+		if grant.ScopeLabel == "" && grant.ScopeName == "" {
+			return true, nil
+		}
+		if target != nil && inScope(grant, target) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (a *Authorizer) Scopes(userID int, permission string) (unscoped bool, scopes []models.EffectivePermission, err error) {
+	grants, err := a.EffectivePermissions(userID)
+	if err != nil {
+		return false, nil, err
+	}
+
+	for _, grant := range grants {
+		if !hasPermission(grant.Permissions, permission) {
+			continue
+		}
+		if grant.ScopeLabel == "" && grant.ScopeName == "" {
+			return true, nil, nil
+		}
+		scopes = append(scopes, grant)
+	}
+
+	return false, scopes, nil
+}
+
+func (a *Authorizer) resolveContainer(c *gin.Context, id string) (*Target, error) {
+	containers, err := a.hosts.From(c).ListContainers(c.Request.Context(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, container := range containers {
+		if container.ID == id {
+			return containerTarget(container), nil
+		}
+	}
+	for _, container := range containers {
+		if hasName(container.Names, id) {
+			return containerTarget(container), nil
+		}
+	}
+
+	var match *models.Container
+	for i, container := range containers {
+		if id != "" && strings.HasPrefix(container.ID, id) {
+			if match != nil {
+				return nil, errdefs.InvalidParameter(fmt.Errorf("multiple containers match the ID prefix %s", id))
+			}
+			match = &containers[i]
+		}
+	}
+	if match != nil {
+		return containerTarget(*match), nil
+	}
+
+	return &Target{ID: id, Name: id}, nil
+}
+
+func containerTarget(container models.Container) *Target {
+	name := container.ID
+	if len(container.Names) > 0 {
+		name = strings.TrimPrefix(container.Names[0], "/")
+	}
+	return &Target{ID: container.ID, Name: name, Labels: container.Labels}
+}
+
+func hasName(names []string, id string) bool {
+	for _, name := range names {
+		if strings.TrimPrefix(name, "/") == strings.TrimPrefix(id, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+func inScope(grant models.EffectivePermission, target *Target) bool {
+	if grant.ScopeLabel != "" {
+		key, value, hasValue := strings.Cut(grant.ScopeLabel, "=")
+		actual, ok := target.Labels[key]
+		if !ok || (hasValue && actual != value) {
+			return false
+		}
+	}
+
+	if grant.ScopeName != "" {
+		matched, err := path.Match(grant.ScopeName, strings.TrimPrefix(target.Name, "/"))
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasPermission(permissions []string, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, p := range permissions {
+		if p == "*" || p == permission || p == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePermissions(permissions []string) error {
+	if len(permissions) == 0 {
+		return fmt.Errorf("%w: at least one permission is required", ErrInvalidPermission)
+	}
+
+	known := make(map[string]bool, len(Permissions))
+	resources := make(map[string]bool)
+	for _, p := range Permissions {
+		known[p] = true
+		resource, _, _ := strings.Cut(p, ":")
+		resources[resource] = true
+	}
+
+	for _, p := range permissions {
+		if p == "*" || known[p] {
+			continue
+		}
+		if resource, action, _ := strings.Cut(p, ":"); action == "*" && resources[resource] {
+			continue
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidPermission, p)
+	}
+
+	return nil
+}
+
+func isBuiltin(name string) bool {
+	for _, role := range builtinRoles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func SortedPermissions() []string {
+	permissions := append([]string{}, Permissions...)
+	sort.Strings(permissions)
+	return permissions
+}
@@ -0,0 +1,293 @@
+package rbac
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"docker-gui-backend/internal/database"
+	"docker-gui-backend/internal/docker/dockertest"
+	"docker-gui-backend/internal/hosts"
+	"docker-gui-backend/internal/stacks"
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/errdefs"
+	"github.com/gin-gonic/gin"
+)
+
+func newTestAuthorizer(t *testing.T, fake *dockertest.Fake) (*Authorizer, database.Store) {
+	t.Helper()
+	t.Setenv("DOCKER_DEFAULT_HOST", "")
+	t.Setenv("SSH_KEY_SECRET", "rbac-test-secret")
+
+	db, err := database.NewMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	pool, err := hosts.NewPool(db, fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pool.Close() })
+
+	authorizer, err := NewAuthorizer(db, pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authorizer, db
+}
+
+func TestHasPermission(t *testing.T) {
+	cases := []struct {
+		granted    []string
+		permission string
+		want       bool
+	}{
+		{[]string{"*"}, ContainersRemove, true},
+		{[]string{ContainersRead, ContainersStart}, ContainersStart, true},
+		{[]string{ContainersRead}, ContainersStart, false},
+		{[]string{"containers:*"}, ContainersRestart, true},
+		{[]string{"containers:*"}, ContainersFiles, true},
+		{[]string{"images:*"}, ContainersRead, false},
+		{[]string{"containers"}, ContainersRead, false},
+		{nil, ContainersRead, false},
+	}
+
+	for _, tc := range cases {
+		if got := hasPermission(tc.granted, tc.permission); got != tc.want {
+			t.Errorf("hasPermission(%v, %s) = %v, want %v", tc.granted, tc.permission, got, tc.want)
+		}
+	}
+}
+
+func TestValidatePermissions(t *testing.T) {
+	cases := []struct {
+		permissions []string
+		valid       bool
+	}{
+		{[]string{ContainersRead, ImagesPull}, true},
+		{[]string{"*"}, true},
+		{[]string{"volumes:*"}, true},
+		{nil, false},
+		{[]string{"containers:fly"}, false},
+		{[]string{"widgets:*"}, false},
+		{[]string{ContainersRead, "containers"}, false},
+	}
+
+	for _, tc := range cases {
+		err := validatePermissions(tc.permissions)
+		if valid := err == nil; valid != tc.valid {
+			t.Errorf("validatePermissions(%v) = %v, want valid %v", tc.permissions, err, tc.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidPermission) {
+			t.Errorf("validatePermissions(%v) = %v, want ErrInvalidPermission", tc.permissions, err)
+		}
+	}
+}
+
+func TestInScope(t *testing.T) {
+	target := &Target{ID: "0123456789ab", Name: "/payments-api", Labels: map[string]string{"team": "payments", "tier": ""}}
+
+	cases := []struct {
+		label, name string
+		want        bool
+	}{
+		{"", "", true},
+		{"team", "", true},
+		{"team=payments", "", true},
+		{"team=billing", "", false},
+		{"tier", "", true},
+		{"tier=", "", true},
+		{"owner", "", false},
+		{"", "payments-api", true},
+		{"", "payments-*", true},
+		{"", "pay*-a?i", true},
+		{"", "[a-p]ayments-*", true},
+		{"", "billing-*", false},
+		{"", "payments", false},
+		{"", "payments-[", false},
+		{"team=payments", "payments-*", true},
+		{"team=payments", "billing-*", false},
+		{"team=billing", "payments-*", false},
+	}
+
+	for _, tc := range cases {
+		grant := models.EffectivePermission{ScopeLabel: tc.label, ScopeName: tc.name}
+		if got := inScope(grant, target); got != tc.want {
+			t.Errorf("inScope(label %q, name %q) = %v, want %v", tc.label, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestInAnyScope(t *testing.T) {
+	target := &Target{Name: "web", Labels: map[string]string{"team": "frontend"}}
+
+	cases := []struct {
+		scopes []models.EffectivePermission
+		want   bool
+	}{
+		{nil, false},
+		{[]models.EffectivePermission{{ScopeName: "db-*"}}, false},
+		{[]models.EffectivePermission{{ScopeName: "db-*"}, {ScopeLabel: "team=frontend"}}, true},
+		{[]models.EffectivePermission{{ScopeLabel: "team=backend"}, {ScopeName: "w?b"}}, true},
+	}
+
+	for _, tc := range cases {
+		if got := inAnyScope(tc.scopes, target); got != tc.want {
+			t.Errorf("inAnyScope(%+v) = %v, want %v", tc.scopes, got, tc.want)
+		}
+	}
+}
+
+func TestResolveContainer(t *testing.T) {
+	fake := dockertest.New()
+	web := fake.AddContainer(dockertest.Container{ID: "abc1230000000000000000000000000000000000000000000000000000000000", Name: "web", Labels: map[string]string{"team": "frontend"}})
+	fake.AddContainer(dockertest.Container{ID: "abc4560000000000000000000000000000000000000000000000000000000000", Name: "api"})
+	shadow := fake.AddContainer(dockertest.Container{ID: "def0000000000000000000000000000000000000000000000000000000000000", Name: "abc4"})
+	authorizer, _ := newTestAuthorizer(t, fake)
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	cases := []struct {
+		id       string
+		wantID   string
+		wantName string
+		wantErr  bool
+	}{
+		{id: web, wantID: web, wantName: "web"},
+		{id: "web", wantID: web, wantName: "web"},
+		{id: "/web", wantID: web, wantName: "web"},
+		{id: "abc12", wantID: web, wantName: "web"},
+		{id: "abc4", wantID: shadow, wantName: "abc4"},
+		{id: "abc45", wantID: "abc4560000000000000000000000000000000000000000000000000000000000", wantName: "api"},
+		{id: "abc", wantErr: true},
+		{id: "missing", wantID: "missing", wantName: "missing"},
+	}
+
+	for _, tc := range cases {
+		target, err := authorizer.resolveContainer(c, tc.id)
+		if tc.wantErr {
+			if !errdefs.IsInvalidParameter(err) {
+				t.Errorf("%s: err = %v, want an ambiguous prefix error", tc.id, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.id, err)
+			continue
+		}
+		if target.ID != tc.wantID || target.Name != tc.wantName {
+			t.Errorf("%s: resolved to %s (%s), want %s (%s)", tc.id, target.ID, target.Name, tc.wantID, tc.wantName)
+		}
+	}
+}
+
+func TestStackOutsideScopes(t *testing.T) {
+	container := func(name, service, team string) models.Container {
+		return models.Container{
+			ID:    name + "-id",
+			Names: []string{"/" + name},
+			Labels: map[string]string{
+				stacks.LabelProject: "shop",
+				stacks.LabelService: service,
+				"team":              team,
+			},
+		}
+	}
+	containers := []models.Container{
+		container("shop-web-1", "web", "frontend"),
+		container("shop-api-1", "api", "frontend"),
+		container("shop-db-1", "db", "data"),
+		{ID: "other-id", Names: []string{"/other"}, Labels: map[string]string{stacks.LabelProject: "blog"}},
+	}
+	frontend := []models.EffectivePermission{{ScopeLabel: "team=frontend"}}
+	everyone := []models.EffectivePermission{{ScopeLabel: "team=frontend"}, {ScopeName: "shop-db-*"}}
+
+	cases := []struct {
+		name     string
+		project  string
+		service  string
+		scopes   []models.EffectivePermission
+		outside  string
+		complete bool
+	}{
+		{"whole project partly in scope", "shop", "", frontend, "shop-db-1", false},
+		{"whole project in scope", "shop", "", everyone, "", true},
+		{"service in scope", "shop", "web", frontend, "", true},
+		{"service out of scope", "shop", "db", frontend, "shop-db-1", false},
+		{"unknown service", "shop", "cache", everyone, "", false},
+		{"unknown project", "wiki", "", everyone, "", false},
+		{"no scopes", "blog", "", nil, "other", false},
+	}
+
+	for _, tc := range cases {
+		target, ok := stackOutsideScopes(containers, tc.project, tc.service, tc.scopes)
+		outside := ""
+		if target != nil {
+			outside = target.Name
+		}
+		if outside != tc.outside || ok != tc.complete {
+			t.Errorf("%s: outside %q, in scope %v, want %q, %v", tc.name, outside, ok, tc.outside, tc.complete)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	authorizer, db := newTestAuthorizer(t, dockertest.New())
+	user, err := db.CreateUser("dana", "hash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []models.AssignRoleRequest{
+		{Role: RoleViewer},
+		{Role: RoleOperator, ScopeLabel: "team=payments"},
+		{Role: RoleAdmin, ScopeName: "payments-db-*"},
+	} {
+		if _, err := authorizer.Assign(user.ID, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	api := &Target{ID: "1", Name: "payments-api", Labels: map[string]string{"team": "payments"}}
+	db1 := &Target{ID: "2", Name: "payments-db-1"}
+	web := &Target{ID: "3", Name: "web", Labels: map[string]string{"team": "frontend"}}
+
+	cases := []struct {
+		permission string
+		target     *Target
+		want       bool
+	}{
+		{ContainersRead, nil, true},
+		{ContainersRead, web, true},
+		{ContainersStart, nil, false},
+		{ContainersStart, api, true},
+		{ContainersStart, web, false},
+		{ContainersRemove, api, false},
+		{ContainersRemove, db1, true},
+		{UsersManage, nil, false},
+	}
+
+	for _, tc := range cases {
+		got, err := authorizer.Allowed(user.ID, tc.permission, tc.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "no target"
+		if tc.target != nil {
+			name = tc.target.Name
+		}
+		if got != tc.want {
+			t.Errorf("Allowed(%s, %s) = %v, want %v", tc.permission, name, got, tc.want)
+		}
+	}
+
+	if _, err := authorizer.Assign(user.ID, models.AssignRoleRequest{Role: RoleViewer, ScopeName: "payments-["}); !errors.Is(err, ErrInvalidScope) {
+		t.Errorf("invalid name pattern: err = %v, want ErrInvalidScope", err)
+	}
+	if _, err := authorizer.Assign(user.ID, models.AssignRoleRequest{Role: RoleViewer, ScopeLabel: "=payments"}); !errors.Is(err, ErrInvalidScope) {
+		t.Errorf("invalid label scope: err = %v, want ErrInvalidScope", err)
+	}
+}
@@ -0,0 +1,378 @@
+package stacks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ComposeFile struct {
+	Services map[string]*ServiceConfig `yaml:"services" json:"services"`
+	Networks map[string]*NetworkConfig `yaml:"networks" json:"networks"`
+	Volumes  map[string]*VolumeConfig  `yaml:"volumes" json:"volumes"`
+}
+
+type ServiceConfig struct {
+	Image         string             `yaml:"image" json:"image"`
+	ContainerName string             `yaml:"container_name" json:"containerName,omitempty"`
+	Command       StringList         `yaml:"command" json:"command,omitempty"`
+	Environment   Mapping            `yaml:"environment" json:"environment,omitempty"`
+	Labels        Mapping            `yaml:"labels" json:"labels,omitempty"`
+	Ports         []PortConfig       `yaml:"ports" json:"ports,omitempty"`
+	Volumes       []VolumeMount      `yaml:"volumes" json:"volumes,omitempty"`
+	Networks      ServiceNetworks    `yaml:"networks" json:"networks,omitempty"`
+	DependsOn     DependsOn          `yaml:"depends_on" json:"dependsOn,omitempty"`
+	Healthcheck   *HealthcheckConfig `yaml:"healthcheck" json:"healthcheck,omitempty"`
+	Restart       string             `yaml:"restart" json:"restart,omitempty"`
+	Build         interface{}        `yaml:"build" json:"-"`
+}
+
+type NetworkConfig struct {
+	Name     string      `yaml:"name" json:"name,omitempty"`
+	Driver   string      `yaml:"driver" json:"driver,omitempty"`
+	Internal bool        `yaml:"internal" json:"internal,omitempty"`
+	External bool        `yaml:"external" json:"external,omitempty"`
+	Options  Mapping     `yaml:"driver_opts" json:"options,omitempty"`
+	IPAM     *IPAMConfig `yaml:"ipam" json:"ipam,omitempty"`
+}
+
+type IPAMConfig struct {
+	Driver string           `yaml:"driver" json:"driver,omitempty"`
+	Config []IPAMPoolConfig `yaml:"config" json:"config,omitempty"`
+}
+
+type IPAMPoolConfig struct {
+	Subnet  string `yaml:"subnet" json:"subnet,omitempty"`
+	Gateway string `yaml:"gateway" json:"gateway,omitempty"`
+	IPRange string `yaml:"ip_range" json:"ipRange,omitempty"`
+}
+
+type VolumeConfig struct {
+	Name     string  `yaml:"name" json:"name,omitempty"`
+	Driver   string  `yaml:"driver" json:"driver,omitempty"`
+	External bool    `yaml:"external" json:"external,omitempty"`
+	Options  Mapping `yaml:"driver_opts" json:"options,omitempty"`
+}
+
+type HealthcheckConfig struct {
+	Test        HealthTest `yaml:"test" json:"test,omitempty"`
+	Interval    Duration   `yaml:"interval" json:"interval,omitempty"`
+	Timeout     Duration   `yaml:"timeout" json:"timeout,omitempty"`
+	StartPeriod Duration   `yaml:"start_period" json:"startPeriod,omitempty"`
+	Retries     int        `yaml:"retries" json:"retries,omitempty"`
+	Disable     bool       `yaml:"disable" json:"disable,omitempty"`
+}
+
+type PortConfig struct {
+	HostIP    string `json:"hostIp,omitempty"`
+	Published string `json:"published,omitempty"`
+	Target    string `json:"target"`
+	Protocol  string `json:"protocol"`
+}
+
+type VolumeMount struct {
+	Type     string `json:"type"`
+	Source   string `json:"source,omitempty"`
+	Target   string `json:"target"`
+	ReadOnly bool   `json:"readOnly,omitempty"`
+}
+
+type ServiceNetwork struct {
+	Aliases     []string `yaml:"aliases" json:"aliases,omitempty"`
+	IPv4Address string   `yaml:"ipv4_address" json:"ipv4Address,omitempty"`
+}
+
+type ServiceNetworks map[string]*ServiceNetwork
+
+type Dependency struct {
+	Condition string `yaml:"condition" json:"condition"`
+}
+
+type DependsOn map[string]Dependency
+
+type StringList []string
+
+type HealthTest []string
+
+type Mapping map[string]string
+
+type Duration time.Duration
+
+func ParseCompose(data []byte) (*ComposeFile, error) {
+	var file ComposeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid compose file: %w", err)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+func (f *ComposeFile) validate() error {
+	if len(f.Services) == 0 {
+		return fmt.Errorf("compose file defines no services")
+	}
+
+	for name, svc := range f.Services {
+		if svc == nil {
+			return fmt.Errorf("service %s is empty", name)
+		}
+		if svc.Build != nil {
+			return fmt.Errorf("service %s: build is not supported, use a prebuilt image", name)
+		}
+		if svc.Image == "" {
+			return fmt.Errorf("service %s: image is required", name)
+		}
+		for dep := range svc.DependsOn {
+			if _, ok := f.Services[dep]; !ok {
+				return fmt.Errorf("service %s depends on undefined service %s", name, dep)
+			}
+		}
+		for netName := range svc.Networks {
+			if _, ok := f.Networks[netName]; !ok && netName != "default" {
+				return fmt.Errorf("service %s uses undefined network %s", name, netName)
+			}
+		}
+		for _, vol := range svc.Volumes {
+			if vol.Type != "volume" || vol.Source == "" {
+				continue
+			}
+			if _, ok := f.Volumes[vol.Source]; !ok {
+				return fmt.Errorf("service %s uses undefined volume %s", name, vol.Source)
+			}
+		}
+		switch {
+		case svc.Restart == "", svc.Restart == "no", svc.Restart == "always", svc.Restart == "unless-stopped", strings.HasPrefix(svc.Restart, "on-failure"):
+		default:
+			return fmt.Errorf("service %s: invalid restart policy %q", name, svc.Restart)
+		}
+	}
+
+	return nil
+}
+
+func (f *ComposeFile) ServiceNames() []string {
+	names := make([]string, 0, len(f.Services))
+	for name := range f.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *ServiceConfig) NetworkNames() []string {
+	if len(s.Networks) == 0 {
+		return []string{"default"}
+	}
+	names := make([]string, 0, len(s.Networks))
+	for name := range s.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (l *StringList) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*l = strings.Fields(value.Value)
+		return nil
+	case yaml.SequenceNode:
+		var items []string
+		if err := value.Decode(&items); err != nil {
+			return err
+		}
+		*l = items
+		return nil
+	}
+	return fmt.Errorf("line %d: expected string or list", value.Line)
+}
+
+func (t *HealthTest) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		*t = HealthTest{"CMD-SHELL", value.Value}
+		return nil
+	case yaml.SequenceNode:
+		var items []string
+		if err := value.Decode(&items); err != nil {
+			return err
+		}
+		*t = items
+		return nil
+	}
+	return fmt.Errorf("line %d: expected string or list", value.Line)
+}
+
+func (m *Mapping) UnmarshalYAML(value *yaml.Node) error {
+	result := make(Mapping)
+	switch value.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			result[value.Content[i].Value] = value.Content[i+1].Value
+		}
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			key, val, _ := strings.Cut(item.Value, "=")
+			result[key] = val
+		}
+	default:
+		return fmt.Errorf("line %d: expected mapping or list", value.Line)
+	}
+	*m = result
+	return nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", value.Line, value.Value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (p *PortConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var long struct {
+			Target    string `yaml:"target"`
+			Published string `yaml:"published"`
+			Protocol  string `yaml:"protocol"`
+			HostIP    string `yaml:"host_ip"`
+		}
+		if err := value.Decode(&long); err != nil {
+			return err
+		}
+		*p = PortConfig{HostIP: long.HostIP, Published: long.Published, Target: long.Target, Protocol: long.Protocol}
+	} else {
+		spec := value.Value
+		protocol := "tcp"
+		if base, proto, ok := strings.Cut(spec, "/"); ok {
+			spec, protocol = base, proto
+		}
+		parts := strings.Split(spec, ":")
+		switch len(parts) {
+		case 1:
+			*p = PortConfig{Target: parts[0], Protocol: protocol}
+		case 2:
+			*p = PortConfig{Published: parts[0], Target: parts[1], Protocol: protocol}
+		case 3:
+			*p = PortConfig{HostIP: parts[0], Published: parts[1], Target: parts[2], Protocol: protocol}
+		default:
+			return fmt.Errorf("line %d: invalid port %q", value.Line, value.Value)
+		}
+	}
+
+	if p.Protocol == "" {
+		p.Protocol = "tcp"
+	}
+	if _, err := strconv.Atoi(p.Target); err != nil {
+		return fmt.Errorf("line %d: invalid container port %q", value.Line, p.Target)
+	}
+	return nil
+}
+
+func (v *VolumeMount) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		var long struct {
+			Type     string `yaml:"type"`
+			Source   string `yaml:"source"`
+			Target   string `yaml:"target"`
+			ReadOnly bool   `yaml:"read_only"`
+		}
+		if err := value.Decode(&long); err != nil {
+			return err
+		}
+		*v = VolumeMount{Type: long.Type, Source: long.Source, Target: long.Target, ReadOnly: long.ReadOnly}
+	} else {
+		parts := strings.Split(value.Value, ":")
+		switch len(parts) {
+		case 1:
+			*v = VolumeMount{Target: parts[0]}
+		case 2, 3:
+			*v = VolumeMount{Source: parts[0], Target: parts[1]}
+			if len(parts) == 3 {
+				v.ReadOnly = strings.Contains(parts[2], "ro")
+			}
+		default:
+			return fmt.Errorf("line %d: invalid volume %q", value.Line, value.Value)
+		}
+		switch {
+		case v.Source == "":
+			v.Type = "volume"
+		case strings.HasPrefix(v.Source, "/"):
+			v.Type = "bind"
+		case strings.HasPrefix(v.Source, ".") || strings.HasPrefix(v.Source, "~"):
+			return fmt.Errorf("line %d: relative bind mount %q is not supported, use an absolute path", value.Line, v.Source)
+		default:
+			v.Type = "volume"
+		}
+	}
+
+	if v.Type == "" {
+		v.Type = "volume"
+	}
+	if v.Type != "volume" && v.Type != "bind" && v.Type != "tmpfs" {
+		return fmt.Errorf("line %d: unsupported volume type %q", value.Line, v.Type)
+	}
+	if !strings.HasPrefix(v.Target, "/") {
+		return fmt.Errorf("line %d: volume target must be an absolute path", value.Line)
+	}
+	return nil
+}
+
+func (n *ServiceNetworks) UnmarshalYAML(value *yaml.Node) error {
+	result := make(ServiceNetworks)
+	switch value.Kind {
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			result[item.Value] = &ServiceNetwork{}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			cfg := &ServiceNetwork{}
+			if value.Content[i+1].Kind == yaml.MappingNode {
+				if err := value.Content[i+1].Decode(cfg); err != nil {
+					return err
+				}
+			}
+			result[value.Content[i].Value] = cfg
+		}
+	default:
+		return fmt.Errorf("line %d: expected list or mapping of networks", value.Line)
+	}
+	*n = result
+	return nil
+}
+
+func (d *DependsOn) UnmarshalYAML(value *yaml.Node) error {
+	result := make(DependsOn)
+	switch value.Kind {
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			result[item.Value] = Dependency{Condition: "service_started"}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			var dep Dependency
+			if err := value.Content[i+1].Decode(&dep); err != nil {
+				return err
+			}
+			if dep.Condition == "" {
+				dep.Condition = "service_started"
+			}
+			result[value.Content[i].Value] = dep
+		}
+	default:
+		return fmt.Errorf("line %d: expected list or mapping of dependencies", value.Line)
+	}
+	*d = result
+	return nil
+}
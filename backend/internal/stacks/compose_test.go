@@ -0,0 +1,134 @@
+package stacks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCompose(t *testing.T) {
+	file, err := ParseCompose([]byte(`
+services:
+  web:
+    image: nginx:1.27
+    command: nginx -g "daemon off;"
+    environment:
+      - MODE=prod
+      - EMPTY=
+    ports:
+      - "8080:80"
+      - 127.0.0.1:8443:443/udp
+      - "9000"
+      - target: 53
+        published: "5353"
+        protocol: udp
+    volumes:
+      - data:/var/lib/data
+      - /etc/ssl:/etc/ssl:ro
+      - /cache
+      - type: tmpfs
+        target: /run
+    networks:
+      front:
+        aliases: [www]
+        ipv4_address: 10.1.0.10
+      default:
+    depends_on: [db]
+    healthcheck:
+      test: curl -f http://localhost
+      interval: 30s
+    restart: on-failure:3
+  db:
+    image: redis:7
+    depends_on:
+      cache:
+        condition: service_healthy
+  cache:
+    image: redis:7
+    networks: [front]
+networks:
+  front:
+    external: true
+volumes:
+  data:
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	web := file.Services["web"]
+	if want := (StringList{"nginx", "-g", `"daemon`, `off;"`}); !reflect.DeepEqual(web.Command, want) {
+		t.Errorf("command = %q, want %q", web.Command, want)
+	}
+	if want := (Mapping{"MODE": "prod", "EMPTY": ""}); !reflect.DeepEqual(web.Environment, want) {
+		t.Errorf("environment = %v, want %v", web.Environment, want)
+	}
+	wantPorts := []PortConfig{
+		{Published: "8080", Target: "80", Protocol: "tcp"},
+		{HostIP: "127.0.0.1", Published: "8443", Target: "443", Protocol: "udp"},
+		{Target: "9000", Protocol: "tcp"},
+		{Published: "5353", Target: "53", Protocol: "udp"},
+	}
+	if !reflect.DeepEqual(web.Ports, wantPorts) {
+		t.Errorf("ports = %+v, want %+v", web.Ports, wantPorts)
+	}
+	wantVolumes := []VolumeMount{
+		{Type: "volume", Source: "data", Target: "/var/lib/data"},
+		{Type: "bind", Source: "/etc/ssl", Target: "/etc/ssl", ReadOnly: true},
+		{Type: "volume", Target: "/cache"},
+		{Type: "tmpfs", Target: "/run"},
+	}
+	if !reflect.DeepEqual(web.Volumes, wantVolumes) {
+		t.Errorf("volumes = %+v, want %+v", web.Volumes, wantVolumes)
+	}
+	if got := web.NetworkNames(); !reflect.DeepEqual(got, []string{"default", "front"}) {
+		t.Errorf("networks = %v", got)
+	}
+	if front := web.Networks["front"]; front == nil || front.IPv4Address != "10.1.0.10" || !reflect.DeepEqual(front.Aliases, []string{"www"}) {
+		t.Errorf("front network = %+v", front)
+	}
+	if web.DependsOn["db"].Condition != "service_started" || file.Services["db"].DependsOn["cache"].Condition != "service_healthy" {
+		t.Errorf("depends_on = %v, %v", web.DependsOn, file.Services["db"].DependsOn)
+	}
+	if hc := web.Healthcheck; !reflect.DeepEqual(hc.Test, HealthTest{"CMD-SHELL", "curl -f http://localhost"}) || time.Duration(hc.Interval) != 30*time.Second {
+		t.Errorf("healthcheck = %+v", hc)
+	}
+	if got := file.ServiceNames(); !reflect.DeepEqual(got, []string{"cache", "db", "web"}) {
+		t.Errorf("service names = %v", got)
+	}
+	if got := file.Services["db"].NetworkNames(); !reflect.DeepEqual(got, []string{"default"}) {
+		t.Errorf("db networks = %v", got)
+	}
+}
+
+func TestParseComposeErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		compose string
+		want    string
+	}{
+		{"no services", "services: {}", "defines no services"},
+		{"empty service", "services:\n  web:\n", "service web is empty"},
+		{"build", "services:\n  web:\n    build: .\n", "build is not supported"},
+		{"no image", "services:\n  web:\n    restart: always\n", "image is required"},
+		{"undefined dependency", "services:\n  web:\n    image: nginx\n    depends_on: [db]\n", "depends on undefined service db"},
+		{"undefined network", "services:\n  web:\n    image: nginx\n    networks: [front]\n", "undefined network front"},
+		{"undefined volume", "services:\n  web:\n    image: nginx\n    volumes: [data:/data]\n", "undefined volume data"},
+		{"restart policy", "services:\n  web:\n    image: nginx\n    restart: sometimes\n", "invalid restart policy"},
+		{"container port", "services:\n  web:\n    image: nginx\n    ports: [\"8080:http\"]\n", "invalid container port"},
+		{"port format", "services:\n  web:\n    image: nginx\n    ports: [\"1:2:3:4\"]\n", "invalid port"},
+		{"relative bind", "services:\n  web:\n    image: nginx\n    volumes: [./html:/usr/share/nginx/html]\n", "relative bind mount"},
+		{"relative target", "services:\n  web:\n    image: nginx\n    volumes: [/srv:srv]\n", "must be an absolute path"},
+		{"volume type", "services:\n  web:\n    image: nginx\n    volumes:\n      - type: npipe\n        target: /x\n", "unsupported volume type"},
+		{"duration", "services:\n  web:\n    image: nginx\n    healthcheck:\n      interval: soon\n", "invalid duration"},
+		{"yaml", "services: [", "invalid compose file"},
+	}
+
+	for _, tc := range cases {
+		_, err := ParseCompose([]byte(tc.compose))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
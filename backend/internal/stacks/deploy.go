@@ -0,0 +1,667 @@
+package stacks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"docker-gui-backend/pkg/models"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	LabelContainerNumber = "com.docker.compose.container-number"
+	LabelNetwork         = "com.docker.compose.network"
+	LabelVolume          = "com.docker.compose.volume"
+	LabelConfigHash      = "docker-gui.config-hash"
+
+	managedConfigFiles = "docker-gui"
+	dependencyTimeout  = 2 * time.Minute
+)
+
+const (
+	ChangeCreate    = "create"
+	ChangeRecreate  = "recreate"
+	ChangeStart     = "start"
+	ChangePull      = "pull"
+	ChangeRemove    = "remove"
+	ChangeOrphan    = "orphan"
+	ChangeMissing   = "missing"
+	ChangeUnchanged = "unchanged"
+)
+
+type DeployOptions struct {
+	RemoveOrphans bool
+}
+
+func (m *Manager) Plan(ctx context.Context, project string, file *ComposeFile, previous *ComposeFile, opts DeployOptions) (*models.StackPlan, error) {
+	plan := &models.StackPlan{
+		Project:  project,
+		Changes:  []models.StackPlanChange{},
+		Warnings: []string{},
+	}
+
+	for _, key := range usedNetworks(file) {
+		cfg := file.Networks[key]
+		name := cfg.resolveName(project, key)
+		exists, err := m.dockerClient.NetworkExists(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, resourceChange("network", name, exists, cfg != nil && cfg.External))
+	}
+
+	for _, key := range sortedKeys(file.Volumes) {
+		cfg := file.Volumes[key]
+		name := cfg.resolveName(project, key)
+		exists, err := m.dockerClient.VolumeExists(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, resourceChange("volume", name, exists, cfg != nil && cfg.External))
+	}
+
+	seenImages := make(map[string]bool)
+	for _, name := range file.ServiceNames() {
+		img := file.Services[name].Image
+		if seenImages[img] {
+			continue
+		}
+		seenImages[img] = true
+		exists, err := m.dockerClient.ImageExists(ctx, img)
+		if err != nil {
+			return nil, err
+		}
+		change := models.StackPlanChange{Kind: "image", Name: img, Action: ChangeUnchanged}
+		if !exists {
+			change.Action = ChangePull
+			change.Reason = "image not present locally"
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	current, err := m.currentServices(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range orderedServiceNames(file) {
+		svc := file.Services[name]
+		change := models.StackPlanChange{Kind: "service", Name: name}
+		existing := current[name]
+		hash := configHash(project, name, svc, file)
+
+		switch {
+		case len(existing) == 0:
+			change.Action = ChangeCreate
+			change.Reason = "service not deployed"
+		case existing[0].Labels[LabelConfigHash] == "":
+			change.Action = ChangeRecreate
+			change.Reason = "container was not deployed by docker-gui"
+		case existing[0].Labels[LabelConfigHash] != hash:
+			change.Action = ChangeRecreate
+			change.Reason = "configuration changed"
+			if previous != nil {
+				change.Diff = diffServices(previous.Services[name], svc)
+			}
+		case existing[0].State != "running":
+			change.Action = ChangeStart
+			change.Reason = "container is " + existing[0].State
+		default:
+			change.Action = ChangeUnchanged
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	for _, name := range sortedKeys(current) {
+		if _, ok := file.Services[name]; ok {
+			continue
+		}
+		change := models.StackPlanChange{Kind: "service", Name: name, Action: ChangeOrphan, Reason: "service not defined in compose file"}
+		if opts.RemoveOrphans {
+			change.Action = ChangeRemove
+		} else {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("service %s is running but not defined in the compose file", name))
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	for _, change := range plan.Changes {
+		if change.Action == ChangeMissing {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("external %s %s does not exist", change.Kind, change.Name))
+		}
+	}
+
+	return plan, nil
+}
+
+func (m *Manager) Deploy(ctx context.Context, project string, file *ComposeFile, opts DeployOptions) ([]models.StackPlanChange, error) {
+	plan, err := m.Plan(ctx, project, file, nil, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := m.currentServices(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := make(map[string]string)
+	for name, containers := range current {
+		deployed[name] = containers[0].ID
+	}
+
+	for i := range plan.Changes {
+		change := &plan.Changes[i]
+		if err := m.applyChange(ctx, project, file, change, current, deployed); err != nil {
+			change.Error = err.Error()
+			return plan.Changes[:i+1], fmt.Errorf("%s %s: %w", change.Kind, change.Name, err)
+		}
+	}
+
+	return plan.Changes, nil
+}
+
+func (m *Manager) applyChange(ctx context.Context, project string, file *ComposeFile, change *models.StackPlanChange, current map[string][]models.Container, deployed map[string]string) error {
+	switch change.Kind {
+	case "network":
+		return m.applyNetwork(ctx, project, file, change)
+	case "volume":
+		return m.applyVolume(ctx, project, file, change)
+	case "image":
+		if change.Action == ChangePull {
+			return m.dockerClient.PullImage(ctx, change.Name)
+		}
+		return nil
+	case "service":
+		return m.applyService(ctx, project, file, change, current, deployed)
+	}
+	return nil
+}
+
+func (m *Manager) applyNetwork(ctx context.Context, project string, file *ComposeFile, change *models.StackPlanChange) error {
+	switch change.Action {
+	case ChangeMissing:
+		return fmt.Errorf("external network does not exist")
+	case ChangeCreate:
+	default:
+		return nil
+	}
+
+	req := models.CreateNetworkRequest{
+		Name:   change.Name,
+		Labels: map[string]string{LabelProject: project},
+	}
+	for key, cfg := range file.Networks {
+		if cfg.resolveName(project, key) != change.Name {
+			continue
+		}
+		req.Labels[LabelNetwork] = key
+		if cfg == nil {
+			break
+		}
+		req.Driver = cfg.Driver
+		req.Internal = cfg.Internal
+		req.Options = cfg.Options
+		if cfg.IPAM != nil {
+			req.IPAM = &models.NetworkIPAM{Driver: cfg.IPAM.Driver}
+			for _, pool := range cfg.IPAM.Config {
+				req.IPAM.Config = append(req.IPAM.Config, models.IPAMPool{
+					Subnet:  pool.Subnet,
+					Gateway: pool.Gateway,
+					IPRange: pool.IPRange,
+				})
+			}
+		}
+	}
+	if req.Labels[LabelNetwork] == "" {
+		req.Labels[LabelNetwork] = "default"
+	}
+
+	_, err := m.dockerClient.CreateNetwork(ctx, req)
+	return err
+}
+
+func (m *Manager) applyVolume(ctx context.Context, project string, file *ComposeFile, change *models.StackPlanChange) error {
+	switch change.Action {
+	case ChangeMissing:
+		return fmt.Errorf("external volume does not exist")
+	case ChangeCreate:
+	default:
+		return nil
+	}
+
+	req := models.CreateVolumeRequest{
+		Name:   change.Name,
+		Labels: map[string]string{LabelProject: project},
+	}
+	for key, cfg := range file.Volumes {
+		if cfg.resolveName(project, key) != change.Name {
+			continue
+		}
+		req.Labels[LabelVolume] = key
+		if cfg != nil {
+			req.Driver = cfg.Driver
+			req.DriverOpts = cfg.Options
+		}
+	}
+
+	_, err := m.dockerClient.CreateVolume(ctx, req)
+	return err
+}
+
+func (m *Manager) applyService(ctx context.Context, project string, file *ComposeFile, change *models.StackPlanChange, current map[string][]models.Container, deployed map[string]string) error {
+	name := change.Name
+
+	switch change.Action {
+	case ChangeUnchanged, ChangeOrphan:
+		return nil
+	case ChangeRemove:
+		for _, cont := range current[name] {
+			if err := m.dockerClient.RemoveContainer(ctx, cont.ID, true); err != nil {
+				return err
+			}
+		}
+		delete(deployed, name)
+		return nil
+	}
+
+	svc := file.Services[name]
+	for dep, cfg := range svc.DependsOn {
+		if err := m.waitForDependency(ctx, dep, deployed[dep], cfg.Condition); err != nil {
+			return err
+		}
+	}
+
+	if change.Action == ChangeStart {
+		return m.dockerClient.StartContainer(ctx, deployed[name])
+	}
+
+	containerName, config, hostConfig, networking, extra := containerSpec(project, name, svc, file)
+	old := current[name]
+	createName := containerName
+	if len(old) > 0 {
+		createName = containerName + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	id, err := m.dockerClient.CreateContainer(ctx, createName, config, hostConfig, networking)
+	if err != nil {
+		return err
+	}
+
+	for _, netKey := range extra {
+		netCfg := svc.Networks[netKey]
+		req := models.NetworkConnectRequest{Container: id, Aliases: []string{name}}
+		if netCfg != nil {
+			req.Aliases = append(req.Aliases, netCfg.Aliases...)
+			req.IPv4Address = netCfg.IPv4Address
+		}
+		netName := file.Networks[netKey].resolveName(project, netKey)
+		if err := m.dockerClient.ConnectNetwork(ctx, netName, req); err != nil {
+			m.dockerClient.RemoveContainer(ctx, id, true)
+			return err
+		}
+	}
+
+	var stopped []string
+	for _, cont := range old {
+		if cont.State != "running" {
+			continue
+		}
+		if err := m.dockerClient.StopContainer(ctx, cont.ID); err != nil {
+			m.restoreService(ctx, id, stopped)
+			return fmt.Errorf("failed to stop old container: %w", err)
+		}
+		stopped = append(stopped, cont.ID)
+	}
+
+	if err := m.dockerClient.StartContainer(ctx, id); err != nil {
+		m.restoreService(ctx, id, stopped)
+		return err
+	}
+
+	for _, cont := range old {
+		if err := m.dockerClient.RemoveContainer(ctx, cont.ID, true); err != nil {
+			return fmt.Errorf("failed to remove old container: %w", err)
+		}
+	}
+
+	if createName != containerName {
+		if err := m.dockerClient.RenameContainer(ctx, id, containerName); err != nil {
+			return fmt.Errorf("failed to rename container: %w", err)
+		}
+	}
+
+	deployed[name] = id
+	return nil
+}
+
+func (m *Manager) restoreService(ctx context.Context, replacement string, stopped []string) {
+	m.dockerClient.RemoveContainer(ctx, replacement, true)
+	for _, id := range stopped {
+		m.dockerClient.StartContainer(ctx, id)
+	}
+}
+
+func (m *Manager) waitForDependency(ctx context.Context, service, containerID, condition string) error {
+	if containerID == "" || condition == "service_started" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dependencyTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		state, err := m.dockerClient.ContainerState(ctx, containerID)
+		if err != nil {
+			return err
+		}
+
+		switch condition {
+		case "service_healthy":
+			if state.Health == nil || state.Health.Status == "healthy" {
+				return nil
+			}
+			if state.Health.Status == "unhealthy" {
+				return fmt.Errorf("dependency %s is unhealthy", service)
+			}
+		case "service_completed_successfully":
+			if !state.Running && state.Status == "exited" {
+				if state.ExitCode != 0 {
+					return fmt.Errorf("dependency %s exited with code %d", service, state.ExitCode)
+				}
+				return nil
+			}
+		default:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for dependency %s (%s)", service, condition)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) currentServices(ctx context.Context, project string) (map[string][]models.Container, error) {
+	containers, err := m.dockerClient.ListContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string][]models.Container)
+	for _, cont := range containers {
+		if cont.Labels[LabelProject] != project || cont.Labels[LabelOneOff] == "True" {
+			continue
+		}
+		service := cont.Labels[LabelService]
+		current[service] = append(current[service], cont)
+	}
+
+	return current, nil
+}
+
+func containerSpec(project, name string, svc *ServiceConfig, file *ComposeFile) (string, *container.Config, *container.HostConfig, *network.NetworkingConfig, []string) {
+	containerName := svc.ContainerName
+	if containerName == "" {
+		containerName = fmt.Sprintf("%s-%s-1", project, name)
+	}
+
+	labels := map[string]string{}
+	for k, v := range svc.Labels {
+		labels[k] = v
+	}
+	labels[LabelProject] = project
+	labels[LabelService] = name
+	labels[LabelContainerNumber] = "1"
+	labels[LabelOneOff] = "False"
+	labels[LabelConfigFiles] = managedConfigFiles
+	labels[LabelConfigHash] = configHash(project, name, svc, file)
+	if len(svc.DependsOn) > 0 {
+		var deps []string
+		for _, dep := range sortedKeys(svc.DependsOn) {
+			deps = append(deps, fmt.Sprintf("%s:%s:false", dep, svc.DependsOn[dep].Condition))
+		}
+		labels[LabelDependsOn] = strings.Join(deps, ",")
+	}
+
+	var env []string
+	for _, key := range sortedKeys(svc.Environment) {
+		env = append(env, key+"="+svc.Environment[key])
+	}
+
+	config := &container.Config{
+		Image:        svc.Image,
+		Cmd:          []string(svc.Command),
+		Env:          env,
+		Labels:       labels,
+		ExposedPorts: nat.PortSet{},
+	}
+
+	hostConfig := &container.HostConfig{
+		PortBindings:  nat.PortMap{},
+		RestartPolicy: restartPolicy(svc.Restart),
+	}
+
+	for _, p := range svc.Ports {
+		port := nat.Port(p.Target + "/" + p.Protocol)
+		config.ExposedPorts[port] = struct{}{}
+		if p.Published != "" {
+			hostConfig.PortBindings[port] = append(hostConfig.PortBindings[port], nat.PortBinding{
+				HostIP:   p.HostIP,
+				HostPort: p.Published,
+			})
+		}
+	}
+
+	for _, v := range svc.Volumes {
+		m := mount.Mount{Target: v.Target, ReadOnly: v.ReadOnly}
+		switch v.Type {
+		case "bind":
+			m.Type = mount.TypeBind
+			m.Source = v.Source
+		case "tmpfs":
+			m.Type = mount.TypeTmpfs
+		default:
+			m.Type = mount.TypeVolume
+			if v.Source != "" {
+				m.Source = file.Volumes[v.Source].resolveName(project, v.Source)
+			}
+		}
+		hostConfig.Mounts = append(hostConfig.Mounts, m)
+	}
+
+	if hc := svc.Healthcheck; hc != nil {
+		config.Healthcheck = &container.HealthConfig{
+			Test:        []string(hc.Test),
+			Interval:    time.Duration(hc.Interval),
+			Timeout:     time.Duration(hc.Timeout),
+			StartPeriod: time.Duration(hc.StartPeriod),
+			Retries:     hc.Retries,
+		}
+		if hc.Disable {
+			config.Healthcheck = &container.HealthConfig{Test: []string{"NONE"}}
+		}
+	}
+
+	networks := svc.NetworkNames()
+	first := networks[0]
+	endpoint := &network.EndpointSettings{Aliases: []string{name}}
+	if cfg := svc.Networks[first]; cfg != nil {
+		endpoint.Aliases = append(endpoint.Aliases, cfg.Aliases...)
+		if cfg.IPv4Address != "" {
+			endpoint.IPAMConfig = &network.EndpointIPAMConfig{IPv4Address: cfg.IPv4Address}
+		}
+	}
+	firstName := file.Networks[first].resolveName(project, first)
+	hostConfig.NetworkMode = container.NetworkMode(firstName)
+	networking := &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{firstName: endpoint},
+	}
+
+	return containerName, config, hostConfig, networking, networks[1:]
+}
+
+func restartPolicy(restart string) container.RestartPolicy {
+	name, retries, _ := strings.Cut(restart, ":")
+	policy := container.RestartPolicy{Name: container.RestartPolicyMode(name)}
+	if name == "" {
+		policy.Name = container.RestartPolicyDisabled
+	}
+	if n, err := strconv.Atoi(retries); err == nil {
+		policy.MaximumRetryCount = n
+	}
+	return policy
+}
+
+func configHash(project, name string, svc *ServiceConfig, file *ComposeFile) string {
+	resolved := make(map[string]string)
+	for _, key := range svc.NetworkNames() {
+		resolved["network:"+key] = file.Networks[key].resolveName(project, key)
+	}
+	for _, v := range svc.Volumes {
+		if v.Type == "volume" && v.Source != "" {
+			resolved["volume:"+v.Source] = file.Volumes[v.Source].resolveName(project, v.Source)
+		}
+	}
+
+	data, _ := json.Marshal(struct {
+		Project   string            `json:"project"`
+		Service   string            `json:"service"`
+		Config    *ServiceConfig    `json:"config"`
+		Resources map[string]string `json:"resources"`
+	}{project, name, svc, resolved})
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func diffServices(previous, next *ServiceConfig) []string {
+	if previous == nil {
+		return []string{"service added"}
+	}
+
+	toFields := func(svc *ServiceConfig) map[string]json.RawMessage {
+		data, _ := json.Marshal(svc)
+		fields := make(map[string]json.RawMessage)
+		json.Unmarshal(data, &fields)
+		return fields
+	}
+
+	before, after := toFields(previous), toFields(next)
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+
+	var diff []string
+	for _, key := range sortedKeys(keys) {
+		old, new := before[key], after[key]
+		if bytes.Equal(old, new) {
+			continue
+		}
+		diff = append(diff, fmt.Sprintf("%s: %s -> %s", key, rawOrNone(old), rawOrNone(new)))
+	}
+	return diff
+}
+
+func rawOrNone(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return "(none)"
+	}
+	return string(raw)
+}
+
+func usedNetworks(file *ComposeFile) []string {
+	used := make(map[string]bool)
+	for key, cfg := range file.Networks {
+		if cfg == nil || !cfg.External {
+			used[key] = true
+		}
+	}
+	for _, svc := range file.Services {
+		for _, key := range svc.NetworkNames() {
+			used[key] = true
+		}
+	}
+	return sortedKeys(used)
+}
+
+func resourceChange(kind, name string, exists, external bool) models.StackPlanChange {
+	change := models.StackPlanChange{Kind: kind, Name: name, Action: ChangeUnchanged}
+	switch {
+	case exists:
+	case external:
+		change.Action = ChangeMissing
+		change.Reason = "external " + kind + " not found"
+	default:
+		change.Action = ChangeCreate
+		change.Reason = kind + " does not exist"
+	}
+	return change
+}
+
+func (n *NetworkConfig) resolveName(project, key string) string {
+	switch {
+	case n != nil && n.Name != "":
+		return n.Name
+	case n != nil && n.External:
+		return key
+	default:
+		return project + "_" + key
+	}
+}
+
+func (v *VolumeConfig) resolveName(project, key string) string {
+	switch {
+	case v != nil && v.Name != "":
+		return v.Name
+	case v != nil && v.External:
+		return key
+	default:
+		return project + "_" + key
+	}
+}
+
+func orderedServiceNames(file *ComposeFile) []string {
+	services := make([]models.StackService, 0, len(file.Services))
+	for _, name := range file.ServiceNames() {
+		services = append(services, models.StackService{
+			Name:      name,
+			DependsOn: sortedKeys(file.Services[name].DependsOn),
+		})
+	}
+
+	var names []string
+	for _, svc := range OrderServices(services) {
+		names = append(names, svc.Name)
+	}
+	return names
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
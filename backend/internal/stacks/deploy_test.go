@@ -0,0 +1,251 @@
+package stacks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"docker-gui-backend/internal/docker/dockertest"
+	"docker-gui-backend/pkg/models"
+)
+
+const testCompose = `
+services:
+  web:
+    image: nginx:1.27
+    ports: ["8080:80"]
+    depends_on: [db]
+  db:
+    image: redis:7
+    volumes: [data:/data]
+volumes:
+  data:
+`
+
+func mustParse(t *testing.T, compose string) *ComposeFile {
+	t.Helper()
+	file, err := ParseCompose([]byte(compose))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func actions(changes []models.StackPlanChange) []string {
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.Kind+" "+change.Name+" "+change.Action)
+	}
+	return got
+}
+
+func TestOrderServices(t *testing.T) {
+	cases := []struct {
+		name     string
+		services []models.StackService
+		want     []string
+	}{
+		{"independent", []models.StackService{{Name: "b"}, {Name: "a"}}, []string{"a", "b"}},
+		{"chain", []models.StackService{
+			{Name: "web", DependsOn: []string{"api"}},
+			{Name: "api", DependsOn: []string{"db"}},
+			{Name: "db"},
+		}, []string{"db", "api", "web"}},
+		{"diamond", []models.StackService{
+			{Name: "app", DependsOn: []string{"cache", "db"}},
+			{Name: "cache", DependsOn: []string{"net"}},
+			{Name: "db", DependsOn: []string{"net"}},
+			{Name: "net"},
+		}, []string{"net", "cache", "db", "app"}},
+		{"unknown dependency", []models.StackService{{Name: "web", DependsOn: []string{"gone"}}}, []string{"web"}},
+		{"cycle", []models.StackService{
+			{Name: "z"},
+			{Name: "b", DependsOn: []string{"a"}},
+			{Name: "a", DependsOn: []string{"b"}},
+		}, []string{"z", "a", "b"}},
+	}
+
+	for _, tc := range cases {
+		var got []string
+		for _, svc := range OrderServices(tc.services) {
+			got = append(got, svc.Name)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: order = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+	fake := dockertest.New()
+	fake.AddImage("nginx:1.27", 0)
+	m := NewManager(fake)
+	file := mustParse(t, testCompose)
+
+	changed := mustParse(t, strings.Replace(testCompose, `ports: ["8080:80"]`, `ports: ["8081:80"]`, 1))
+	webOnly := mustParse(t, "services:\n  web:\n    image: nginx:1.27\n    ports: [\"8080:80\"]\n")
+
+	cases := []struct {
+		name     string
+		setup    func(t *testing.T)
+		file     *ComposeFile
+		previous *ComposeFile
+		opts     DeployOptions
+		want     []string
+		diff     []string
+		warnings int
+	}{
+		{
+			name: "fresh",
+			file: file,
+			want: []string{
+				"network demo_default create", "volume demo_data create",
+				"image redis:7 pull", "image nginx:1.27 unchanged",
+				"service db create", "service web create",
+			},
+		},
+		{
+			name: "deployed",
+			setup: func(t *testing.T) {
+				if _, err := m.Deploy(ctx, "demo", file, DeployOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			},
+			file: file,
+			want: []string{
+				"network demo_default unchanged", "volume demo_data unchanged",
+				"image redis:7 unchanged", "image nginx:1.27 unchanged",
+				"service db unchanged", "service web unchanged",
+			},
+		},
+		{
+			name:     "changed",
+			file:     changed,
+			previous: file,
+			want: []string{
+				"network demo_default unchanged", "volume demo_data unchanged",
+				"image redis:7 unchanged", "image nginx:1.27 unchanged",
+				"service db unchanged", "service web recreate",
+			},
+			diff: []string{`ports: [{"published":"8080","target":"80","protocol":"tcp"}] -> [{"published":"8081","target":"80","protocol":"tcp"}]`},
+		},
+		{
+			name: "stopped",
+			setup: func(t *testing.T) {
+				if err := fake.StopContainer(ctx, "demo-db-1"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			file: file,
+			want: []string{
+				"network demo_default unchanged", "volume demo_data unchanged",
+				"image redis:7 unchanged", "image nginx:1.27 unchanged",
+				"service db start", "service web unchanged",
+			},
+		},
+		{
+			name:     "orphan",
+			file:     webOnly,
+			want:     []string{"network demo_default unchanged", "image nginx:1.27 unchanged", "service web recreate", "service db orphan"},
+			warnings: 1,
+		},
+		{
+			name: "remove orphans",
+			file: webOnly,
+			opts: DeployOptions{RemoveOrphans: true},
+			want: []string{"network demo_default unchanged", "image nginx:1.27 unchanged", "service web recreate", "service db remove"},
+		},
+	}
+
+	for _, tc := range cases {
+		if tc.setup != nil {
+			tc.setup(t)
+		}
+		plan, err := m.Plan(ctx, "demo", tc.file, tc.previous, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got := actions(plan.Changes); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: changes = %q, want %q", tc.name, got, tc.want)
+		}
+		if tc.diff != nil && !reflect.DeepEqual(plan.Changes[len(plan.Changes)-1].Diff, tc.diff) {
+			t.Errorf("%s: diff = %q, want %q", tc.name, plan.Changes[len(plan.Changes)-1].Diff, tc.diff)
+		}
+		if len(plan.Warnings) != tc.warnings {
+			t.Errorf("%s: warnings = %q", tc.name, plan.Warnings)
+		}
+	}
+}
+
+func TestDeployRecreate(t *testing.T) {
+	ctx := context.Background()
+
+	cases := []struct {
+		name    string
+		setup   func(fake *dockertest.Fake)
+		compose string
+		wantErr string
+		wantOld bool
+	}{
+		{
+			name:    "replaced",
+			compose: strings.Replace(testCompose, `ports: ["8080:80"]`, `ports: ["8081:80"]`, 1),
+		},
+		{
+			name:    "same port",
+			compose: strings.Replace(testCompose, "image: nginx:1.27", "image: nginx:1.27\n    restart: always", 1),
+		},
+		{
+			name:    "create fails",
+			setup:   func(fake *dockertest.Fake) { fake.Fail("CreateContainer", errors.New("disk full")) },
+			compose: strings.Replace(testCompose, `ports: ["8080:80"]`, `ports: ["8081:80"]`, 1),
+			wantErr: "disk full",
+			wantOld: true,
+		},
+		{
+			name: "start fails",
+			setup: func(fake *dockertest.Fake) {
+				fake.AddContainer(dockertest.Container{Name: "other", Image: "busybox", Ports: []models.Port{{PublicPort: 8081, Type: "tcp"}}})
+			},
+			compose: strings.Replace(testCompose, `ports: ["8080:80"]`, `ports: ["8081:80"]`, 1),
+			wantErr: "port is already allocated",
+			wantOld: true,
+		},
+	}
+
+	for _, tc := range cases {
+		fake := dockertest.New()
+		m := NewManager(fake)
+		if _, err := m.Deploy(ctx, "demo", mustParse(t, testCompose), DeployOptions{}); err != nil {
+			t.Fatalf("%s: initial deploy: %v", tc.name, err)
+		}
+		old, _ := fake.Container("demo-web-1")
+		if tc.setup != nil {
+			tc.setup(fake)
+		}
+
+		_, err := m.Deploy(ctx, "demo", mustParse(t, tc.compose), DeployOptions{})
+		if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.wantErr)
+		}
+
+		current, err := m.currentServices(ctx, "demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		web := current["web"]
+		if len(web) != 1 {
+			t.Errorf("%s: web containers = %+v, want exactly one", tc.name, web)
+			continue
+		}
+		if web[0].Names[0] != "/demo-web-1" || web[0].State != "running" {
+			t.Errorf("%s: web container = %s %s, want /demo-web-1 running", tc.name, web[0].Names, web[0].State)
+		}
+		if kept := web[0].ID == old.ID; kept != tc.wantOld {
+			t.Errorf("%s: kept old container = %v, want %v", tc.name, kept, tc.wantOld)
+		}
+	}
+}
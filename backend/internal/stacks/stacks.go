@@ -0,0 +1,365 @@
+package stacks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"docker-gui-backend/internal/docker"
+	"docker-gui-backend/pkg/models"
+)
+
+const (
+	LabelProject     = "com.docker.compose.project"
+	LabelService     = "com.docker.compose.service"
+	LabelDependsOn   = "com.docker.compose.depends_on"
+	LabelWorkingDir  = "com.docker.compose.project.working_dir"
+	LabelConfigFiles = "com.docker.compose.project.config_files"
+	LabelOneOff      = "com.docker.compose.oneoff"
+
+	ActionStart   = "start"
+	ActionStop    = "stop"
+	ActionRestart = "restart"
+	ActionRemove  = "remove"
+)
+
+var (
+	ErrStackNotFound   = errors.New("stack not found")
+	ErrServiceNotFound = errors.New("service not found")
+	ErrInvalidAction   = errors.New("invalid action")
+)
+
+type Manager struct {
+	dockerClient docker.API
+}
+
+func NewManager(dockerClient docker.API) *Manager {
+	return &Manager{dockerClient: dockerClient}
+}
+
+func (m *Manager) List(ctx context.Context) ([]models.Stack, error) {
+	containers, err := m.dockerClient.ListContainers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return Group(containers), nil
+}
+
+func (m *Manager) Get(ctx context.Context, name string) (*models.Stack, error) {
+	stacks, err := m.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range stacks {
+		if stacks[i].Name == name {
+			return &stacks[i], nil
+		}
+	}
+
+	return nil, ErrStackNotFound
+}
+
+func (m *Manager) Apply(ctx context.Context, project, service, action string, force bool) ([]models.StackActionResult, error) {
+	ordered, err := m.services(ctx, project, service)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case ActionStart:
+		return m.run(ctx, ordered, ActionStart, force), nil
+	case ActionStop:
+		return m.run(ctx, reverse(ordered), ActionStop, force), nil
+	case ActionRestart:
+		if service != "" {
+			return m.run(ctx, ordered, ActionRestart, force), nil
+		}
+		results := m.run(ctx, reverse(ordered), ActionStop, force)
+		stopped, err := m.services(ctx, project, service)
+		if err != nil {
+			return results, err
+		}
+		return append(results, m.run(ctx, stopped, ActionStart, force)...), nil
+	case ActionRemove:
+		results := m.run(ctx, reverse(ordered), ActionStop, force)
+		return append(results, m.run(ctx, reverse(ordered), ActionRemove, force)...), nil
+	default:
+		return nil, ErrInvalidAction
+	}
+}
+
+func (m *Manager) services(ctx context.Context, project, service string) ([]models.StackService, error) {
+	stack, err := m.Get(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	ordered := OrderServices(stack.Services)
+	if service == "" {
+		return ordered, nil
+	}
+	var selected []models.StackService
+	for _, svc := range ordered {
+		if svc.Name == service {
+			selected = append(selected, svc)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, ErrServiceNotFound
+	}
+	return selected, nil
+}
+
+func (m *Manager) run(ctx context.Context, services []models.StackService, action string, force bool) []models.StackActionResult {
+	var results []models.StackActionResult
+	for _, svc := range services {
+		for _, cont := range svc.Containers {
+			if action == ActionStart && cont.State == "running" {
+				continue
+			}
+			if action == ActionStop && cont.State != "running" && cont.State != "paused" && cont.State != "restarting" {
+				continue
+			}
+
+			var err error
+			switch action {
+			case ActionStart:
+				err = m.dockerClient.StartContainer(ctx, cont.ID)
+			case ActionStop:
+				err = m.dockerClient.StopContainer(ctx, cont.ID)
+			case ActionRestart:
+				err = m.dockerClient.RestartContainer(ctx, cont.ID)
+			case ActionRemove:
+				err = m.dockerClient.RemoveContainer(ctx, cont.ID, force)
+			}
+
+			result := models.StackActionResult{
+				Service:       svc.Name,
+				ContainerID:   cont.ID,
+				ContainerName: cont.Name,
+				Action:        action,
+			}
+			if err != nil {
+				result.Error = err.Error()
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+func Group(containers []models.Container) []models.Stack {
+	byProject := make(map[string]*models.Stack)
+	services := make(map[string]map[string]*models.StackService)
+
+	for _, cont := range containers {
+		project := cont.Labels[LabelProject]
+		if project == "" || cont.Labels[LabelOneOff] == "True" {
+			continue
+		}
+
+		stack, ok := byProject[project]
+		if !ok {
+			stack = &models.Stack{
+				Name:        project,
+				WorkingDir:  cont.Labels[LabelWorkingDir],
+				ConfigFiles: cont.Labels[LabelConfigFiles],
+			}
+			byProject[project] = stack
+			services[project] = make(map[string]*models.StackService)
+		}
+
+		serviceName := cont.Labels[LabelService]
+		svc, ok := services[project][serviceName]
+		if !ok {
+			svc = &models.StackService{
+				Name:      serviceName,
+				Image:     cont.Image,
+				DependsOn: parseDependsOn(cont.Labels[LabelDependsOn]),
+			}
+			services[project][serviceName] = svc
+		}
+
+		svc.Containers = append(svc.Containers, models.StackContainer{
+			ID:     cont.ID,
+			Name:   strings.TrimPrefix(firstName(cont.Names), "/"),
+			State:  cont.State,
+			Status: cont.Status,
+			Health: containerHealth(cont.Status),
+		})
+
+		stack.Containers++
+		if cont.State == "running" {
+			stack.Running++
+		}
+	}
+
+	result := make([]models.Stack, 0, len(byProject))
+	for name, stack := range byProject {
+		for _, svc := range services[name] {
+			sort.Slice(svc.Containers, func(i, j int) bool {
+				return svc.Containers[i].Name < svc.Containers[j].Name
+			})
+			svc.Status = aggregateStatus(svc.Containers)
+			svc.Health = aggregateHealth(svc.Containers)
+			stack.Services = append(stack.Services, *svc)
+		}
+		sort.Slice(stack.Services, func(i, j int) bool {
+			return stack.Services[i].Name < stack.Services[j].Name
+		})
+
+		switch {
+		case stack.Running == 0:
+			stack.Status = "stopped"
+		case stack.Running == stack.Containers:
+			stack.Status = "running"
+		default:
+			stack.Status = "partial"
+		}
+		result = append(result, *stack)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+func OrderServices(services []models.StackService) []models.StackService {
+	byName := make(map[string]models.StackService, len(services))
+	pending := make(map[string]int, len(services))
+	dependents := make(map[string][]string)
+
+	for _, svc := range services {
+		byName[svc.Name] = svc
+	}
+	for _, svc := range services {
+		for _, dep := range svc.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				continue
+			}
+			pending[svc.Name]++
+			dependents[dep] = append(dependents[dep], svc.Name)
+		}
+	}
+
+	var ready []string
+	for _, svc := range services {
+		if pending[svc.Name] == 0 {
+			ready = append(ready, svc.Name)
+		}
+	}
+	sort.Strings(ready)
+
+	ordered := make([]models.StackService, 0, len(services))
+	visited := make(map[string]bool, len(services))
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		visited[name] = true
+		ordered = append(ordered, byName[name])
+
+		var next []string
+		for _, dependent := range dependents[name] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				next = append(next, dependent)
+			}
+		}
+		ready = append(ready, next...)
+		sort.Strings(ready)
+	}
+
+	var cyclic []string
+	for _, svc := range services {
+		if !visited[svc.Name] {
+			cyclic = append(cyclic, svc.Name)
+		}
+	}
+	sort.Strings(cyclic)
+	for _, name := range cyclic {
+		ordered = append(ordered, byName[name])
+	}
+
+	return ordered
+}
+
+func parseDependsOn(label string) []string {
+	deps := []string{}
+	for _, entry := range strings.Split(label, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		if name != "" {
+			deps = append(deps, name)
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+func containerHealth(status string) string {
+	switch {
+	case strings.Contains(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.Contains(status, "(health: starting)"):
+		return "starting"
+	case strings.Contains(status, "(healthy)"):
+		return "healthy"
+	default:
+		return "none"
+	}
+}
+
+func aggregateStatus(containers []models.StackContainer) string {
+	running := 0
+	for _, cont := range containers {
+		if cont.State == "running" {
+			running++
+		}
+	}
+
+	switch {
+	case running == 0:
+		return "stopped"
+	case running == len(containers):
+		return "running"
+	default:
+		return fmt.Sprintf("running (%d/%d)", running, len(containers))
+	}
+}
+
+func aggregateHealth(containers []models.StackContainer) string {
+	health := "none"
+	for _, cont := range containers {
+		switch cont.Health {
+		case "unhealthy":
+			return "unhealthy"
+		case "starting":
+			health = "starting"
+		case "healthy":
+			if health == "none" {
+				health = "healthy"
+			}
+		}
+	}
+	return health
+}
+
+func reverse(services []models.StackService) []models.StackService {
+	reversed := make([]models.StackService, len(services))
+	for i, svc := range services {
+		reversed[len(services)-1-i] = svc
+	}
+	return reversed
+}
+
+func firstName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
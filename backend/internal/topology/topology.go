@@ -0,0 +1,192 @@
+package topology
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"docker-gui-backend/pkg/models"
+)
+
+const (
+	NodeHost      = "host"
+	NodeNetwork   = "network"
+	NodeContainer = "container"
+
+	EdgeAttached  = "attached"
+	EdgePublished = "published"
+
+	hostNodeID = "host"
+)
+
+func Build(networks []models.NetworkResource, containers []models.Container) models.Topology {
+	topology := models.Topology{
+		Nodes: []models.TopologyNode{{ID: hostNodeID, Type: NodeHost, Label: "host"}},
+		Edges: []models.TopologyEdge{},
+	}
+
+	networkIDs := make(map[string]string, len(networks))
+	for _, net := range networks {
+		var subnets []string
+		for _, pool := range net.IPAM.Config {
+			if pool.Subnet != "" {
+				subnets = append(subnets, pool.Subnet)
+			}
+		}
+
+		id := networkNodeID(net.ID)
+		networkIDs[net.ID] = id
+		networkIDs[net.Name] = id
+		topology.Nodes = append(topology.Nodes, models.TopologyNode{
+			ID:       id,
+			Type:     NodeNetwork,
+			Label:    net.Name,
+			Driver:   net.Driver,
+			Internal: net.Internal,
+			Subnets:  subnets,
+		})
+	}
+
+	for _, container := range containers {
+		id := containerNodeID(container.ID)
+		topology.Nodes = append(topology.Nodes, models.TopologyNode{
+			ID:    id,
+			Type:  NodeContainer,
+			Label: containerLabel(container),
+			State: container.State,
+			Image: container.Image,
+		})
+
+		for _, endpoint := range container.Networks {
+			target, ok := networkIDs[endpoint.NetworkID]
+			if !ok {
+				target, ok = networkIDs[endpoint.Name]
+			}
+			if !ok {
+				continue
+			}
+			topology.Edges = append(topology.Edges, models.TopologyEdge{
+				Source:      id,
+				Target:      target,
+				Type:        EdgeAttached,
+				IPAddress:   endpoint.IPAddress,
+				IPv6Address: endpoint.IPv6Address,
+				Aliases:     endpoint.Aliases,
+			})
+		}
+
+		var published []models.Port
+		for _, port := range container.Ports {
+			if port.PublicPort != 0 {
+				published = append(published, port)
+			}
+		}
+		if len(published) > 0 {
+			sort.Slice(published, func(i, j int) bool {
+				if published[i].PublicPort != published[j].PublicPort {
+					return published[i].PublicPort < published[j].PublicPort
+				}
+				return published[i].IP < published[j].IP
+			})
+			topology.Edges = append(topology.Edges, models.TopologyEdge{
+				Source: hostNodeID,
+				Target: id,
+				Type:   EdgePublished,
+				Ports:  published,
+			})
+		}
+	}
+
+	return topology
+}
+
+func DOT(topology models.Topology) string {
+	var b strings.Builder
+	b.WriteString("graph topology {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [fontname=\"Helvetica\"];\n")
+
+	for _, node := range topology.Nodes {
+		label := node.Label
+		var attrs string
+		switch node.Type {
+		case NodeHost:
+			attrs = "shape=house"
+		case NodeNetwork:
+			if len(node.Subnets) > 0 {
+				label += "\n" + strings.Join(node.Subnets, "\n")
+			}
+			attrs = "shape=box, style=rounded"
+			if node.Internal {
+				attrs += ", color=gray"
+			}
+		case NodeContainer:
+			if node.Image != "" {
+				label += "\n" + node.Image
+			}
+			attrs = "shape=ellipse"
+			if node.State != "running" {
+				attrs += ", style=dashed"
+			}
+		}
+		fmt.Fprintf(&b, "\t%s [label=%s, %s];\n", quote(node.ID), quote(label), attrs)
+	}
+
+	for _, edge := range topology.Edges {
+		var parts []string
+		switch edge.Type {
+		case EdgeAttached:
+			if edge.IPAddress != "" {
+				parts = append(parts, edge.IPAddress)
+			}
+			if edge.IPv6Address != "" {
+				parts = append(parts, edge.IPv6Address)
+			}
+			if len(edge.Aliases) > 0 {
+				parts = append(parts, strings.Join(edge.Aliases, ", "))
+			}
+			fmt.Fprintf(&b, "\t%s -- %s [label=%s];\n", quote(edge.Source), quote(edge.Target), quote(strings.Join(parts, "\n")))
+		case EdgePublished:
+			for _, port := range edge.Ports {
+				parts = append(parts, formatPort(port))
+			}
+			fmt.Fprintf(&b, "\t%s -- %s [label=%s, style=bold];\n", quote(edge.Source), quote(edge.Target), quote(strings.Join(parts, "\n")))
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func networkNodeID(id string) string {
+	return NodeNetwork + ":" + id
+}
+
+func containerNodeID(id string) string {
+	return NodeContainer + ":" + id
+}
+
+func containerLabel(container models.Container) string {
+	if len(container.Names) == 0 {
+		if len(container.ID) > 12 {
+			return container.ID[:12]
+		}
+		return container.ID
+	}
+	return strings.TrimPrefix(container.Names[0], "/")
+}
+
+func formatPort(port models.Port) string {
+	ip := port.IP
+	if ip == "" {
+		ip = "0.0.0.0"
+	}
+	return fmt.Sprintf("%s:%d->%d/%s", ip, port.PublicPort, port.PrivatePort, port.Type)
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
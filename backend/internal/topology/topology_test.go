@@ -0,0 +1,91 @@
+package topology
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"docker-gui-backend/pkg/models"
+)
+
+func testGraph() models.Topology {
+	networks := []models.NetworkResource{
+		{ID: "n1", Name: "bridge", Driver: "bridge", IPAM: models.NetworkIPAM{Config: []models.IPAMPool{{Subnet: "172.17.0.0/16"}}}},
+		{ID: "n2", Name: "backend", Driver: "bridge", Internal: true},
+	}
+	containers := []models.Container{
+		{
+			ID:    "0123456789abcdef",
+			Names: []string{"/web"},
+			Image: "nginx:1.27",
+			State: "running",
+			Ports: []models.Port{
+				{PrivatePort: 443, PublicPort: 8443, Type: "tcp"},
+				{PrivatePort: 9000, Type: "tcp"},
+				{IP: "127.0.0.1", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+			},
+			Networks: []models.ContainerNetwork{
+				{Name: "bridge", NetworkID: "n1", IPAddress: "172.17.0.2"},
+				{Name: "backend", IPAddress: "10.0.0.2", Aliases: []string{"web", "frontend"}},
+				{Name: "gone", NetworkID: "n9"},
+			},
+		},
+		{ID: "fedcba9876543210aa", Image: "redis:7", State: "exited"},
+	}
+	return Build(networks, containers)
+}
+
+func TestBuild(t *testing.T) {
+	graph := testGraph()
+
+	want := []models.TopologyNode{
+		{ID: "host", Type: NodeHost, Label: "host"},
+		{ID: "network:n1", Type: NodeNetwork, Label: "bridge", Driver: "bridge", Subnets: []string{"172.17.0.0/16"}},
+		{ID: "network:n2", Type: NodeNetwork, Label: "backend", Driver: "bridge", Internal: true},
+		{ID: "container:0123456789abcdef", Type: NodeContainer, Label: "web", State: "running", Image: "nginx:1.27"},
+		{ID: "container:fedcba9876543210aa", Type: NodeContainer, Label: "fedcba987654", State: "exited", Image: "redis:7"},
+	}
+	if !reflect.DeepEqual(graph.Nodes, want) {
+		t.Errorf("nodes:\n%+v\nwant:\n%+v", graph.Nodes, want)
+	}
+
+	wantEdges := []models.TopologyEdge{
+		{Source: "container:0123456789abcdef", Target: "network:n1", Type: EdgeAttached, IPAddress: "172.17.0.2"},
+		{Source: "container:0123456789abcdef", Target: "network:n2", Type: EdgeAttached, IPAddress: "10.0.0.2", Aliases: []string{"web", "frontend"}},
+		{Source: "host", Target: "container:0123456789abcdef", Type: EdgePublished, Ports: []models.Port{
+			{IP: "127.0.0.1", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+			{PrivatePort: 443, PublicPort: 8443, Type: "tcp"},
+		}},
+	}
+	if !reflect.DeepEqual(graph.Edges, wantEdges) {
+		t.Errorf("edges:\n%+v\nwant:\n%+v", graph.Edges, wantEdges)
+	}
+
+	if empty := Build(nil, nil); len(empty.Nodes) != 1 || empty.Edges == nil || len(empty.Edges) != 0 {
+		t.Errorf("empty graph = %+v, want the host node and an empty edge list", empty)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	want := `graph topology {
+	rankdir=LR;
+	node [fontname="Helvetica"];
+	"host" [label="host", shape=house];
+	"network:n1" [label="bridge\n172.17.0.0/16", shape=box, style=rounded];
+	"network:n2" [label="backend", shape=box, style=rounded, color=gray];
+	"container:0123456789abcdef" [label="web\nnginx:1.27", shape=ellipse];
+	"container:fedcba9876543210aa" [label="fedcba987654\nredis:7", shape=ellipse, style=dashed];
+	"container:0123456789abcdef" -- "network:n1" [label="172.17.0.2"];
+	"container:0123456789abcdef" -- "network:n2" [label="10.0.0.2\nweb, frontend"];
+	"host" -- "container:0123456789abcdef" [label="127.0.0.1:8080->80/tcp\n0.0.0.0:8443->443/tcp", style=bold];
+}
+`
+	if got := DOT(testGraph()); got != want {
+		t.Errorf("DOT output:\n%s\nwant:\n%s", got, want)
+	}
+
+	got := DOT(models.Topology{Nodes: []models.TopologyNode{{ID: `container:x`, Type: NodeContainer, Label: `say "hi" \ bye`, State: "running"}}})
+	if want := "\t\"container:x\" [label=\"say \\\"hi\\\" \\\\ bye\", shape=ellipse];\n"; !strings.Contains(got, want) {
+		t.Errorf("labels are not escaped:\n%s", got)
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type CreateTokenRequest struct {
+	Name          string `json:"name"`
+	ExpiresInDays int    `json:"expiresInDays"`
+}
+
+type AssignRoleRequest struct {
+	Role       string `json:"role"`
+	ScopeLabel string `json:"scopeLabel"`
+	ScopeName  string `json:"scopeName"`
+}
+
+type RoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type EffectivePermission struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	ScopeLabel  string   `json:"scopeLabel,omitempty"`
+	ScopeName   string   `json:"scopeName,omitempty"`
+}
+
+type AccessDenied struct {
+	Permission string `json:"permission"`
+	Resource   string `json:"resource"`
+	User       string `json:"user"`
+}
@@ -3,17 +3,19 @@ package models
 import "time"
 
 type Container struct {
-	ID      string            `json:"id"`
-	Names   []string          `json:"names"`
-	Image   string            `json:"image"`
-	ImageID string            `json:"imageId"`
-	Command string            `json:"command"`
-	Created int64             `json:"created"`
-	Ports   []Port            `json:"ports"`
-	Labels  map[string]string `json:"labels"`
-	State   string            `json:"state"`
-	Status  string            `json:"status"`
-	Mounts  []Mount           `json:"mounts"`
+	ID       string             `json:"id"`
+	Names    []string           `json:"names"`
+	Image    string             `json:"image"`
+	ImageID  string             `json:"imageId"`
+	Command  string             `json:"command"`
+	Created  int64              `json:"created"`
+	Ports    []Port             `json:"ports"`
+	Labels   map[string]string  `json:"labels"`
+	State    string             `json:"state"`
+	Status   string             `json:"status"`
+	Mounts   []Mount            `json:"mounts"`
+	Networks []ContainerNetwork `json:"networks"`
+	Host     string             `json:"host,omitempty"`
 }
 
 type Port struct {
@@ -34,6 +36,15 @@ type Mount struct {
 	Propagation string `json:"propagation"`
 }
 
+type ContainerNetwork struct {
+	Name        string   `json:"name"`
+	NetworkID   string   `json:"networkId"`
+	IPAddress   string   `json:"ipAddress"`
+	IPv6Address string   `json:"ipv6Address"`
+	MacAddress  string   `json:"macAddress"`
+	Aliases     []string `json:"aliases"`
+}
+
 type ContainerStats struct {
 	ID       string    `json:"id"`
 	Name     string    `json:"name"`
@@ -63,9 +74,18 @@ type BlockIO struct {
 }
 
 type LogEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	Message   string    `json:"message"`
-	Stream    string    `json:"stream"`
+	Timestamp time.Time         `json:"timestamp"`
+	Message   string            `json:"message"`
+	Stream    string            `json:"stream"`
+	Level     string            `json:"level,omitempty"`
+	Format    string            `json:"format,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+type ContainerLogs struct {
+	Entries   []LogEntry `json:"entries"`
+	Scanned   int        `json:"scanned"`
+	Timestamp int64      `json:"timestamp"`
 }
 
 type ContainerAction struct {
@@ -77,8 +97,42 @@ type Image struct {
 	RepoTags []string `json:"repoTags"`
 	Size     int64    `json:"size"`
 	Created  int64    `json:"created"`
+	Host     string   `json:"host,omitempty"`
 }
 
 type PullImageRequest struct {
 	ImageName string `json:"imageName"`
-}
\ No newline at end of file
+}
+type ProcessList struct {
+	Titles    []string            `json:"titles"`
+	Processes []map[string]string `json:"processes"`
+}
+
+type RestartPolicy struct {
+	Name              string `json:"name"`
+	MaximumRetryCount int    `json:"maximumRetryCount"`
+}
+
+type ContainerResources struct {
+	CPUShares         int64         `json:"cpuShares"`
+	CPUPeriod         int64         `json:"cpuPeriod"`
+	CPUQuota          int64         `json:"cpuQuota"`
+	NanoCPUs          int64         `json:"nanoCpus"`
+	Memory            int64         `json:"memory"`
+	MemoryReservation int64         `json:"memoryReservation"`
+	MemorySwap        int64         `json:"memorySwap"`
+	PidsLimit         int64         `json:"pidsLimit"`
+	RestartPolicy     RestartPolicy `json:"restartPolicy"`
+}
+
+type UpdateResourcesRequest struct {
+	CPUShares         *int64         `json:"cpuShares"`
+	CPUPeriod         *int64         `json:"cpuPeriod"`
+	CPUQuota          *int64         `json:"cpuQuota"`
+	NanoCPUs          *int64         `json:"nanoCpus"`
+	Memory            *int64         `json:"memory"`
+	MemoryReservation *int64         `json:"memoryReservation"`
+	MemorySwap        *int64         `json:"memorySwap"`
+	PidsLimit         *int64         `json:"pidsLimit"`
+	RestartPolicy     *RestartPolicy `json:"restartPolicy"`
+}
@@ -0,0 +1,12 @@
+package models
+
+type APIError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+type ErrorResponse struct {
+	Error APIError `json:"error"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type FileEntry struct {
+	Name       string    `json:"name"`
+	Path       string    `json:"path"`
+	Type       string    `json:"type"`
+	Size       int64     `json:"size"`
+	Mode       string    `json:"mode"`
+	ModTime    time.Time `json:"modTime"`
+	UID        int       `json:"uid"`
+	GID        int       `json:"gid"`
+	LinkTarget string    `json:"linkTarget,omitempty"`
+}
+
+type FileChange struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+}
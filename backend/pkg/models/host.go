@@ -0,0 +1,56 @@
+package models
+
+type DockerHost struct {
+	Name          string            `json:"name"`
+	Endpoint      string            `json:"endpoint"`
+	TLS           bool              `json:"tls"`
+	TLSSkipVerify bool              `json:"tlsSkipVerify"`
+	SSHKey        string            `json:"sshKey,omitempty"`
+	SSHKnownHosts string            `json:"sshKnownHosts,omitempty"`
+	Labels        map[string]string `json:"labels"`
+	Default       bool              `json:"default"`
+	Builtin       bool              `json:"builtin"`
+	CreatedAt     string            `json:"createdAt,omitempty"`
+	UpdatedAt     string            `json:"updatedAt,omitempty"`
+	Status        *HostStatus       `json:"status,omitempty"`
+}
+
+type HostStatus struct {
+	Online        bool   `json:"online"`
+	Error         string `json:"error,omitempty"`
+	ServerVersion string `json:"serverVersion,omitempty"`
+	APIVersion    string `json:"apiVersion,omitempty"`
+	OS            string `json:"os,omitempty"`
+	Arch          string `json:"arch,omitempty"`
+	Containers    int    `json:"containers"`
+	Images        int    `json:"images"`
+}
+
+type HostRequest struct {
+	Name          string            `json:"name"`
+	Endpoint      string            `json:"endpoint"`
+	TLSCA         string            `json:"tlsCa"`
+	TLSCert       string            `json:"tlsCert"`
+	TLSKey        string            `json:"tlsKey"`
+	TLSSkipVerify bool              `json:"tlsSkipVerify"`
+	SSHKey        string            `json:"sshKey"`
+	SSHKnownHosts string            `json:"sshKnownHosts"`
+	Labels        map[string]string `json:"labels"`
+}
+
+type HostError struct {
+	Host  string `json:"host"`
+	Error string `json:"error"`
+}
+
+type SSHKey struct {
+	Name        string `json:"name"`
+	PublicKey   string `json:"publicKey"`
+	Fingerprint string `json:"fingerprint"`
+	CreatedAt   string `json:"createdAt,omitempty"`
+}
+
+type SSHKeyRequest struct {
+	Name       string `json:"name"`
+	PrivateKey string `json:"privateKey"`
+}
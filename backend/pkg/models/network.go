@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+type NetworkResource struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Scope      string            `json:"scope"`
+	Created    time.Time         `json:"created"`
+	Internal   bool              `json:"internal"`
+	Attachable bool              `json:"attachable"`
+	EnableIPv6 bool              `json:"enableIpv6"`
+	IPAM       NetworkIPAM       `json:"ipam"`
+	Options    map[string]string `json:"options"`
+	Labels     map[string]string `json:"labels"`
+	Containers []NetworkEndpoint `json:"containers"`
+	Host       string            `json:"host,omitempty"`
+}
+
+type NetworkIPAM struct {
+	Driver  string            `json:"driver"`
+	Options map[string]string `json:"options"`
+	Config  []IPAMPool        `json:"config"`
+}
+
+type IPAMPool struct {
+	Subnet       string            `json:"subnet"`
+	IPRange      string            `json:"ipRange"`
+	Gateway      string            `json:"gateway"`
+	AuxAddresses map[string]string `json:"auxAddresses"`
+}
+
+type NetworkEndpoint struct {
+	ContainerID string `json:"containerId"`
+	Name        string `json:"name"`
+	EndpointID  string `json:"endpointId"`
+	MacAddress  string `json:"macAddress"`
+	IPv4Address string `json:"ipv4Address"`
+	IPv6Address string `json:"ipv6Address"`
+}
+
+type CreateNetworkRequest struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Internal   bool              `json:"internal"`
+	Attachable bool              `json:"attachable"`
+	EnableIPv6 bool              `json:"enableIpv6"`
+	IPAM       *NetworkIPAM      `json:"ipam"`
+	Options    map[string]string `json:"options"`
+	Labels     map[string]string `json:"labels"`
+}
+
+type NetworkConnectRequest struct {
+	Container   string   `json:"container"`
+	Aliases     []string `json:"aliases"`
+	IPv4Address string   `json:"ipv4Address"`
+	IPv6Address string   `json:"ipv6Address"`
+}
+
+type NetworkDisconnectRequest struct {
+	Container string `json:"container"`
+	Force     bool   `json:"force"`
+}
+
+type PruneNetworksResult struct {
+	NetworksDeleted []string `json:"networksDeleted"`
+}
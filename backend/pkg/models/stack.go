@@ -0,0 +1,36 @@
+package models
+
+type Stack struct {
+	Name        string         `json:"name"`
+	Status      string         `json:"status"`
+	WorkingDir  string         `json:"workingDir"`
+	ConfigFiles string         `json:"configFiles"`
+	Containers  int            `json:"containers"`
+	Running     int            `json:"running"`
+	Services    []StackService `json:"services"`
+}
+
+type StackService struct {
+	Name       string           `json:"name"`
+	Status     string           `json:"status"`
+	Health     string           `json:"health"`
+	Image      string           `json:"image"`
+	DependsOn  []string         `json:"dependsOn"`
+	Containers []StackContainer `json:"containers"`
+}
+
+type StackContainer struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Status string `json:"status"`
+	Health string `json:"health"`
+}
+
+type StackActionResult struct {
+	Service       string `json:"service"`
+	ContainerID   string `json:"containerId"`
+	ContainerName string `json:"containerName"`
+	Action        string `json:"action"`
+	Error         string `json:"error,omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+type StackPlan struct {
+	Project  string            `json:"project"`
+	Changes  []StackPlanChange `json:"changes"`
+	Warnings []string          `json:"warnings"`
+}
+
+type StackPlanChange struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Action string   `json:"action"`
+	Reason string   `json:"reason,omitempty"`
+	Diff   []string `json:"diff,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+type StackDeployment struct {
+	Project  string            `json:"project"`
+	Revision int               `json:"revision"`
+	Changes  []StackPlanChange `json:"changes"`
+}
+
+type DeployStackRequest struct {
+	Compose string `json:"compose"`
+}
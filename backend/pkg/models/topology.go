@@ -0,0 +1,27 @@
+package models
+
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+}
+
+type TopologyNode struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Label    string   `json:"label"`
+	State    string   `json:"state,omitempty"`
+	Image    string   `json:"image,omitempty"`
+	Driver   string   `json:"driver,omitempty"`
+	Internal bool     `json:"internal,omitempty"`
+	Subnets  []string `json:"subnets,omitempty"`
+}
+
+type TopologyEdge struct {
+	Source      string   `json:"source"`
+	Target      string   `json:"target"`
+	Type        string   `json:"type"`
+	IPAddress   string   `json:"ipAddress,omitempty"`
+	IPv6Address string   `json:"ipv6Address,omitempty"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Ports       []Port   `json:"ports,omitempty"`
+}
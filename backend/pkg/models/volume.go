@@ -0,0 +1,35 @@
+package models
+
+type Volume struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Mountpoint string            `json:"mountpoint"`
+	CreatedAt  string            `json:"createdAt"`
+	Scope      string            `json:"scope"`
+	Labels     map[string]string `json:"labels"`
+	Options    map[string]string `json:"options"`
+	Size       int64             `json:"size"`
+	RefCount   int64             `json:"refCount"`
+	UsedBy     []VolumeUsage     `json:"usedBy"`
+	Host       string            `json:"host,omitempty"`
+}
+
+type VolumeUsage struct {
+	ContainerID   string `json:"containerId"`
+	ContainerName string `json:"containerName"`
+	State         string `json:"state"`
+	Destination   string `json:"destination"`
+	RW            bool   `json:"rw"`
+}
+
+type CreateVolumeRequest struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	DriverOpts map[string]string `json:"driverOpts"`
+	Labels     map[string]string `json:"labels"`
+}
+
+type PruneVolumesResult struct {
+	VolumesDeleted []string `json:"volumesDeleted"`
+	SpaceReclaimed uint64   `json:"spaceReclaimed"`
+}